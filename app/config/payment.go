@@ -0,0 +1,21 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"BE-E-Meeting/app/payments"
+)
+
+// load payment provider dari env, default fake (tanpa gateway)
+func LoadPaymentProvider() payments.Provider {
+	secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	switch os.Getenv("PAYMENT_PROVIDER") {
+	case "gateway":
+		return payments.NewHTTPGateway(os.Getenv("PAYMENT_GATEWAY_URL"), os.Getenv("PAYMENT_SERVER_KEY"), secret)
+	case "", "fake":
+	default:
+		log.Printf("payment: unknown PAYMENT_PROVIDER %q, using fake provider", os.Getenv("PAYMENT_PROVIDER"))
+	}
+	return payments.NewFakeProvider(secret)
+}
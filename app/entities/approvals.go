@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+// PendingApproval: reservasi berstatus pending yang menunggu keputusan admin
+type PendingApproval struct {
+	ReservationID  int                   `json:"reservationID"`
+	UserID         int                   `json:"userID"`
+	Name           string                `json:"name"`
+	Company        string                `json:"company"`
+	Email          string                `json:"email"`
+	Total          float64               `json:"total"`
+	ApprovalReason string                `json:"approvalReason"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	Rooms          []PendingApprovalRoom `json:"rooms"`
+}
+
+// PendingApprovalRoom: slot yang diminta beserta konteks bentroknya
+type PendingApprovalRoom struct {
+	DetailID    int       `json:"detailID"`
+	RoomID      int       `json:"roomID"`
+	RoomName    string    `json:"roomName"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+	Participant int       `json:"participant"`
+	// Booking lain di room yang sama pada hari itu
+	SameDay []RoomSchedule `json:"sameDay"`
+	// Antrian waitlist yang menunggu slot ini (dilayani jika reservasi ditolak)
+	Waitlisted int `json:"waitlisted"`
+}
+
+// Request body untuk approve / reject reservasi pending. Comment wajib saat reject.
+type ApprovalDecisionRequest struct {
+	Comment string `json:"comment"`
+}
@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+// Request body untuk membuat link subscription kalender
+type CalendarFeedRequest struct {
+	Type   string `json:"type" validate:"required,oneof=user room"`
+	RoomID int    `json:"roomID"`
+}
+
+type CalendarFeed struct {
+	Token   string `json:"token"`
+	Type    string `json:"type"`
+	OwnerID int    `json:"ownerID"`
+	URL     string `json:"url"`
+}
+
+// CalendarEvent: satu VEVENT, dibentuk dari satu baris reservation_details
+type CalendarEvent struct {
+	DetailID      int
+	ReservationID int
+	RoomName      string
+	Company       string
+	Note          string
+	Status        string
+	Sequence      int
+	StartAt       time.Time
+	EndAt         time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -6,15 +6,24 @@ type DashboardRoom struct {
 	Name              string  `json:"name"`
 	Omzet             float64 `json:"omzet"`
 	PercentageOfUsage float64 `json:"percentageOfUsage"`
+	// Rata-rata peserta dibanding kapasitas room (persen)
+	SeatUtilization float64 `json:"seatUtilization"`
 }
 
 // --- struct terpisah untuk Data ---
 type DashboardData struct {
-	TotalRoom        int             `json:"totalRoom"`
-	TotalVisitor     int             `json:"totalVisitor"`
-	TotalReservation int             `json:"totalReservation"`
-	TotalOmzet       float64         `json:"totalOmzet"`
-	Rooms            []DashboardRoom `json:"rooms"`
+	TotalRoom        int     `json:"totalRoom"`
+	TotalVisitor     int     `json:"totalVisitor"`
+	TotalReservation int     `json:"totalReservation"`
+	TotalOmzet       float64 `json:"totalOmzet"`
+	// Bagian dari TotalOmzet yang berasal dari biaya cancel reservasi yang sudah dibayar
+	TotalCancellationFee float64 `json:"totalCancellationFee"`
+	// Total potongan promo code / company agreement, TotalOmzet sudah net setelah diskon
+	TotalDiscount float64 `json:"totalDiscount"`
+	TotalNoShow   int     `json:"totalNoShow"`
+	// Rata-rata peserta dibanding kapasitas room (persen) dari semua room yang dibooking
+	AverageSeatUtilization float64         `json:"averageSeatUtilization"`
+	Rooms                  []DashboardRoom `json:"rooms"`
 }
 
 type DashboardResponse struct {
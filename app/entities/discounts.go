@@ -0,0 +1,79 @@
+package entities
+
+import "time"
+
+// DiscountTerms: aturan potongan yang sama untuk promo code dan company agreement.
+// DiscountType percent = persen dari harga yang eligible, fixed = potongan nominal.
+// RoomTypes / SnackIDs kosong = semua room / snack eligible.
+type DiscountTerms struct {
+	DiscountType  string     `json:"discountType"`
+	DiscountValue float64    `json:"discountValue"`
+	ValidFrom     *time.Time `json:"validFrom,omitempty"`
+	ValidUntil    *time.Time `json:"validUntil,omitempty"`
+	// Jumlah reservasi aktif yang boleh memakai diskon ini (0 = tidak dibatasi)
+	UsageLimit int      `json:"usageLimit"`
+	RoomTypes  []string `json:"roomTypes"`
+	SnackIDs   []int    `json:"snackIDs"`
+}
+
+type PromoCode struct {
+	ID   int    `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+	DiscountTerms
+	// Batas pemakaian per user (0 = tidak dibatasi)
+	PerUserLimit int       `json:"perUserLimit"`
+	UsedCount    int       `json:"usedCount"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// CompanyAgreement: diskon hasil negosiasi sales dengan perusahaan klien,
+// hanya bisa dipakai jika company di reservasi sama dengan CompanyName
+type CompanyAgreement struct {
+	ID          int    `json:"id"`
+	Code        string `json:"code"`
+	CompanyName string `json:"companyName"`
+	DiscountTerms
+	UsedCount int       `json:"usedCount"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Field request yang sama untuk promo code dan company agreement
+type DiscountTermsRequest struct {
+	DiscountType  string     `json:"discountType" validate:"oneof=percent fixed"`
+	DiscountValue float64    `json:"discountValue" validate:"gt=0"`
+	ValidFrom     *time.Time `json:"validFrom"`
+	ValidUntil    *time.Time `json:"validUntil"`
+	UsageLimit    int        `json:"usageLimit" validate:"min=0"`
+	RoomTypes     []string   `json:"roomTypes" validate:"dive,oneof=small medium large"`
+	SnackIDs      []int      `json:"snackIDs" validate:"dive,gt=0"`
+}
+
+// Request body untuk POST / PUT /promo-codes
+type PromoCodeRequest struct {
+	Code         string `json:"code" validate:"required"`
+	Name         string `json:"name" validate:"required"`
+	PerUserLimit int    `json:"perUserLimit" validate:"min=0"`
+	DiscountTermsRequest
+}
+
+// Request body untuk POST / PUT /company-agreements
+type CompanyAgreementRequest struct {
+	Code        string `json:"code" validate:"required"`
+	CompanyName string `json:"companyName" validate:"required"`
+	DiscountTermsRequest
+}
+
+// DiscountLine: potongan yang dipakai di response kalkulasi
+type DiscountLine struct {
+	Source        string  `json:"source"` // promo / agreement
+	Code          string  `json:"code"`
+	Name          string  `json:"name"`
+	DiscountType  string  `json:"discountType"`
+	DiscountValue float64 `json:"discountValue"`
+	// Bagian harga (room + snack) yang memenuhi syarat room type / snack
+	EligibleAmount float64 `json:"eligibleAmount"`
+	Amount         float64 `json:"amount"`
+}
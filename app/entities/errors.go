@@ -0,0 +1,76 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound: resource tidak ditemukan, dipetakan ke HTTP 404.
+// Dibungkus dengan nama resource, mis. fmt.Errorf("reservation %w", ErrNotFound) -> "reservation not found".
+var ErrNotFound = errors.New("not found")
+
+// BadRequestError dikembalikan ketika request tidak valid atau tidak diizinkan oleh aturan bisnis
+// (status reservasi, batas pemakaian, format input). Dipetakan ke HTTP 400; error lain yang
+// tidak dikenali (database, service luar) menjadi HTTP 500.
+type BadRequestError struct {
+	Message string
+}
+
+func (e *BadRequestError) Error() string {
+	return e.Message
+}
+
+// ConflictError dikembalikan ketika slot room sudah terisi reservasi lain.
+// Handler memetakan error ini ke HTTP 409 Conflict.
+type ConflictError struct {
+	RoomID int
+	// Saran slot/room alternatif, diisi usecase jika tersedia
+	Suggestions *ConflictSuggestion
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("room %d is already booked", e.RoomID)
+}
+
+// ForbiddenError dikembalikan ketika user tidak berhak melakukan aksi
+// terhadap resource (role atau kepemilikan tidak sesuai). Dipetakan ke HTTP 403.
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
+// ClosedError dikembalikan ketika jam yang diminta di luar jam buka room
+// atau jatuh di tanggal blackout / hari libur. Dipetakan ke HTTP 400.
+type ClosedError struct {
+	RoomID  int
+	Message string
+}
+
+func (e *ClosedError) Error() string {
+	return e.Message
+}
+
+// RuleViolation: satu aturan booking yang dilanggar
+type RuleViolation struct {
+	RoomID  int    `json:"roomID"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError dikembalikan ketika request melanggar aturan booking.
+// Berisi semua aturan yang dilanggar, handler menampilkannya sebagai "violations" (HTTP 400).
+type ValidationError struct {
+	Violations []RuleViolation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return "booking rules violated: " + strings.Join(messages, "; ")
+}
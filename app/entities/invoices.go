@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// InvoiceNumber: nomor invoice / receipt yang sudah diterbitkan untuk satu reservasi
+type InvoiceNumber struct {
+	ReservationID int
+	Kind          string // invoice / receipt
+	Number        string
+	IssuedAt      time.Time
+}
+
+// InvoiceFile: hasil generate PDF invoice / receipt
+type InvoiceFile struct {
+	Kind     string
+	Number   string
+	FileName string
+	Content  []byte
+}
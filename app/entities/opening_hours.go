@@ -0,0 +1,52 @@
+package entities
+
+import "time"
+
+// OperatingHour: jam buka per hari (weekday 0 = Minggu ... 6 = Sabtu).
+// RoomID 0 = jam buka default global, dipakai jika room tidak punya jam sendiri di hari itu.
+type OperatingHour struct {
+	RoomID    int    `json:"roomID"`
+	Weekday   int    `json:"weekday"`
+	OpenTime  string `json:"openTime"`  // "08:00"
+	CloseTime string `json:"closeTime"` // "18:00", "24:00" = sampai tengah malam
+	Closed    bool   `json:"closed"`
+}
+
+// Request body untuk PUT /operating-hours, mengganti seluruh jam buka mingguan room / global
+type OperatingHoursRequest struct {
+	RoomID int                    `json:"roomID" validate:"min=0"`
+	Hours  []OperatingHourRequest `json:"hours" validate:"max=7,dive"`
+}
+
+type OperatingHourRequest struct {
+	Weekday   int    `json:"weekday" validate:"min=0,max=6"`
+	OpenTime  string `json:"openTime"`
+	CloseTime string `json:"closeTime"`
+	Closed    bool   `json:"closed"`
+}
+
+// BlackoutDate: tanggal tutup (libur / maintenance), inklusif. RoomID 0 = semua room (hari libur)
+type BlackoutDate struct {
+	ID        int       `json:"id"`
+	RoomID    int       `json:"roomID"`
+	StartDate string    `json:"startDate"`
+	EndDate   string    `json:"endDate"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type BlackoutDateRequest struct {
+	RoomID    int    `json:"roomID" validate:"min=0"`
+	StartDate string `json:"startDate" validate:"required"`
+	EndDate   string `json:"endDate"` // kosong = sama dengan startDate
+	Name      string `json:"name" validate:"required"`
+}
+
+// RoomOpening: jam buka efektif room pada satu tanggal (OpenTime/CloseTime kosong jika tutup)
+type RoomOpening struct {
+	Date      string     `json:"date"`
+	Closed    bool       `json:"closed"`
+	Reason    string     `json:"reason,omitempty"`
+	OpenTime  *time.Time `json:"openTime,omitempty"`
+	CloseTime *time.Time `json:"closeTime,omitempty"`
+}
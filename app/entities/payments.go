@@ -0,0 +1,59 @@
+package entities
+
+import "time"
+
+// Payment: payment intent untuk total reservasi di payment provider
+type Payment struct {
+	ID                int        `json:"id"`
+	ReservationID     int        `json:"reservationID"`
+	Provider          string     `json:"provider"`
+	Reference         string     `json:"reference"`
+	ProviderReference string     `json:"providerReference,omitempty"`
+	Amount            float64    `json:"amount"`
+	Status            string     `json:"status"` // pending / paid / failed / expired
+	PaymentURL        string     `json:"paymentURL"`
+	ExpiresAt         *time.Time `json:"expiresAt,omitempty"`
+	PaidAmount        float64    `json:"paidAmount,omitempty"`
+	PaidAt            *time.Time `json:"paidAt,omitempty"`
+	FailureReason     string     `json:"failureReason,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+// PaymentIntentRequest: data yang dikirim ke payment provider saat membuat intent
+type PaymentIntentRequest struct {
+	Reference     string
+	Amount        float64
+	Description   string
+	CustomerName  string
+	CustomerEmail string
+	ExpiresAt     time.Time
+}
+
+// PaymentIntent: balasan payment provider
+type PaymentIntent struct {
+	ProviderReference string
+	PaymentURL        string
+	ExpiresAt         time.Time
+}
+
+// PaymentWebhookEvent: callback payment provider yang signature-nya sudah diverifikasi
+type PaymentWebhookEvent struct {
+	Provider          string
+	EventID           string
+	Reference         string
+	ProviderReference string
+	Status            string // paid / failed / expired
+	Amount            float64
+	Reason            string
+	Payload           string
+}
+
+// PaymentWebhookResult: hasil proses webhook, Duplicate = event sudah pernah diproses / pembayaran sudah lunas
+type PaymentWebhookResult struct {
+	Reference     string `json:"reference"`
+	ReservationID int    `json:"reservationID"`
+	PaymentStatus string `json:"paymentStatus"`
+	Duplicate     bool   `json:"duplicate"`
+	// Reservasi ikut berubah menjadi paid
+	ReservationPaid bool `json:"reservationPaid"`
+}
@@ -0,0 +1,58 @@
+package entities
+
+import "time"
+
+// CancellationPolicy: RoomType kosong = policy global
+type CancellationPolicy struct {
+	ID               int       `json:"id"`
+	RoomType         string    `json:"roomType"`
+	FreeCancelHours  int       `json:"freeCancelHours"`
+	LateFeePercent   float64   `json:"lateFeePercent"`
+	NoShowFeePercent float64   `json:"noShowFeePercent"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// Request body untuk PUT /cancellation-policies (upsert per room type)
+type CancellationPolicyRequest struct {
+	RoomType         string  `json:"roomType" validate:"omitempty,oneof=small medium large"`
+	FreeCancelHours  int     `json:"freeCancelHours" validate:"min=0"`
+	LateFeePercent   float64 `json:"lateFeePercent" validate:"min=0,max=100"`
+	NoShowFeePercent float64 `json:"noShowFeePercent" validate:"min=0,max=100"`
+}
+
+// CancellationSettlement: biaya cancel dan nominal yang dikembalikan ke user
+type CancellationSettlement struct {
+	Fee    float64 `json:"cancellationFee"`
+	Refund float64 `json:"refundAmount"`
+}
+
+// BookingRule: aturan booking, RoomType kosong = aturan global.
+// Nilai 0 = tidak dibatasi (kecuali lead time: 0 = minimal mulai dari sekarang).
+type BookingRule struct {
+	ID                 int    `json:"id"`
+	RoomType           string `json:"roomType"`
+	MinLeadMinutes     int    `json:"minLeadMinutes"`
+	MaxHorizonDays     int    `json:"maxHorizonDays"`
+	MinDurationMinutes int    `json:"minDurationMinutes"`
+	MaxDurationMinutes int    `json:"maxDurationMinutes"`
+	SlotMinutes        int    `json:"slotMinutes"`
+	// Toleransi peserta melebihi kapasitas room (persen dari kapasitas)
+	CapacityOverflowPercent int `json:"capacityOverflowPercent"`
+	// Reservasi room type ini / dengan total di atas threshold butuh approval admin (0 = tanpa batas harga)
+	RequiresApproval       bool      `json:"requiresApproval"`
+	ApprovalPriceThreshold float64   `json:"approvalPriceThreshold"`
+	UpdatedAt              time.Time `json:"updatedAt"`
+}
+
+// Request body untuk PUT /booking-rules (upsert per room type)
+type BookingRuleRequest struct {
+	RoomType                string  `json:"roomType" validate:"omitempty,oneof=small medium large"`
+	MinLeadMinutes          int     `json:"minLeadMinutes" validate:"min=0"`
+	MaxHorizonDays          int     `json:"maxHorizonDays" validate:"min=0"`
+	MinDurationMinutes      int     `json:"minDurationMinutes" validate:"min=0"`
+	MaxDurationMinutes      int     `json:"maxDurationMinutes" validate:"min=0"`
+	SlotMinutes             int     `json:"slotMinutes" validate:"oneof=0 5 10 15 30 60"`
+	CapacityOverflowPercent int     `json:"capacityOverflowPercent" validate:"min=0,max=100"`
+	RequiresApproval        bool    `json:"requiresApproval"`
+	ApprovalPriceThreshold  float64 `json:"approvalPriceThreshold" validate:"min=0"`
+}
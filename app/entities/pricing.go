@@ -0,0 +1,55 @@
+package entities
+
+import "time"
+
+// PricingRule: aturan harga room yang diatur admin, RoomType kosong = berlaku untuk semua room.
+// Kind menentukan field yang dipakai:
+//   - time_band: Weekdays (kosong = setiap hari) + StartTime/EndTime + Multiplier (peak > 1, off-peak < 1)
+//   - weekend: Multiplier untuk hari Sabtu & Minggu
+//   - holiday: StartDate/EndDate + Multiplier
+//   - package: harga paket PackagePrice untuk booking sampai DurationMinutes (half-day / full-day)
+//   - long_booking: diskon DiscountPercent untuk booking minimal DurationMinutes
+type PricingRule struct {
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	Kind            string    `json:"kind"`
+	RoomType        string    `json:"roomType"`
+	Weekdays        []int     `json:"weekdays"`
+	StartTime       string    `json:"startTime,omitempty"`
+	EndTime         string    `json:"endTime,omitempty"`
+	StartDate       string    `json:"startDate,omitempty"`
+	EndDate         string    `json:"endDate,omitempty"`
+	Multiplier      float64   `json:"multiplier"`
+	DurationMinutes int       `json:"durationMinutes"`
+	PackagePrice    float64   `json:"packagePrice"`
+	DiscountPercent float64   `json:"discountPercent"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// Request body untuk POST / PUT /pricing-rules
+type PricingRuleRequest struct {
+	Name            string  `json:"name" validate:"required"`
+	Kind            string  `json:"kind" validate:"oneof=time_band weekend holiday package long_booking"`
+	RoomType        string  `json:"roomType" validate:"omitempty,oneof=small medium large"`
+	Weekdays        []int   `json:"weekdays" validate:"dive,min=0,max=6"`
+	StartTime       string  `json:"startTime"`
+	EndTime         string  `json:"endTime"`
+	StartDate       string  `json:"startDate"`
+	EndDate         string  `json:"endDate"`
+	Multiplier      float64 `json:"multiplier" validate:"min=0"`
+	DurationMinutes int     `json:"durationMinutes" validate:"min=0"`
+	PackagePrice    float64 `json:"packagePrice" validate:"min=0"`
+	DiscountPercent float64 `json:"discountPercent" validate:"min=0,max=100"`
+}
+
+// PriceLine: rincian harga room di response kalkulasi.
+// Kind "base" = harga dasar (harga per jam x durasi), baris lain = penyesuaian dari pricing rule.
+type PriceLine struct {
+	RuleID     int     `json:"ruleID,omitempty"`
+	Name       string  `json:"name"`
+	Kind       string  `json:"kind"`
+	Minutes    int     `json:"minutes,omitempty"`
+	Multiplier float64 `json:"multiplier,omitempty"`
+	Amount     float64 `json:"amount"`
+}
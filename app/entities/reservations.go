@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -16,11 +17,63 @@ type ReservationRequest struct {
 	// tapi jika butuh data global, kita simpan disini.
 	TotalParticipants int                      `json:"totalParticipants"`
 	Rooms             []RoomReservationRequest `json:"rooms" validate:"required,min=1"`
+	// Recurrence diisi jika booking berulang (series), waktu di Rooms = occurrence pertama
+	Recurrence *RecurrenceRule `json:"recurrence,omitempty"`
+	// Hold = tentative booking, slot diblokir sampai masa hold habis
+	Hold bool `json:"hold"`
+	// Opsional: kode promo dan kode company agreement (company harus sama dengan agreement)
+	PromoCode     string `json:"promoCode"`
+	AgreementCode string `json:"agreementCode"`
+}
+
+// RecurrenceRule mengikuti konsep RRULE (RFC 5545) yang disederhanakan.
+// Wajib isi salah satu dari Until atau Count.
+type RecurrenceRule struct {
+	Frequency     string     `json:"frequency" validate:"required,oneof=daily weekly monthly"`
+	Interval      int        `json:"interval"`  // default 1
+	ByWeekday     []string   `json:"byWeekday"` // MO,TU,WE,TH,FR,SA,SU (khusus weekly)
+	Until         *time.Time `json:"until,omitempty"`
+	Count         int        `json:"count"`
+	SkipConflicts bool       `json:"skipConflicts"` // lewati occurrence yang bentrok, bukan gagal semua
+}
+
+// UpdateSeriesRequest: StartTime/EndTime adalah jadwal baru untuk occurrence yang dipilih,
+// pergeserannya diterapkan ke semua occurrence dalam scope.
+type UpdateSeriesRequest struct {
+	Scope     string    `json:"scope" validate:"required,oneof=this following all"`
+	StartTime time.Time `json:"startTime" validate:"required"`
+	EndTime   time.Time `json:"endTime" validate:"required"`
+	Notes     *string   `json:"notes"`
+}
+
+// ModifyReservationRequest: setiap item mengubah satu baris reservation_details (DetailID),
+// baris yang tidak disebut tidak berubah. Preview = hanya hitung selisih harga, tidak disimpan.
+type ModifyReservationRequest struct {
+	Rooms   []ModifyRoomRequest `json:"rooms" validate:"required,min=1"`
+	Reason  string              `json:"reason"`
+	Preview bool                `json:"preview"`
+}
+
+type ModifyRoomRequest struct {
+	DetailID int `json:"detailID" validate:"required"`
+	RoomReservationRequest
+}
+
+// CancelDetailsRequest: cancel sebagian room (baris reservation_details) dari satu reservasi
+type CancelDetailsRequest struct {
+	DetailIDs []int  `json:"detailIDs" validate:"required,min=1"`
+	Reason    string `json:"reason"`
+}
+
+type CancelSeriesRequest struct {
+	Scope  string `json:"scope" validate:"required,oneof=this following all"`
+	Reason string `json:"reason"`
 }
 
 type UpdateReservationRequest struct {
 	ReservationID int    `json:"reservation_id" validate:"required"`
-	Status        string `json:"status" validate:"required,oneof=booked cancel paid"`
+	Status        string `json:"status" validate:"required,oneof=booked cancel paid refunded"`
+	Reason        string `json:"reason"`
 }
 
 // 2. RESPONSE MODELS
@@ -30,7 +83,65 @@ type CalculateReservationData struct {
 	Rooms         []RoomCalculationDetail `json:"rooms"`
 	SubTotalRoom  float64                 `json:"subTotalRoom"`
 	SubTotalSnack float64                 `json:"subTotalSnack"`
-	Total         float64                 `json:"total"`
+	// Potongan promo code / company agreement
+	Discount  float64        `json:"discount"`
+	Discounts []DiscountLine `json:"discounts,omitempty"`
+	// Service charge & pajak sesuai tax component yang aktif
+	ServiceCharge float64 `json:"serviceCharge"`
+	Tax           float64 `json:"tax"`
+	Total         float64 `json:"total"`
+	// Baris invoice (room, fee, snack, diskon, service charge, pajak), kosong untuk booking berulang
+	InvoiceLines []InvoiceLine `json:"invoiceLines,omitempty"`
+	// Hanya terisi untuk booking berulang, total di atas = jumlah semua occurrence
+	Occurrences []OccurrenceCalculation `json:"occurrences,omitempty"`
+}
+
+type OccurrenceCalculation struct {
+	Index           int       `json:"index"`
+	StartTime       time.Time `json:"startTime"`
+	EndTime         time.Time `json:"endTime"`
+	Available       bool      `json:"available"`
+	ConflictRoomIDs []int     `json:"conflictRoomIDs,omitempty"`
+	// Diisi jika occurrence di luar jam buka / tanggal blackout / melanggar aturan booking
+	UnavailableReason string  `json:"unavailableReason,omitempty"`
+	Total             float64 `json:"total"`
+}
+
+type PriceSummary struct {
+	SubTotalRoom  float64 `json:"subTotalRoom"`
+	SubTotalSnack float64 `json:"subTotalSnack"`
+	Discount      float64 `json:"discount"`
+	ServiceCharge float64 `json:"serviceCharge"`
+	Tax           float64 `json:"tax"`
+	Total         float64 `json:"total"`
+}
+
+type ModifyReservationResult struct {
+	ReservationID int                     `json:"reservationID"`
+	Applied       bool                    `json:"applied"`
+	Before        PriceSummary            `json:"before"`
+	After         PriceSummary            `json:"after"`
+	Diff          PriceSummary            `json:"diff"` // after - before
+	Rooms         []RoomCalculationDetail `json:"rooms"`
+	// pending jika perubahan butuh approval admin
+	Status         string `json:"status"`
+	ApprovalReason string `json:"approvalReason,omitempty"`
+}
+
+type CancelDetailsResult struct {
+	ReservationID int          `json:"reservationID"`
+	Status        string       `json:"status"`
+	Cancelled     []int        `json:"cancelled"`
+	Before        PriceSummary `json:"before"`
+	After         PriceSummary `json:"after"`
+	// Biaya cancel untuk room yang dicancel (lihat cancellation policy)
+	Settlement CancellationSettlement `json:"settlement"`
+}
+
+type SeriesCreateResult struct {
+	SeriesID int         `json:"seriesID"`
+	Created  int         `json:"created"`
+	Skipped  []time.Time `json:"skipped"`
 }
 
 type RoomCalculationDetail struct {
@@ -43,7 +154,24 @@ type RoomCalculationDetail struct {
 	EndTime       time.Time `json:"endTime"`
 	Duration      int       `json:"duration"` // menit
 	Participant   int       `json:"participant"`
-	Snack         *Snack    `json:"snack"`
+	// Snack pertama (kompatibilitas), rincian lengkap di snacks
+	Snack  *Snack      `json:"snack"`
+	Snacks []SnackLine `json:"snacks"`
+	// Rincian harga room per pricing rule yang dipakai
+	PriceBreakdown []PriceLine `json:"priceBreakdown"`
+}
+
+// --- Conflict Suggestion ---
+
+type ConflictSuggestion struct {
+	SameRoomSlots []SuggestedSlot `json:"sameRoomSlots"`
+	OtherRooms    []AvailableRoom `json:"otherRooms"`
+}
+
+type SuggestedSlot struct {
+	RoomID    int       `json:"roomID"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
 }
 
 // --- B. History & Detail Response ---
@@ -63,26 +191,75 @@ type ReservationDetailResponse struct {
 }
 
 type ReservationHistoryData struct {
-	ID            int       `json:"id"`
-	Name          string    `json:"name"`
-	PhoneNumber   string    `json:"phoneNumber"`
-	Company       string    `json:"company"`
-	SubTotalSnack float64   `json:"subTotalSnack"`
-	SubTotalRoom  float64   `json:"subTotalRoom"`
-	Total         float64   `json:"total"`
-	Status        string    `json:"status"`
-	CreatedAt     time.Time `json:"createdAt"`
+	ID            int     `json:"id"`
+	UserID        int     `json:"userID"`
+	SeriesID      int     `json:"seriesID,omitempty"`
+	Name          string  `json:"name"`
+	PhoneNumber   string  `json:"phoneNumber"`
+	Company       string  `json:"company"`
+	Notes         string  `json:"notes"`
+	SubTotalSnack float64 `json:"subTotalSnack"`
+	SubTotalRoom  float64 `json:"subTotalRoom"`
+	Discount      float64 `json:"discount"`
+	ServiceCharge float64 `json:"serviceCharge"`
+	Tax           float64 `json:"tax"`
+	Total         float64 `json:"total"`
+	Status        string  `json:"status"`
+	// Diisi saat cancel (cancellation policy) atau refund
+	CancellationFee float64 `json:"cancellationFee"`
+	RefundAmount    float64 `json:"refundAmount"`
+	// Batas waktu hold (hanya untuk status hold)
+	HoldExpiresAt *time.Time `json:"holdExpiresAt,omitempty"`
+	// Alasan reservasi butuh approval admin
+	ApprovalReason string `json:"approvalReason,omitempty"`
+	// Diisi saat dibayar lewat payment provider
+	PaymentReference string     `json:"paymentReference,omitempty"`
+	PaidAmount       float64    `json:"paidAmount,omitempty"`
+	PaidAt           *time.Time `json:"paidAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
 	// struct khusus untuk response history
 	Rooms []ReservationRoomDetail `json:"rooms"`
+	// Baris invoice yang tersimpan saat booking (hanya di detail reservasi)
+	InvoiceLines []InvoiceLine `json:"invoiceLines,omitempty"`
 }
 
+// ReservationRoomDetail: satu baris reservation_details, nama / tipe / harga dari snapshot saat booking.
+// ID = 0 jika room sudah dihapus.
 type ReservationRoomDetail struct {
-	ID         int     `json:"id"`
-	Name       string  `json:"name"`
-	Type       string  `json:"type"`
-	Price      float64 `json:"price"`
-	TotalRoom  float64 `json:"totalRoom"`
-	TotalSnack float64 `json:"totalSnack"`
+	DetailID    int         `json:"detailID"`
+	ID          int         `json:"id"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Price       float64     `json:"price"`
+	StartTime   time.Time   `json:"startTime"`
+	EndTime     time.Time   `json:"endTime"`
+	Duration    int         `json:"duration"`
+	Participant int         `json:"participant"`
+	Snack       *Snack      `json:"snack"`
+	Snacks      []SnackLine `json:"snacks"`
+	TotalRoom   float64     `json:"totalRoom"`
+	TotalSnack  float64     `json:"totalSnack"`
+	CancelledAt *time.Time  `json:"cancelledAt,omitempty"`
+}
+
+type ReservationStatusHistory struct {
+	ID         int       `json:"id"`
+	ChangedBy  int       `json:"changedBy"`
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type ReservationChangeHistory struct {
+	ID          int             `json:"id"`
+	ChangedBy   int             `json:"changedBy"`
+	Reason      string          `json:"reason"`
+	BeforeTotal float64         `json:"beforeTotal"`
+	AfterTotal  float64         `json:"afterTotal"`
+	Before      json.RawMessage `json:"before" swaggertype:"object"`
+	After       json.RawMessage `json:"after" swaggertype:"object"`
+	CreatedAt   time.Time       `json:"createdAt"`
 }
 
 // --- C. Schedule Response ---
@@ -91,6 +268,8 @@ type ScheduleResponse struct {
 	Message   string             `json:"message"`
 	Data      []RoomScheduleInfo `json:"data"`
 	TotalData int                `json:"totalData"`
+	// Tanggal blackout / libur di rentang tanggal yang diminta
+	Closures []BlackoutDate `json:"closures"`
 }
 
 type RoomScheduleInfo struct {
@@ -110,8 +289,11 @@ type RoomSchedule struct {
 	ID               int       `json:"id"`
 	StartTime        time.Time `json:"startTime"`
 	EndTime          time.Time `json:"endTime"`
-	Status           string    `json:"status"`
+	Status           string    `json:"status"` // status reservasi, atau cancel / no_show per room
 	TotalParticipant int       `json:"totalParticipant"`
+	// Rentang yang terblokir termasuk buffer setup / teardown room
+	BlockedFrom  time.Time `json:"blockedFrom"`
+	BlockedUntil time.Time `json:"blockedUntil"`
 }
 
 // 3. REPOSITORY DTOs
@@ -130,20 +312,96 @@ type ReservationData struct {
 	TotalParticipants int
 	AddSnack          bool
 	DurationMinute    int
+	SeriesID          int
+	OccurrenceStart   time.Time
+	HoldExpiresAt     time.Time
+	// Diisi jika reservasi butuh approval admin (status pending)
+	ApprovalReason string
+	// Diskon promo / company agreement, Total sudah dikurangi DiscountAmount
+	PromoCodeID        int
+	CompanyAgreementID int
+	DiscountAmount     float64
+	// Service charge & pajak, Total = subtotal - diskon + ServiceCharge + TaxAmount
+	ServiceCharge float64
+	TaxAmount     float64
+	// Baris invoice level reservasi (diskon, service, tax), baris per room ada di detail
+	InvoiceLines []InvoiceLine
+}
+
+// ExpiredHold: hold yang dilepas worker, dipakai untuk notifikasi email
+type ExpiredHold struct {
+	ReservationID int
+	Email         string
+	ContactName   string
+	ExpiredAt     time.Time
 }
 
+// Tag json dipakai saat snapshot detail disimpan ke reservation_change_histories
 type ReservationDetailData struct {
-	ReservationID     int
-	RoomID            int
-	RoomName          string
-	RoomPrice         float64
-	SnackID           int
-	SnackName         string
-	SnackPrice        float64
-	DurationMinute    int
-	TotalParticipants int
-	TotalRoom         float64
-	TotalSnack        float64
-	StartAt           time.Time
-	EndAt             time.Time
+	ID                int       `json:"id"`
+	ReservationID     int       `json:"reservationID"`
+	RoomID            int       `json:"roomID"`
+	RoomName          string    `json:"roomName"`
+	RoomType          string    `json:"roomType"`
+	RoomPrice         float64   `json:"roomPrice"`
+	SnackID           int       `json:"snackID"`
+	SnackName         string    `json:"snackName"`
+	SnackPrice        float64   `json:"snackPrice"`
+	DurationMinute    int       `json:"durationMinute"`
+	TotalParticipants int       `json:"totalParticipants"`
+	TotalRoom         float64   `json:"totalRoom"`
+	TotalSnack        float64   `json:"totalSnack"`
+	StartAt           time.Time `json:"startAt"`
+	EndAt             time.Time `json:"endAt"`
+	// Item snack, SnackID / SnackName / SnackPrice = item pertama
+	SnackLines []SnackLine `json:"snackLines"`
+	// Baris invoice room / fee / snack milik detail ini
+	InvoiceLines []InvoiceLine `json:"-"`
+}
+
+// ReservationChangeData: catatan perubahan (sebelum/sesudah) satu reservasi
+type ReservationChangeData struct {
+	ReservationID int
+	ChangedBy     int
+	Reason        string
+	BeforeTotal   float64
+	AfterTotal    float64
+	Before        []ReservationDetailData
+	After         []ReservationDetailData
+}
+
+type ReservationSeriesData struct {
+	ID        int
+	UserID    int
+	Frequency string
+	Interval  int
+	ByWeekday string
+	Until     *time.Time
+	Count     int
+}
+
+// ReservationOccurrenceData: satu reservasi lengkap (header + detail) di dalam series
+type ReservationOccurrenceData struct {
+	Reservation ReservationData
+	Details     []ReservationDetailData
+}
+
+type SeriesOccurrence struct {
+	ReservationID   int
+	UserID          int
+	Status          string
+	OccurrenceStart time.Time
+}
+
+// StatusChangeData: perubahan status satu reservasi di dalam batch (mis. cancel series)
+type StatusChangeData struct {
+	ReservationID int
+	FromStatus    string
+	Settlement    *CancellationSettlement
+}
+
+type CheckInResult struct {
+	ReservationID int       `json:"reservationID"`
+	DetailIDs     []int     `json:"detailIDs"`
+	CheckedInAt   time.Time `json:"checkedInAt"`
 }
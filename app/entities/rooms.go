@@ -11,18 +11,24 @@ type RoomRequest struct {
 	Capacity     int     `json:"capacity"`
 	PricePerHour float64 `json:"pricePerHour"`
 	ImageURL     string  `json:"imageURL"`
+	// Jeda (menit) sebelum dan sesudah meeting untuk persiapan / bersih-bersih.
+	// Pointer supaya saat update field yang tidak dikirim tetap memakai nilai lama
+	SetupBufferMinutes    *int `json:"setupBufferMinutes"`
+	TeardownBufferMinutes *int `json:"teardownBufferMinutes"`
 }
 
 // Response struct untuk rooms
 type Room struct {
-	ID           int       `json:"id"`
-	Name         string    `json:"name"`
-	RoomType     string    `json:"type"`
-	Capacity     int       `json:"capacity"`
-	PricePerHour float64   `json:"pricePerHour"`
-	PictureURL   string    `json:"imageURL"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID                    int       `json:"id"`
+	Name                  string    `json:"name"`
+	RoomType              string    `json:"type"`
+	Capacity              int       `json:"capacity"`
+	PricePerHour          float64   `json:"pricePerHour"`
+	PictureURL            string    `json:"imageURL"`
+	SetupBufferMinutes    int       `json:"setupBufferMinutes"`
+	TeardownBufferMinutes int       `json:"teardownBufferMinutes"`
+	CreatedAt             time.Time `json:"createdAt"`
+	UpdatedAt             time.Time `json:"updatedAt"`
 }
 
 type RoomReservationRequest struct {
@@ -32,6 +38,8 @@ type RoomReservationRequest struct {
 	Participant int       `json:"participant"`
 	SnackID     int       `json:"snackID"`
 	AddSnack    bool      `json:"addSnack"`
+	// Beberapa item snack sekaligus, jika diisi snackID / addSnack diabaikan
+	Snacks []SnackLineRequest `json:"snacks"`
 }
 
 type RoomInfo struct {
@@ -48,3 +56,12 @@ type RoomInfo struct {
 	Participant  int     `json:"participant"`
 	Snack        *Snack  `json:"snack,omitempty"`
 }
+
+// Response search room yang tersedia di rentang waktu tertentu
+type AvailableRoom struct {
+	Room
+	SubTotalRoom  float64 `json:"subTotalRoom"`
+	SubTotalSnack float64 `json:"subTotalSnack"`
+	Total         float64 `json:"total"`
+	Duration      int     `json:"duration"` // menit
+}
@@ -1,5 +1,7 @@
 package entities
 
+import "time"
+
 // Response struct untuk snacks
 type Snack struct {
 	ID       int     `json:"id"`
@@ -8,3 +10,23 @@ type Snack struct {
 	Price    float64 `json:"price"`
 	Category string  `json:"category"`
 }
+
+// SnackLineRequest: satu item snack untuk room yang dibooking
+type SnackLineRequest struct {
+	SnackID int `json:"snackID"`
+	// 0 = default: unit person -> jumlah peserta room, box -> 1
+	Quantity int `json:"quantity"`
+	// Jam penyajian, default jam mulai room
+	ServeAt *time.Time `json:"serveAt"`
+}
+
+// SnackLine: snapshot item snack per room (reservation_snack_lines), harga = price x quantity
+type SnackLine struct {
+	SnackID  int       `json:"snackID"`
+	Name     string    `json:"name"`
+	Unit     string    `json:"unit"` // person / box
+	Price    float64   `json:"price"`
+	Quantity int       `json:"quantity"`
+	ServeAt  time.Time `json:"serveAt"`
+	Subtotal float64   `json:"subtotal"`
+}
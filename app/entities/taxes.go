@@ -0,0 +1,49 @@
+package entities
+
+import "time"
+
+// TaxComponent: komponen pajak / service charge yang diatur admin.
+// Kind service dihitung dari total setelah diskon, kind tax (mis. PPN) dari total setelah diskon + service charge.
+// Setiap komponen punya aturan pembulatan sendiri: Rounding nearest / up / down ke kelipatan RoundingUnit.
+type TaxComponent struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Kind         string    `json:"kind"`
+	Rate         float64   `json:"rate"` // persen
+	Rounding     string    `json:"rounding"`
+	RoundingUnit float64   `json:"roundingUnit"`
+	SortOrder    int       `json:"sortOrder"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Request body untuk POST / PUT /tax-components
+type TaxComponentRequest struct {
+	Name         string  `json:"name" validate:"required"`
+	Kind         string  `json:"kind" validate:"oneof=service tax"`
+	Rate         float64 `json:"rate" validate:"gt=0,max=100"`
+	Rounding     string  `json:"rounding" validate:"omitempty,oneof=nearest up down"`
+	RoundingUnit float64 `json:"roundingUnit" validate:"min=0"`
+	SortOrder    int     `json:"sortOrder"`
+}
+
+// InvoiceLine: satu baris invoice reservasi, disimpan saat booking supaya total lama tidak berubah
+// walaupun harga / tarif pajak diubah. Type: room (waktu room), fee (pricing rule), snack (per peserta),
+// discount, service, tax. Jumlah Amount semua baris = total reservasi.
+type InvoiceLine struct {
+	ID          int     `json:"id,omitempty"`
+	DetailID    int     `json:"detailID,omitempty"`
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit,omitempty"`
+	// Baris service / tax: UnitPrice = dasar pengenaan, Amount = UnitPrice x Rate% setelah pembulatan
+	UnitPrice float64 `json:"unitPrice"`
+	// Persen untuk diskon persen, service charge dan pajak
+	Rate   float64 `json:"rate,omitempty"`
+	Amount float64 `json:"amount"`
+	// Snapshot komponen pajak (service / tax) yang dipakai, untuk hitung ulang saat modify / partial cancel
+	TaxComponentID int     `json:"taxComponentID,omitempty"`
+	Rounding       string  `json:"rounding,omitempty"`
+	RoundingUnit   float64 `json:"roundingUnit,omitempty"`
+}
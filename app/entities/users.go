@@ -35,6 +35,8 @@ type GetUser struct {
 	Updated_at sql.NullString `json:"updatedAt"`
 	Username   string         `json:"username"`
 	Name       string         `json:"name"`
+	// Jumlah room yang ditandai no-show (hanya diisi di profile)
+	NoShowCount int `json:"noShowCount"`
 }
 
 type UpdateUser struct {
@@ -0,0 +1,41 @@
+package entities
+
+import "time"
+
+// Request body untuk POST /waitlist
+type WaitlistRequest struct {
+	UserID      int       `json:"-"` // Diisi token
+	RoomID      int       `json:"roomID" validate:"required"`
+	StartTime   time.Time `json:"startTime" validate:"required"`
+	EndTime     time.Time `json:"endTime" validate:"required"`
+	Participant int       `json:"participant" validate:"required,min=1"`
+	SnackID     int       `json:"snackID"`
+	AddSnack    bool      `json:"addSnack"`
+	Name        string    `json:"name" validate:"required"`
+	PhoneNumber string    `json:"phoneNumber" validate:"required"`
+	Company     string    `json:"company" validate:"required"`
+	Notes       string    `json:"notes"`
+	// AutoBook = langsung dibooking saat slot kosong, jika false slot ditawarkan (hold) sementara
+	AutoBook bool `json:"autoBook"`
+}
+
+type WaitlistEntry struct {
+	ID             int        `json:"id"`
+	UserID         int        `json:"userID"`
+	RoomID         int        `json:"roomID"`
+	StartTime      time.Time  `json:"startTime"`
+	EndTime        time.Time  `json:"endTime"`
+	Participant    int        `json:"participant"`
+	SnackID        int        `json:"snackID"`
+	Name           string     `json:"name"`
+	PhoneNumber    string     `json:"phoneNumber"`
+	Company        string     `json:"company"`
+	Notes          string     `json:"notes"`
+	AutoBook       bool       `json:"autoBook"`
+	Status         string     `json:"status"`
+	ReservationID  int        `json:"reservationID,omitempty"`
+	OfferExpiresAt *time.Time `json:"offerExpiresAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	// Dipakai untuk email notifikasi
+	Email string `json:"-"`
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CalendarHandler struct {
+	usecase    usecases.CalendarUsecase
+	resUsecase usecases.ReservationUsecase
+}
+
+func NewCalendarHandler(usecase usecases.CalendarUsecase, resUsecase usecases.ReservationUsecase) *CalendarHandler {
+	return &CalendarHandler{usecase: usecase, resUsecase: resUsecase}
+}
+
+// CreateCalendarFeed godoc
+// @Summary Get calendar subscription link
+// @Description Get a tokenized read-only iCalendar feed URL for your upcoming reservations (type=user)
+// @Description or for a room schedule (type=room)
+// @Tags Calendar
+// @Accept json
+// @Produce json
+// @Param body body entities.CalendarFeedRequest true "Feed Type"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /calendar/feeds [post]
+func (h *CalendarHandler) CreateCalendarFeed(c echo.Context) error {
+	var req entities.CalendarFeedRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "type must be one of user, room"})
+	}
+
+	userID, err := currentUserID(c, h.resUsecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	baseURL := c.Scheme() + "://" + c.Request().Host
+
+	feed, err := h.usecase.GetFeed(userID, req, baseURL)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": feed})
+}
+
+// GetCalendarFeed godoc
+// @Summary Calendar subscription feed
+// @Description Read-only iCalendar feed, the token in the URL acts as the credential
+// @Tags Calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token (with .ics suffix)"
+// @Success 200 {string} string "iCalendar data"
+// @Failure 404 {object} map[string]string
+// @Router /calendar/{token} [get]
+func (h *CalendarHandler) GetCalendarFeed(c echo.Context) error {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	ics, err := h.usecase.FeedICS(token)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"message": err.Error()})
+	}
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", []byte(ics))
+}
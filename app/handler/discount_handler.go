@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DiscountHandler struct {
+	usecase usecases.DiscountUsecase
+}
+
+func NewDiscountHandler(usecase usecases.DiscountUsecase) *DiscountHandler {
+	return &DiscountHandler{usecase: usecase}
+}
+
+const discountValidationMessage = "discountType must be percent or fixed, discountValue must be greater than 0, roomTypes must be small, medium or large and limits cannot be negative"
+
+// GetPromoCodes godoc
+// @Summary Get promo codes
+// @Description Get every promo code with its current usage (active reservations using it)
+// @Tags Discount
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /promo-codes [get]
+func (h *DiscountHandler) GetPromoCodes(c echo.Context) error {
+	promos, err := h.usecase.GetPromoCodes()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "internal server error"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": promos})
+}
+
+// CreatePromoCode godoc
+// @Summary Create a promo code
+// @Description Percentage or fixed discount with an optional validity window, usage limits and eligible room types / snacks (empty = all)
+// @Tags Discount
+// @Accept json
+// @Produce json
+// @Param body body entities.PromoCodeRequest true "Promo Code"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /promo-codes [post]
+func (h *DiscountHandler) CreatePromoCode(c echo.Context) error {
+	var req entities.PromoCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "code and name are required, " + discountValidationMessage})
+	}
+
+	promo, err := h.usecase.CreatePromoCode(req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusCreated, echo.Map{"message": "success", "data": promo})
+}
+
+// UpdatePromoCode godoc
+// @Summary Update a promo code
+// @Tags Discount
+// @Accept json
+// @Produce json
+// @Param id path int true "Promo Code ID"
+// @Param body body entities.PromoCodeRequest true "Promo Code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /promo-codes/{id} [put]
+func (h *DiscountHandler) UpdatePromoCode(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+	var req entities.PromoCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "code and name are required, " + discountValidationMessage})
+	}
+
+	promo, err := h.usecase.UpdatePromoCode(id, req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": promo})
+}
+
+// DeletePromoCode godoc
+// @Summary Delete a promo code
+// @Description Reservations that already used the code keep their discount
+// @Tags Discount
+// @Produce json
+// @Param id path int true "Promo Code ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /promo-codes/{id} [delete]
+func (h *DiscountHandler) DeletePromoCode(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	if err := h.usecase.DeletePromoCode(id); err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "promo code deleted"})
+}
+
+// GetCompanyAgreements godoc
+// @Summary Get company agreements
+// @Description Get every company discount agreement with its current usage
+// @Tags Discount
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /company-agreements [get]
+func (h *DiscountHandler) GetCompanyAgreements(c echo.Context) error {
+	agreements, err := h.usecase.GetCompanyAgreements()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "internal server error"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": agreements})
+}
+
+// CreateCompanyAgreement godoc
+// @Summary Create a company agreement
+// @Description Discount negotiated with a client company. The agreement code only works when the reservation company matches companyName.
+// @Tags Discount
+// @Accept json
+// @Produce json
+// @Param body body entities.CompanyAgreementRequest true "Company Agreement"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /company-agreements [post]
+func (h *DiscountHandler) CreateCompanyAgreement(c echo.Context) error {
+	var req entities.CompanyAgreementRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "code and companyName are required, " + discountValidationMessage})
+	}
+
+	agreement, err := h.usecase.CreateCompanyAgreement(req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusCreated, echo.Map{"message": "success", "data": agreement})
+}
+
+// UpdateCompanyAgreement godoc
+// @Summary Update a company agreement
+// @Tags Discount
+// @Accept json
+// @Produce json
+// @Param id path int true "Company Agreement ID"
+// @Param body body entities.CompanyAgreementRequest true "Company Agreement"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /company-agreements/{id} [put]
+func (h *DiscountHandler) UpdateCompanyAgreement(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+	var req entities.CompanyAgreementRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "code and companyName are required, " + discountValidationMessage})
+	}
+
+	agreement, err := h.usecase.UpdateCompanyAgreement(id, req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": agreement})
+}
+
+// DeleteCompanyAgreement godoc
+// @Summary Delete a company agreement
+// @Tags Discount
+// @Produce json
+// @Param id path int true "Company Agreement ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /company-agreements/{id} [delete]
+func (h *DiscountHandler) DeleteCompanyAgreement(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	if err := h.usecase.DeleteCompanyAgreement(id); err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "company agreement deleted"})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OpeningHoursHandler struct {
+	usecase usecases.OpeningHoursUsecase
+}
+
+func NewOpeningHoursHandler(usecase usecases.OpeningHoursUsecase) *OpeningHoursHandler {
+	return &OpeningHoursHandler{usecase: usecase}
+}
+
+// GetOperatingHours godoc
+// @Summary Get operating hours
+// @Description Get the effective weekly opening hours of a room (weekday 0 = Sunday).
+// @Description Without roomID the global default is returned. roomID 0 in a day means it comes from the global default.
+// @Tags Opening Hours
+// @Produce json
+// @Param roomID query int false "Room ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /operating-hours [get]
+func (h *OpeningHoursHandler) GetOperatingHours(c echo.Context) error {
+	roomID := 0
+	if v := c.QueryParam("roomID"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid roomID"})
+		}
+		roomID = id
+	}
+
+	hours, err := h.usecase.GetOperatingHours(roomID)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": hours})
+}
+
+// SaveOperatingHours godoc
+// @Summary Replace operating hours
+// @Description Replace the weekly opening hours of a room (roomID 0 = global default).
+// @Description Days that are not sent follow the global default. Use "24:00" as closeTime to stay open until midnight.
+// @Tags Opening Hours
+// @Accept json
+// @Produce json
+// @Param body body entities.OperatingHoursRequest true "Operating Hours"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /operating-hours [put]
+func (h *OpeningHoursHandler) SaveOperatingHours(c echo.Context) error {
+	var req entities.OperatingHoursRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "weekday must be between 0 and 6"})
+	}
+
+	hours, err := h.usecase.SaveOperatingHours(req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": hours})
+}
+
+// GetBlackoutDates godoc
+// @Summary Get blackout dates
+// @Description Get blackout / holiday dates. With roomID, only that room's dates plus dates that close every room.
+// @Tags Opening Hours
+// @Produce json
+// @Param roomID query int false "Room ID"
+// @Param startDate query string false "Start Date (YYYY-MM-DD)"
+// @Param endDate query string false "End Date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /blackout-dates [get]
+func (h *OpeningHoursHandler) GetBlackoutDates(c echo.Context) error {
+	roomID := 0
+	if v := c.QueryParam("roomID"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid roomID"})
+		}
+		roomID = id
+	}
+
+	dates, err := h.usecase.GetBlackoutDates(roomID, c.QueryParam("startDate"), c.QueryParam("endDate"))
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": dates})
+}
+
+// CreateBlackoutDate godoc
+// @Summary Create blackout date
+// @Description Close a room (or every room when roomID is 0) for a date range, e.g. a public holiday
+// @Tags Opening Hours
+// @Accept json
+// @Produce json
+// @Param body body entities.BlackoutDateRequest true "Blackout Date"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /blackout-dates [post]
+func (h *OpeningHoursHandler) CreateBlackoutDate(c echo.Context) error {
+	var req entities.BlackoutDateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "startDate and name are required"})
+	}
+
+	date, err := h.usecase.CreateBlackoutDate(req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusCreated, echo.Map{"message": "success", "data": date})
+}
+
+// DeleteBlackoutDate godoc
+// @Summary Delete blackout date
+// @Tags Opening Hours
+// @Produce json
+// @Param id path int true "Blackout Date ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /blackout-dates/{id} [delete]
+func (h *OpeningHoursHandler) DeleteBlackoutDate(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	if err := h.usecase.DeleteBlackoutDate(id); err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "blackout date deleted"})
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"BE-E-Meeting/app/middleware"
+	"BE-E-Meeting/app/payments"
+	"BE-E-Meeting/app/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PaymentHandler struct {
+	usecase usecases.ReservationUsecase
+}
+
+func NewPaymentHandler(usecase usecases.ReservationUsecase) *PaymentHandler {
+	return &PaymentHandler{usecase: usecase}
+}
+
+// CreatePayment godoc
+// @Summary Create payment for a reservation
+// @Description Create a payment intent for the total of a booked reservation and return the payment URL.
+// @Description A pending payment that has not expired is returned again instead of creating a new one.
+// @Tags Payment
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 201 {object} entities.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/payment [post]
+func (h *PaymentHandler) CreatePayment(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	payment, err := h.usecase.CreatePayment(id, userID, middleware.ExtractTokenRole(c))
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusCreated, echo.Map{"message": "payment created", "data": payment})
+}
+
+// GetPayments godoc
+// @Summary Get payments of a reservation
+// @Description Get payment attempts of a reservation, newest first
+// @Tags Payment
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/payments [get]
+func (h *PaymentHandler) GetPayments(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	data, err := h.usecase.GetPayments(id, userID, middleware.ExtractTokenRole(c))
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": data})
+}
+
+// PaymentWebhook godoc
+// @Summary Payment provider webhook
+// @Description Callback from the payment provider, signed with HMAC-SHA256 of the body in X-Payment-Signature.
+// @Description status paid moves a booked reservation to paid; failed / expired only update the payment.
+// @Description Repeated events (same eventId) are acknowledged without being processed again.
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Success 200 {object} entities.PaymentWebhookResult
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /payments/webhook [post]
+func (h *PaymentHandler) PaymentWebhook(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, 1<<20))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid body"})
+	}
+
+	result, err := h.usecase.HandlePaymentWebhook(c.Request().Header, body)
+	if errors.Is(err, payments.ErrInvalidSignature) {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": err.Error()})
+	}
+	if err != nil {
+		log.Printf("payment: webhook rejected: %v", err)
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": result})
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PolicyHandler struct {
+	usecase usecases.PolicyUsecase
+}
+
+func NewPolicyHandler(usecase usecases.PolicyUsecase) *PolicyHandler {
+	return &PolicyHandler{usecase: usecase}
+}
+
+// GetCancellationPolicies godoc
+// @Summary Get cancellation policies
+// @Description Get the global cancellation policy (roomType empty) and the per room type policies
+// @Tags Policy
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /cancellation-policies [get]
+func (h *PolicyHandler) GetCancellationPolicies(c echo.Context) error {
+	policies, err := h.usecase.GetCancellationPolicies()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "internal server error"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": policies})
+}
+
+// SaveCancellationPolicy godoc
+// @Summary Create or update a cancellation policy
+// @Description Free cancellation until freeCancelHours before start, lateFeePercent afterward,
+// @Description noShowFeePercent once the reservation has started. Leave roomType empty for the global policy.
+// @Tags Policy
+// @Accept json
+// @Produce json
+// @Param body body entities.CancellationPolicyRequest true "Cancellation Policy"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /cancellation-policies [put]
+func (h *PolicyHandler) SaveCancellationPolicy(c echo.Context) error {
+	var req entities.CancellationPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "roomType must be small, medium or large and percentages must be between 0 and 100"})
+	}
+
+	policy, err := h.usecase.SaveCancellationPolicy(req)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "internal server error"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": policy})
+}
+
+// GetBookingRules godoc
+// @Summary Get booking rules
+// @Description Get the global booking rules (roomType empty) and the per room type rules
+// @Tags Policy
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /booking-rules [get]
+func (h *PolicyHandler) GetBookingRules(c echo.Context) error {
+	rules, err := h.usecase.GetBookingRules()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "internal server error"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": rules})
+}
+
+// SaveBookingRule godoc
+// @Summary Create or update booking rules
+// @Description Minimum advance notice, maximum advance horizon, min/max duration and slot alignment.
+// @Description 0 means no limit. Leave roomType empty for the global rules.
+// @Tags Policy
+// @Accept json
+// @Produce json
+// @Param body body entities.BookingRuleRequest true "Booking Rule"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /booking-rules [put]
+func (h *PolicyHandler) SaveBookingRule(c echo.Context) error {
+	var req entities.BookingRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "roomType must be small, medium or large, values cannot be negative and slotMinutes must be 0, 5, 10, 15, 30 or 60"})
+	}
+
+	rule, err := h.usecase.SaveBookingRule(req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": rule})
+}
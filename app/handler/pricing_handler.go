@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PricingHandler struct {
+	usecase usecases.PricingUsecase
+}
+
+func NewPricingHandler(usecase usecases.PricingUsecase) *PricingHandler {
+	return &PricingHandler{usecase: usecase}
+}
+
+// GetPricingRules godoc
+// @Summary Get pricing rules
+// @Description Get every pricing rule (roomType empty = applies to all rooms)
+// @Tags Pricing
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /pricing-rules [get]
+func (h *PricingHandler) GetPricingRules(c echo.Context) error {
+	rules, err := h.usecase.GetPricingRules()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "internal server error"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": rules})
+}
+
+// CreatePricingRule godoc
+// @Summary Create a pricing rule
+// @Description kind time_band: weekdays (0 = Sunday, empty = every day), startTime, endTime, multiplier (peak > 1, off-peak < 1).
+// @Description kind weekend: multiplier. kind holiday: startDate, endDate, multiplier.
+// @Description kind package: durationMinutes (max booking length) and packagePrice. kind long_booking: durationMinutes (min booking length) and discountPercent.
+// @Tags Pricing
+// @Accept json
+// @Produce json
+// @Param body body entities.PricingRuleRequest true "Pricing Rule"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /pricing-rules [post]
+func (h *PricingHandler) CreatePricingRule(c echo.Context) error {
+	var req entities.PricingRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "name is required, kind must be time_band, weekend, holiday, package or long_booking and values cannot be negative"})
+	}
+
+	rule, err := h.usecase.CreatePricingRule(req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusCreated, echo.Map{"message": "success", "data": rule})
+}
+
+// UpdatePricingRule godoc
+// @Summary Update a pricing rule
+// @Tags Pricing
+// @Accept json
+// @Produce json
+// @Param id path int true "Pricing Rule ID"
+// @Param body body entities.PricingRuleRequest true "Pricing Rule"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /pricing-rules/{id} [put]
+func (h *PricingHandler) UpdatePricingRule(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+	var req entities.PricingRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "name is required, kind must be time_band, weekend, holiday, package or long_booking and values cannot be negative"})
+	}
+
+	rule, err := h.usecase.UpdatePricingRule(id, req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": rule})
+}
+
+// DeletePricingRule godoc
+// @Summary Delete a pricing rule
+// @Tags Pricing
+// @Produce json
+// @Param id path int true "Pricing Rule ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /pricing-rules/{id} [delete]
+func (h *PricingHandler) DeletePricingRule(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	if err := h.usecase.DeletePricingRule(id); err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "pricing rule deleted"})
+}
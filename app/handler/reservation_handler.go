@@ -1,8 +1,13 @@
 package handler
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"BE-E-Meeting/app/entities"
@@ -14,11 +19,12 @@ import (
 )
 
 type ReservationHandler struct {
-	usecase usecases.ReservationUsecase
+	usecase         usecases.ReservationUsecase
+	calendarUsecase usecases.CalendarUsecase
 }
 
-func NewReservationHandler(usecase usecases.ReservationUsecase) *ReservationHandler {
-	return &ReservationHandler{usecase: usecase}
+func NewReservationHandler(usecase usecases.ReservationUsecase, calendarUsecase usecases.CalendarUsecase) *ReservationHandler {
+	return &ReservationHandler{usecase: usecase, calendarUsecase: calendarUsecase}
 }
 
 // CalculateReservation godoc
@@ -28,11 +34,21 @@ func NewReservationHandler(usecase usecases.ReservationUsecase) *ReservationHand
 // @Produce json
 // @Param room_id query int true "Room ID"
 // @Param snack_id query int false "Snack ID (0 if none)"
+// @Param snacks query string false "Snack items snackID:quantity@HH:MM, comma separated (e.g. 3:10@12:00,5:2@15:00)"
 // @Param startTime query string true "Start Time (RFC3339 format: 2025-10-20T09:00:00Z)"
 // @Param endTime query string true "End Time (RFC3339 format: 2025-10-20T11:00:00Z)"
 // @Param participant query int true "Participant Count"
+// @Param frequency query string false "Recurrence frequency (daily/weekly/monthly)"
+// @Param interval query int false "Recurrence interval (default: 1)"
+// @Param byWeekday query string false "Recurrence weekdays, comma separated (MO,TU,WE,TH,FR,SA,SU)"
+// @Param until query string false "Recurrence end (RFC3339)"
+// @Param count query int false "Number of occurrences"
+// @Param promoCode query string false "Promo code"
+// @Param agreementCode query string false "Company agreement code"
+// @Param company query string false "Company name (required with agreementCode)"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]interface{} "Conflict, with suggested alternative slots and rooms"
 // @Security BearerAuth
 // @Router /reservation/calculation [get]
 func (h *ReservationHandler) CalculateReservation(c echo.Context) error {
@@ -62,24 +78,95 @@ func (h *ReservationHandler) CalculateReservation(c echo.Context) error {
 			AddSnack:    addSnack,
 		}},
 		TotalParticipants: participant,
+		Company:           c.QueryParam("company"),
+		PromoCode:         c.QueryParam("promoCode"),
+		AgreementCode:     c.QueryParam("agreementCode"),
+	}
+	if snacks := c.QueryParam("snacks"); snacks != "" {
+		items, err := parseSnackItems(snacks, startTime)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
+		}
+		req.Rooms[0].Snacks = items
+	}
+	// User ID untuk batas pemakaian promo per user
+	if userID, err := currentUserID(c, h.usecase); err == nil {
+		req.UserID = userID
+	}
+
+	// Parameter recurrence (opsional) untuk simulasi booking berulang
+	if frequency := c.QueryParam("frequency"); frequency != "" {
+		interval, _ := strconv.Atoi(c.QueryParam("interval"))
+		count, _ := strconv.Atoi(c.QueryParam("count"))
+		rule := &entities.RecurrenceRule{Frequency: frequency, Interval: interval, Count: count}
+		if byWeekday := c.QueryParam("byWeekday"); byWeekday != "" {
+			rule.ByWeekday = strings.Split(byWeekday, ",")
+		}
+		if untilStr := c.QueryParam("until"); untilStr != "" {
+			until, err := time.Parse(time.RFC3339, untilStr)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid until, use RFC3339 format"})
+			}
+			rule.Until = &until
+		}
+		req.Recurrence = rule
 	}
 
 	res, err := h.usecase.Calculate(req)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
+		return errorJSON(c, err)
 	}
 	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": res})
 }
 
+// parseSnackItems: "3:10@12:00,5:2" -> snack 3 x10 disajikan 12:00, snack 5 x2 (quantity & jam opsional).
+// Jam penyajian di tanggal startTime, jika lebih awal dari startTime dianggap hari berikutnya.
+func parseSnackItems(value string, startTime time.Time) ([]entities.SnackLineRequest, error) {
+	var items []entities.SnackLineRequest
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		spec, clock, hasClock := strings.Cut(part, "@")
+		idStr, qtyStr, hasQty := strings.Cut(spec, ":")
+
+		var item entities.SnackLineRequest
+		var err error
+		if item.SnackID, err = strconv.Atoi(idStr); err != nil {
+			return nil, fmt.Errorf("invalid snacks item %q", part)
+		}
+		if hasQty {
+			if item.Quantity, err = strconv.Atoi(qtyStr); err != nil {
+				return nil, fmt.Errorf("invalid snacks item %q", part)
+			}
+		}
+		if hasClock {
+			t, err := time.Parse("15:04", clock)
+			if err != nil {
+				return nil, fmt.Errorf("invalid serving time in snacks item %q, use HH:MM", part)
+			}
+			serveAt := time.Date(startTime.Year(), startTime.Month(), startTime.Day(), t.Hour(), t.Minute(), 0, 0, startTime.Location())
+			if serveAt.Before(startTime) {
+				serveAt = serveAt.AddDate(0, 0, 1)
+			}
+			item.ServeAt = &serveAt
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
 // CreateReservation godoc
 // @Summary Create a new reservation
-// @Description Create a new reservation transaction (Booking)
+// @Description Create a new reservation transaction (Booking).
+// @Description If recurrence is set, one reservation per occurrence is created as a series.
+// @Description If hold is true, the slot is held (status hold) until it expires or is converted to booked.
+// @Description hold cannot be combined with recurrence.
 // @Tags Reservation
 // @Accept json
 // @Produce json
 // @Param request body entities.ReservationRequest true "Reservation Data"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]interface{} "Conflict, with suggested alternative slots and rooms"
 // @Failure 500 {object} map[string]string
 // @Security BearerAuth
 // @Router /reservation [post]
@@ -90,24 +177,122 @@ func (h *ReservationHandler) CreateReservation(c echo.Context) error {
 	}
 
 	// Ambil User ID dari Token
-	userToken := c.Get("user").(*jwt.Token)
-	claims := userToken.Claims.(jwt.MapClaims)
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+	req.UserID = userID
 
-	if username, ok := claims["username"].(string); ok {
-		userID, err := h.usecase.GetUserIDByUsername(username)
+	if req.Recurrence != nil && req.Hold {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "hold is not supported for recurring reservations"})
+	}
+	if req.Recurrence != nil {
+		if err := c.Validate(req.Recurrence); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"message": "recurrence frequency must be daily, weekly or monthly"})
+		}
+		result, err := h.usecase.CreateSeries(req)
 		if err != nil {
-			return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+			return errorJSON(c, err)
 		}
-		req.UserID = userID
-	} else {
-		req.UserID = middleware.ExtractTokenUserID(c)
+		return c.JSON(http.StatusOK, echo.Map{"message": "reservation series created successfully", "data": result})
 	}
 
-	err := h.usecase.Create(req)
+	if req.Hold {
+		expiresAt, err := h.usecase.CreateHold(req)
+		if err != nil {
+			return errorJSON(c, err)
+		}
+		return c.JSON(http.StatusOK, echo.Map{"message": "reservation held successfully", "data": echo.Map{"holdExpiresAt": expiresAt}})
+	}
+
+	status, err := h.usecase.Create(req)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{"message": err.Error()})
+		return errorJSON(c, err)
 	}
-	return c.JSON(http.StatusOK, echo.Map{"message": "reservation created successfully"})
+	if status == "pending" {
+		return c.JSON(http.StatusOK, echo.Map{"message": "reservation submitted for approval", "data": echo.Map{"status": status}})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "reservation created successfully", "data": echo.Map{"status": status}})
+}
+
+// UpdateReservationSeries godoc
+// @Summary Reschedule a recurring reservation
+// @Description Move "this" occurrence, "following" occurrences or "all" occurrences of a series.
+// @Description startTime/endTime are the new times of the selected occurrence, the same shift is applied to the others.
+// @Tags Reservation
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID (any occurrence of the series)"
+// @Param body body entities.UpdateSeriesRequest true "Series Update"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/series [put]
+func (h *ReservationHandler) UpdateReservationSeries(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	var req entities.UpdateSeriesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "scope must be one of this, following, all and times are required"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	updated, err := h.usecase.UpdateSeries(id, userID, middleware.ExtractTokenRole(c), req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "update series success", "data": echo.Map{"updated": updated}})
+}
+
+// CancelReservationSeries godoc
+// @Summary Cancel a recurring reservation
+// @Description Cancel "this" occurrence, "following" occurrences or "all" occurrences of a series
+// @Tags Reservation
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID (any occurrence of the series)"
+// @Param body body entities.CancelSeriesRequest true "Series Cancel"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/series/cancel [put]
+func (h *ReservationHandler) CancelReservationSeries(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	var req entities.CancelSeriesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "scope must be one of this, following, all"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	cancelled, err := h.usecase.CancelSeries(id, userID, middleware.ExtractTokenRole(c), req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "cancel series success", "data": echo.Map{"cancelled": cancelled}})
 }
 
 // GetHistory godoc
@@ -162,8 +347,12 @@ func (h *ReservationHandler) GetHistory(c echo.Context) error {
 // @Description Get full detail of a reservation by ID
 // @Tags Reservation
 // @Produce json
+// @Description Use ?format=ics (or Accept: text/calendar) to download the reservation as an iCalendar file
+// @Produce text/calendar
 // @Param id path int true "Reservation ID"
+// @Param format query string false "Response format (json/ics)"
 // @Success 200 {object} entities.ReservationDetailResponse
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Security BearerAuth
 // @Router /reservation/{id} [get]
@@ -173,22 +362,41 @@ func (h *ReservationHandler) GetReservationByID(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
 	}
 
-	res, err := h.usecase.GetByID(id)
+	userID, err := currentUserID(c, h.usecase)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, echo.Map{"message": "reservation not found"})
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	// Cek pemilik / admin dulu, termasuk untuk export .ics
+	res, err := h.usecase.GetByID(id, userID, middleware.ExtractTokenRole(c))
+	if err != nil {
+		return errorJSON(c, err)
+	}
+
+	if c.QueryParam("format") == "ics" || strings.Contains(c.Request().Header.Get("Accept"), "text/calendar") {
+		ics, err := h.calendarUsecase.ReservationICS(id)
+		if err != nil {
+			return errorJSON(c, err)
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="reservation-%d.ics"`, id))
+		return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", []byte(ics))
 	}
 	return c.JSON(http.StatusOK, res)
 }
 
 // UpdateReservationStatus godoc
 // @Summary Update reservation status
-// @Description Update status (booked -> paid/cancel)
+// @Description Update status following the reservation lifecycle:
+// @Description hold -> booked/cancel (owner/admin), booked -> paid (admin), booked -> cancel (owner/admin),
+// @Description paid -> refunded (admin), paid -> cancel (admin), pending -> booked (admin), pending -> cancel (owner/admin).
+// @Description Confirming a hold that needs approval moves it to pending instead of booked.
 // @Tags Reservation
 // @Accept json
 // @Produce json
 // @Param body body entities.UpdateReservationRequest true "Status Update"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Security BearerAuth
 // @Router /reservation/status [put]
 func (h *ReservationHandler) UpdateReservationStatus(c echo.Context) error {
@@ -196,17 +404,359 @@ func (h *ReservationHandler) UpdateReservationStatus(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
 	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "status must be one of booked, cancel, paid, refunded"})
+	}
 
-	userID := middleware.ExtractTokenUserID(c)
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+	userRole := middleware.ExtractTokenRole(c)
 
-	err := h.usecase.UpdateStatus(req.ReservationID, userID, req.Status, "user")
+	err = h.usecase.UpdateStatus(req.ReservationID, userID, req.Status, userRole, req.Reason)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
+		return errorJSON(c, err)
 	}
 
 	return c.JSON(http.StatusOK, echo.Map{"message": "update status success"})
 }
 
+// GetPendingApprovals godoc
+// @Summary Get approval queue
+// @Description Get reservations waiting for admin approval, oldest first, with the other bookings
+// @Description of the same room on that day and the number of waitlist entries for each slot
+// @Tags Reservation
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservations/approvals [get]
+func (h *ReservationHandler) GetPendingApprovals(c echo.Context) error {
+	approvals, err := h.usecase.GetPendingApprovals()
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": approvals})
+}
+
+// ApproveReservation godoc
+// @Summary Approve a pending reservation
+// @Description Approve a reservation waiting for approval (pending -> booked). The requester is notified by email.
+// @Tags Reservation
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Param body body entities.ApprovalDecisionRequest false "Comment"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/approve [put]
+func (h *ReservationHandler) ApproveReservation(c echo.Context) error {
+	return h.decideApproval(c, h.usecase.ApproveReservation, "reservation approved")
+}
+
+// RejectReservation godoc
+// @Summary Reject a pending reservation
+// @Description Reject a reservation waiting for approval (pending -> cancel, no fee). A comment is required
+// @Description and sent to the requester by email. The slot is offered to the waitlist.
+// @Tags Reservation
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Param body body entities.ApprovalDecisionRequest true "Comment"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/reject [put]
+func (h *ReservationHandler) RejectReservation(c echo.Context) error {
+	return h.decideApproval(c, h.usecase.RejectReservation, "reservation rejected")
+}
+
+func (h *ReservationHandler) decideApproval(c echo.Context, decide func(id, adminID int, comment string) error, message string) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+	var req entities.ApprovalDecisionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+
+	adminID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	if err := decide(id, adminID, req.Comment); err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": message})
+}
+
+// GetReservationStatusHistories godoc
+// @Summary Get reservation status history
+// @Description Get who changed the reservation status, when, and why
+// @Tags Reservation
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/status-history [get]
+func (h *ReservationHandler) GetReservationStatusHistories(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	histories, err := h.usecase.GetStatusHistories(id, userID, middleware.ExtractTokenRole(c))
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": histories})
+}
+
+// ModifyReservation godoc
+// @Summary Modify a reservation
+// @Description Change time, room, participant or snack per reservation detail (detailID) of a booked reservation.
+// @Description Price is recalculated and the difference is returned. Set preview=true to only see the price diff.
+// @Tags Reservation
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Param body body entities.ModifyReservationRequest true "Modify Reservation"
+// @Success 200 {object} entities.ModifyReservationResult
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /reservation/{id} [put]
+func (h *ReservationHandler) ModifyReservation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	var req entities.ModifyReservationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "rooms with detailID are required"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	result, err := h.usecase.Modify(id, userID, middleware.ExtractTokenRole(c), req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+
+	message := "modify reservation success"
+	if !result.Applied {
+		message = "preview"
+	} else if result.Status == "pending" {
+		message = "modification submitted for approval"
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": message, "data": result})
+}
+
+// CancelReservationDetails godoc
+// @Summary Cancel some rooms of a reservation
+// @Description Cancel individual rooms (detailID from GET /reservation/{id}) of a multi-room reservation.
+// @Description Subtotal and total are recalculated, cancelling every room cancels the reservation.
+// @Tags Reservation
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Param body body entities.CancelDetailsRequest true "Details to cancel"
+// @Success 200 {object} entities.CancelDetailsResult
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/details/cancel [put]
+func (h *ReservationHandler) CancelReservationDetails(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	var req entities.CancelDetailsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "detailIDs is required"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	result, err := h.usecase.CancelDetails(id, userID, middleware.ExtractTokenRole(c), req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "cancel room success", "data": result})
+}
+
+// CheckInReservation godoc
+// @Summary Check in to a reservation
+// @Description Check in by the reservation owner (or admin). Open from a few minutes before start time;
+// @Description rooms without check-in after the grace period are marked as no-show and released.
+// @Tags Reservation
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} entities.CheckInResult
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/check-in [post]
+func (h *ReservationHandler) CheckInReservation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	result, err := h.usecase.CheckIn(id, userID, middleware.ExtractTokenRole(c))
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "check-in success", "data": result})
+}
+
+// GetReservationInvoice godoc
+// @Summary Download reservation invoice / receipt PDF
+// @Description Invoice is available once the reservation is booked, receipt once it is paid.
+// @Description Numbers are issued sequentially per year on first download and reused afterwards.
+// @Tags Reservation
+// @Produce application/pdf
+// @Param id path int true "Reservation ID"
+// @Param type query string false "invoice (default) or receipt"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/invoice [get]
+func (h *ReservationHandler) GetReservationInvoice(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	file, err := h.usecase.GetInvoice(id, userID, middleware.ExtractTokenRole(c), invoiceKind(c))
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", file.FileName))
+	return c.Blob(http.StatusOK, "application/pdf", file.Content)
+}
+
+// EmailReservationInvoice godoc
+// @Summary Email reservation invoice / receipt
+// @Description Send the invoice / receipt PDF as attachment to the reservation owner's email
+// @Tags Reservation
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Param type query string false "invoice (default) or receipt"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/invoice/email [post]
+func (h *ReservationHandler) EmailReservationInvoice(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	email, err := h.usecase.EmailInvoice(id, userID, middleware.ExtractTokenRole(c), invoiceKind(c))
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "invoice sent", "email": email})
+}
+
+func invoiceKind(c echo.Context) string {
+	if kind := c.QueryParam("type"); kind != "" {
+		return kind
+	}
+	return "invoice"
+}
+
+// CheckInByRoomToken godoc
+// @Summary Check in with room QR code
+// @Description Check in to the reservation currently running in the room identified by the QR token
+// @Tags Reservation
+// @Produce json
+// @Param token path string true "Room check-in token"
+// @Success 200 {object} entities.CheckInResult
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /rooms/check-in/{token} [post]
+func (h *ReservationHandler) CheckInByRoomToken(c echo.Context) error {
+	result, err := h.usecase.CheckInByRoomToken(c.Param("token"))
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "check-in success", "data": result})
+}
+
+// GetReservationChangeHistories godoc
+// @Summary Get reservation change history
+// @Description Get the before/after snapshot of every modification or reschedule of a reservation
+// @Tags Reservation
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /reservation/{id}/changes [get]
+func (h *ReservationHandler) GetReservationChangeHistories(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	histories, err := h.usecase.GetChangeHistories(id, userID, middleware.ExtractTokenRole(c))
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": histories})
+}
+
 // GetReservationSchedules godoc
 // @Summary Get all schedules
 // @Description Get reservation schedules for all rooms (Admin Dashboard)
@@ -247,6 +797,7 @@ func (h *ReservationHandler) GetReservationSchedules(c echo.Context) error {
 // @Produce json
 // @Param id path int true "Room ID"
 // @Param date query string false "Date Filter (YYYY-MM-DD)"
+// @Param includeCancelled query bool false "Include cancelled and no-show rooms, labelled cancel / no_show (default: false)"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -255,6 +806,7 @@ func (h *ReservationHandler) GetReservationSchedules(c echo.Context) error {
 func (h *ReservationHandler) GetRoomReservationSchedule(c echo.Context) error {
 	roomID, _ := strconv.Atoi(c.Param("id"))
 	dateStr := c.QueryParam("date")
+	includeCancelled, _ := strconv.ParseBool(c.QueryParam("includeCancelled"))
 
 	var startDT, endDT time.Time
 	var err error
@@ -270,10 +822,114 @@ func (h *ReservationHandler) GetRoomReservationSchedule(c echo.Context) error {
 		endDT = startDT.Add(24 * time.Hour)
 	}
 
-	res, err := h.usecase.GetRoomSchedule(roomID, startDT, endDT)
+	res, err := h.usecase.GetRoomSchedule(roomID, startDT, endDT, includeCancelled)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"message": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": res})
 }
+
+// HELPER
+
+type userIDResolver interface {
+	GetUserIDByUsername(username string) (int, error)
+}
+
+// currentUserID mengambil ID user yang login. Token login hanya berisi username,
+// jadi ID dicari lewat username; token OAuth sudah berisi claim "id".
+func currentUserID(c echo.Context, resolver userIDResolver) (int, error) {
+	userToken := c.Get("user").(*jwt.Token)
+	claims := userToken.Claims.(jwt.MapClaims)
+
+	if username, ok := claims["username"].(string); ok {
+		return resolver.GetUserIDByUsername(username)
+	}
+	return middleware.ExtractTokenUserID(c), nil
+}
+
+// errorJSON menulis response error; bentrok jadwal disertai saran slot/room alternatif,
+// pelanggaran aturan booking disertai daftar aturan yang dilanggar.
+// Error internal (database, service luar) hanya dicatat di log, client menerima pesan umum.
+func errorJSON(c echo.Context, err error) error {
+	status := statusFromError(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Request().Method, c.Path(), err)
+		return c.JSON(status, echo.Map{"message": "internal server error"})
+	}
+
+	body := echo.Map{"message": err.Error()}
+	var conflictErr *entities.ConflictError
+	if errors.As(err, &conflictErr) && conflictErr.Suggestions != nil {
+		body["suggestions"] = conflictErr.Suggestions
+	}
+	var ruleErr *entities.ValidationError
+	if errors.As(err, &ruleErr) {
+		body["message"] = "booking rules violated"
+		body["violations"] = ruleErr.Violations
+	}
+	return c.JSON(status, body)
+}
+
+// statusFromError memetakan error dari usecase ke HTTP status code.
+// Error yang tidak dikenali dianggap error internal (500).
+func statusFromError(err error) int {
+	var conflictErr *entities.ConflictError
+	var forbiddenErr *entities.ForbiddenError
+	var badRequestErr *entities.BadRequestError
+	var closedErr *entities.ClosedError
+	var ruleErr *entities.ValidationError
+	switch {
+	case errors.As(err, &conflictErr):
+		return http.StatusConflict
+	case errors.As(err, &forbiddenErr):
+		return http.StatusForbidden
+	case errors.Is(err, entities.ErrNotFound), errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.As(err, &badRequestErr), errors.As(err, &closedErr), errors.As(err, &ruleErr):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// SearchAvailableRooms godoc
+// @Summary Find available rooms
+// @Description Find rooms with no active reservation in the requested time window,
+// @Description sorted by best fit (smallest adequate capacity, then lowest price)
+// @Tags Room
+// @Produce json
+// @Param startTime query string true "Start Time (RFC3339 format: 2025-10-20T14:00:00Z)"
+// @Param endTime query string true "End Time (RFC3339 format: 2025-10-20T16:00:00Z)"
+// @Param participant query int false "Participant count (minimum capacity)"
+// @Param type query string false "Room type (small/medium/large)"
+// @Param name query string false "Room name"
+// @Param snack_id query int false "Snack ID to include in the price"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /rooms/available [get]
+func (h *ReservationHandler) SearchAvailableRooms(c echo.Context) error {
+	startTime, err := time.Parse(time.RFC3339, c.QueryParam("startTime"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid startTime, use RFC3339 format"})
+	}
+	endTime, err := time.Parse(time.RFC3339, c.QueryParam("endTime"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid endTime, use RFC3339 format"})
+	}
+
+	participant, _ := strconv.Atoi(c.QueryParam("participant"))
+	snackID, _ := strconv.Atoi(c.QueryParam("snack_id"))
+
+	rooms, err := h.usecase.SearchAvailableRooms(c.QueryParam("name"), c.QueryParam("type"), participant, snackID, startTime, endTime)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":   "success",
+		"data":      rooms,
+		"totalData": len(rooms),
+	})
+}
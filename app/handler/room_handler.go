@@ -183,3 +183,32 @@ func (h *RoomHandler) DeleteRoom(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, echo.Map{"message": "delete room success"})
 }
+
+// GetRoomCheckInToken godoc
+// @Summary Get room check-in QR token
+// @Description Get the token to print as QR code in the room, scanning it calls POST /rooms/check-in/{token}
+// @Tags Room
+// @Produce json
+// @Param id path int true "Room ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /rooms/{id}/check-in-token [get]
+func (h *RoomHandler) GetRoomCheckInToken(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid room id"})
+	}
+
+	token, err := h.usecase.GetCheckInToken(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"message": err.Error()})
+	}
+
+	checkInURL := c.Scheme() + "://" + c.Request().Host + "/rooms/check-in/" + token
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success",
+		"data":    echo.Map{"token": token, "checkInURL": checkInURL},
+	})
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TaxHandler struct {
+	usecase usecases.TaxUsecase
+}
+
+func NewTaxHandler(usecase usecases.TaxUsecase) *TaxHandler {
+	return &TaxHandler{usecase: usecase}
+}
+
+const taxValidationMessage = "name is required, kind must be service or tax, rate must be between 0 and 100 and rounding must be nearest, up or down"
+
+// GetTaxComponents godoc
+// @Summary Get tax components
+// @Description Get every service charge and tax component used for new reservations
+// @Tags Tax
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /tax-components [get]
+func (h *TaxHandler) GetTaxComponents(c echo.Context) error {
+	components, err := h.usecase.GetTaxComponents()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "internal server error"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": components})
+}
+
+// CreateTaxComponent godoc
+// @Summary Create a tax component
+// @Description kind service is charged on the total after discount, kind tax (e.g. PPN) on the total after discount + service charge.
+// @Description Each line is rounded with its own rule (rounding nearest / up / down to a multiple of roundingUnit, default nearest 1).
+// @Tags Tax
+// @Accept json
+// @Produce json
+// @Param body body entities.TaxComponentRequest true "Tax Component"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /tax-components [post]
+func (h *TaxHandler) CreateTaxComponent(c echo.Context) error {
+	var req entities.TaxComponentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": taxValidationMessage})
+	}
+
+	component, err := h.usecase.CreateTaxComponent(req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusCreated, echo.Map{"message": "success", "data": component})
+}
+
+// UpdateTaxComponent godoc
+// @Summary Update a tax component
+// @Description Existing reservations keep the rate saved on their invoice lines
+// @Tags Tax
+// @Accept json
+// @Produce json
+// @Param id path int true "Tax Component ID"
+// @Param body body entities.TaxComponentRequest true "Tax Component"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /tax-components/{id} [put]
+func (h *TaxHandler) UpdateTaxComponent(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+	var req entities.TaxComponentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": taxValidationMessage})
+	}
+
+	component, err := h.usecase.UpdateTaxComponent(id, req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": component})
+}
+
+// DeleteTaxComponent godoc
+// @Summary Delete a tax component
+// @Tags Tax
+// @Produce json
+// @Param id path int true "Tax Component ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /tax-components/{id} [delete]
+func (h *TaxHandler) DeleteTaxComponent(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	if err := h.usecase.DeleteTaxComponent(id); err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "tax component deleted"})
+}
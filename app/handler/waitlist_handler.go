@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/middleware"
+	"BE-E-Meeting/app/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WaitlistHandler struct {
+	usecase usecases.ReservationUsecase
+}
+
+func NewWaitlistHandler(usecase usecases.ReservationUsecase) *WaitlistHandler {
+	return &WaitlistHandler{usecase: usecase}
+}
+
+// JoinWaitlist godoc
+// @Summary Join waitlist
+// @Description Join the waitlist of a fully booked room and time window. When an overlapping reservation
+// @Description is cancelled or a hold expires, the first waiting entry is booked (autoBook=true)
+// @Description or offered as a temporary hold, and notified by email.
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param body body entities.WaitlistRequest true "Waitlist Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Security BearerAuth
+// @Router /waitlist [post]
+func (h *WaitlistHandler) JoinWaitlist(c echo.Context) error {
+	var req entities.WaitlistRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid format"})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "roomID, startTime, endTime, participant and contact data are required"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+	req.UserID = userID
+
+	entry, err := h.usecase.JoinWaitlist(req)
+	if err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "joined waitlist", "data": entry})
+}
+
+// GetWaitlist godoc
+// @Summary Get my waitlist
+// @Description Get waitlist entries of the logged in user
+// @Tags Waitlist
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /waitlist [get]
+func (h *WaitlistHandler) GetWaitlist(c echo.Context) error {
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	entries, err := h.usecase.GetWaitlist(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "internal server error"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "success", "data": entries})
+}
+
+// LeaveWaitlist godoc
+// @Summary Leave waitlist
+// @Description Remove a waiting entry from the waitlist
+// @Tags Waitlist
+// @Produce json
+// @Param id path int true "Waitlist ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /waitlist/{id} [delete]
+func (h *WaitlistHandler) LeaveWaitlist(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid id"})
+	}
+
+	userID, err := currentUserID(c, h.usecase)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "user not found"})
+	}
+
+	if err := h.usecase.LeaveWaitlist(id, userID, middleware.ExtractTokenRole(c)); err != nil {
+		return errorJSON(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"message": "left waitlist"})
+}
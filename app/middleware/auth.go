@@ -92,6 +92,33 @@ func ExtractTokenUserID(c echo.Context) int {
 	return 0
 }
 
+// ExtractTokenRole mengambil role user dari token JWT yang sudah disimpan di context
+func ExtractTokenRole(c echo.Context) string {
+	user, ok := c.Get("user").(*jwt.Token)
+	if !ok || !user.Valid {
+		return ""
+	}
+
+	claims := user.Claims.(jwt.MapClaims)
+	if role, ok := claims["role"].(string); ok {
+		return role
+	}
+	if roles, ok := claims["role"].([]interface{}); ok {
+		// Jika role berupa list, admin diprioritaskan
+		for _, r := range roles {
+			if r == "admin" {
+				return "admin"
+			}
+		}
+		if len(roles) > 0 {
+			if role, ok := roles[0].(string); ok {
+				return role
+			}
+		}
+	}
+	return ""
+}
+
 // untuk Oauth
 func GenerateToken(userID int, username, role string) (string, error) {
 	claims := jwt.MapClaims{}
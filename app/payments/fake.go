@@ -0,0 +1,33 @@
+package payments
+
+import (
+	"net/http"
+
+	"BE-E-Meeting/app/entities"
+)
+
+// FakeProvider: provider lokal tanpa gateway, untuk development & testing.
+// Intent langsung dibuat, pembayaran disimulasikan dengan mengirim webhook yang ditandatangani WebhookSecret.
+type FakeProvider struct {
+	WebhookSecret string
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{WebhookSecret: webhookSecret}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) CreateIntent(req entities.PaymentIntentRequest) (entities.PaymentIntent, error) {
+	return entities.PaymentIntent{
+		ProviderReference: "fake_" + req.Reference,
+		PaymentURL:        "https://fake-payment.local/pay/" + req.Reference,
+		ExpiresAt:         req.ExpiresAt,
+	}, nil
+}
+
+func (p *FakeProvider) ParseWebhook(header http.Header, body []byte) (entities.PaymentWebhookEvent, error) {
+	return parseSignedWebhook(p.Name(), p.WebhookSecret, header, body)
+}
@@ -0,0 +1,98 @@
+package payments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+)
+
+// HTTPGateway: payment gateway lewat REST API.
+//
+//	POST {BaseURL}/payment-intents (Basic auth ServerKey)
+//	  {"reference", "amount", "currency", "description", "customerName", "customerEmail", "expiresAt"}
+//	-> {"id", "paymentUrl", "expiresAt"}
+//
+// Callback memakai format webhookPayload dengan signature di SignatureHeader.
+type HTTPGateway struct {
+	BaseURL       string
+	ServerKey     string
+	WebhookSecret string
+	Client        *http.Client
+}
+
+func NewHTTPGateway(baseURL, serverKey, webhookSecret string) *HTTPGateway {
+	return &HTTPGateway{
+		BaseURL:       strings.TrimRight(baseURL, "/"),
+		ServerKey:     serverKey,
+		WebhookSecret: webhookSecret,
+		Client:        &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (g *HTTPGateway) Name() string {
+	return "gateway"
+}
+
+func (g *HTTPGateway) CreateIntent(req entities.PaymentIntentRequest) (entities.PaymentIntent, error) {
+	var intent entities.PaymentIntent
+	body, err := json.Marshal(map[string]interface{}{
+		"reference":     req.Reference,
+		"amount":        req.Amount,
+		"currency":      "IDR",
+		"description":   req.Description,
+		"customerName":  req.CustomerName,
+		"customerEmail": req.CustomerEmail,
+		"expiresAt":     req.ExpiresAt,
+	})
+	if err != nil {
+		return intent, err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, g.BaseURL+"/payment-intents", bytes.NewReader(body))
+	if err != nil {
+		return intent, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.SetBasicAuth(g.ServerKey, "")
+
+	resp, err := g.Client.Do(httpReq)
+	if err != nil {
+		return intent, fmt.Errorf("payment gateway: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return intent, fmt.Errorf("payment gateway: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		ID         string    `json:"id"`
+		PaymentURL string    `json:"paymentUrl"`
+		ExpiresAt  time.Time `json:"expiresAt"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return intent, fmt.Errorf("payment gateway: invalid response: %w", err)
+	}
+	if result.PaymentURL == "" {
+		return intent, fmt.Errorf("payment gateway: paymentUrl is empty")
+	}
+
+	intent.ProviderReference = result.ID
+	intent.PaymentURL = result.PaymentURL
+	intent.ExpiresAt = result.ExpiresAt
+	if intent.ExpiresAt.IsZero() {
+		intent.ExpiresAt = req.ExpiresAt
+	}
+	return intent, nil
+}
+
+func (g *HTTPGateway) ParseWebhook(header http.Header, body []byte) (entities.PaymentWebhookEvent, error) {
+	return parseSignedWebhook(g.Name(), g.WebhookSecret, header, body)
+}
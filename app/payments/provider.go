@@ -0,0 +1,77 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"BE-E-Meeting/app/entities"
+)
+
+// Provider adalah payment gateway yang dipakai reservasi. Implementasi: FakeProvider (lokal / testing)
+// dan HTTPGateway (gateway asli), dipilih lewat env PAYMENT_PROVIDER (lihat config.LoadPaymentProvider).
+type Provider interface {
+	Name() string
+	// CreateIntent membuat tagihan di provider dan mengembalikan URL pembayaran
+	CreateIntent(req entities.PaymentIntentRequest) (entities.PaymentIntent, error)
+	// ParseWebhook memverifikasi signature callback lalu membaca isinya
+	ParseWebhook(header http.Header, body []byte) (entities.PaymentWebhookEvent, error)
+}
+
+// SignatureHeader: header berisi HMAC-SHA256 (hex) dari body webhook
+const SignatureHeader = "X-Payment-Signature"
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign menghitung signature body webhook dengan secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookPayload: format callback yang dipakai FakeProvider dan HTTPGateway
+type webhookPayload struct {
+	EventID           string  `json:"eventId"`
+	Reference         string  `json:"reference"`
+	ProviderReference string  `json:"providerReference"`
+	Status            string  `json:"status"`
+	Amount            float64 `json:"amount"`
+	Reason            string  `json:"reason"`
+}
+
+// parseSignedWebhook: cek signature (constant time), lalu validasi isi callback
+func parseSignedWebhook(provider, secret string, header http.Header, body []byte) (entities.PaymentWebhookEvent, error) {
+	event := entities.PaymentWebhookEvent{Provider: provider, Payload: string(body)}
+	if secret == "" {
+		return event, errors.New("webhook secret is not configured")
+	}
+	expected := Sign(secret, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(SignatureHeader))) {
+		return event, ErrInvalidSignature
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return event, &entities.BadRequestError{Message: "invalid webhook payload"}
+	}
+	if payload.EventID == "" || payload.Reference == "" {
+		return event, &entities.BadRequestError{Message: "eventId and reference are required"}
+	}
+	switch payload.Status {
+	case "paid", "failed", "expired":
+	default:
+		return event, &entities.BadRequestError{Message: "status must be paid, failed or expired"}
+	}
+
+	event.EventID = payload.EventID
+	event.Reference = payload.Reference
+	event.ProviderReference = payload.ProviderReference
+	event.Status = payload.Status
+	event.Amount = payload.Amount
+	event.Reason = payload.Reason
+	return event, nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+)
+
+type CalendarRepository interface {
+	GetFeed(feedType string, ownerID, createdBy int) (entities.CalendarFeed, error)
+	GetFeedByToken(token string) (entities.CalendarFeed, error)
+	CreateFeed(feed entities.CalendarFeed, createdBy int) error
+	GetEventsByReservationID(reservationID int) ([]entities.CalendarEvent, error)
+	GetUserEvents(userID int, from time.Time) ([]entities.CalendarEvent, error)
+	GetRoomEvents(roomID int, from time.Time) ([]entities.CalendarEvent, error)
+}
+
+type calendarRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarRepository(db *sql.DB) CalendarRepository {
+	return &calendarRepository{db: db}
+}
+
+func (r *calendarRepository) GetFeed(feedType string, ownerID, createdBy int) (entities.CalendarFeed, error) {
+	var feed entities.CalendarFeed
+	err := r.db.QueryRow(`
+		SELECT token, feed_type, owner_id FROM calendar_feed_tokens
+		WHERE feed_type = $1 AND owner_id = $2 AND created_by = $3`, feedType, ownerID, createdBy,
+	).Scan(&feed.Token, &feed.Type, &feed.OwnerID)
+	return feed, err
+}
+
+func (r *calendarRepository) GetFeedByToken(token string) (entities.CalendarFeed, error) {
+	var feed entities.CalendarFeed
+	err := r.db.QueryRow(`SELECT token, feed_type, owner_id FROM calendar_feed_tokens WHERE token = $1`, token).
+		Scan(&feed.Token, &feed.Type, &feed.OwnerID)
+	return feed, err
+}
+
+func (r *calendarRepository) CreateFeed(feed entities.CalendarFeed, createdBy int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO calendar_feed_tokens (token, feed_type, owner_id, created_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())`, feed.Token, feed.Type, feed.OwnerID, createdBy)
+	return err
+}
+
+// Query dasar event: satu baris reservation_details = satu VEVENT
+const calendarEventQuery = `
+	SELECT rd.id, rd.reservation_id, rd.room_name, COALESCE(res.contact_company, ''), COALESCE(res.note, ''),
+		CASE WHEN rd.cancelled_at IS NOT NULL THEN 'cancel' ELSE res.status_reservation::text END, rd.sequence, rd.start_at, rd.end_at,
+		COALESCE(rd.created_at, NOW()), COALESCE(rd.updated_at, rd.created_at, NOW())
+	FROM reservation_details rd
+	JOIN reservations res ON rd.reservation_id = res.id
+`
+
+func (r *calendarRepository) GetEventsByReservationID(reservationID int) ([]entities.CalendarEvent, error) {
+	return r.queryEvents(calendarEventQuery+` WHERE rd.reservation_id = $1 ORDER BY rd.start_at ASC`, reservationID)
+}
+
+// GetUserEvents: reservasi user yang belum selesai, termasuk yang cancel
+// agar kalender subscriber ikut menghapus event-nya
+func (r *calendarRepository) GetUserEvents(userID int, from time.Time) ([]entities.CalendarEvent, error) {
+	return r.queryEvents(calendarEventQuery+` WHERE res.user_id = $1 AND rd.end_at >= $2 ORDER BY rd.start_at ASC`, userID, from)
+}
+
+func (r *calendarRepository) GetRoomEvents(roomID int, from time.Time) ([]entities.CalendarEvent, error) {
+	return r.queryEvents(calendarEventQuery+` WHERE rd.room_id = $1 AND rd.end_at >= $2 ORDER BY rd.start_at ASC`, roomID, from)
+}
+
+func (r *calendarRepository) queryEvents(query string, args ...interface{}) ([]entities.CalendarEvent, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []entities.CalendarEvent{}
+	for rows.Next() {
+		var e entities.CalendarEvent
+		if err := rows.Scan(&e.DetailID, &e.ReservationID, &e.RoomName, &e.Company, &e.Note,
+			&e.Status, &e.Sequence, &e.StartAt, &e.EndAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
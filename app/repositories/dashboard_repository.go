@@ -36,21 +36,24 @@ func (r *dashboardRepository) GetDashboardData(startDate, endDate time.Time) (en
 	// B. FILTER (Tanggal & Status Paid)
 	// Filter ini hanya akan ditempelkan pada tabel RESERVASI, bukan pada tabel ROOMS
 
-	filterConditions := " WHERE res.status_reservation = 'paid' "
+	dateFilter := ""
 	var args []interface{}
 	argIdx := 1
 
 	if !startDate.IsZero() {
-		filterConditions += fmt.Sprintf(" AND DATE(rd.start_at) >= $%d", argIdx)
+		dateFilter += fmt.Sprintf(" AND DATE(rd.start_at) >= $%d", argIdx)
 		args = append(args, startDate)
 		argIdx++
 	}
 	if !endDate.IsZero() {
-		filterConditions += fmt.Sprintf(" AND DATE(rd.end_at) <= $%d", argIdx)
+		dateFilter += fmt.Sprintf(" AND DATE(rd.end_at) <= $%d", argIdx)
 		args = append(args, endDate)
 		argIdx++
 	}
 
+	// Detail yang dicancel sebagian tidak dihitung
+	filterConditions := " WHERE res.status_reservation = 'paid' AND rd.cancelled_at IS NULL " + dateFilter
+
 	// C. HITUNG TOTAL STATS (Visitor, Reservation, Omzet)
 	// ------------------------------------------
 	// INNER JOIN karena menghitung yang ada transaksinya
@@ -68,6 +71,59 @@ func (r *dashboardRepository) GetDashboardData(startDate, endDate time.Time) (en
 		return result, err
 	}
 
+	// Biaya cancel (cancellation policy) ikut dihitung sebagai omzet, termasuk dari reservasi
+	// yang sudah cancel, tapi hanya yang sudah pernah dibayar (biaya dipotong dari pembayaran).
+	// Biaya dari reservasi booked yang dicancel tidak pernah tertagih.
+	feeQuery := `
+		SELECT COALESCE(SUM(res.cancellation_fee), 0)
+		FROM reservations res
+		WHERE res.cancellation_fee > 0
+		AND (res.paid_at IS NOT NULL OR EXISTS (
+			SELECT 1 FROM reservation_status_histories sh WHERE sh.reservation_id = res.id AND sh.to_status = 'paid'
+		))
+		AND EXISTS (
+			SELECT 1 FROM reservation_details rd WHERE rd.reservation_id = res.id ` + dateFilter + `
+		)`
+
+	err = r.db.QueryRow(feeQuery, args...).Scan(&result.TotalCancellationFee)
+	if err != nil {
+		return result, err
+	}
+	result.TotalOmzet += result.TotalCancellationFee
+
+	// Potongan promo code / company agreement dari reservasi paid.
+	// res.total sudah net setelah diskon, jadi ini hanya informasi (tidak dikurangkan lagi dari omzet)
+	discountQuery := `
+		SELECT COALESCE(SUM(res.discount_amount), 0)
+		FROM reservations res
+		WHERE res.status_reservation = 'paid' AND res.discount_amount > 0 AND EXISTS (
+			SELECT 1 FROM reservation_details rd WHERE rd.reservation_id = res.id AND rd.cancelled_at IS NULL ` + dateFilter + `
+		)`
+
+	err = r.db.QueryRow(discountQuery, args...).Scan(&result.TotalDiscount)
+	if err != nil {
+		return result, err
+	}
+
+	// Jumlah room yang ditandai no-show (tidak check-in)
+	noShowQuery := `SELECT COUNT(*) FROM reservation_details rd WHERE rd.no_show_at IS NOT NULL ` + dateFilter
+	err = r.db.QueryRow(noShowQuery, args...).Scan(&result.TotalNoShow)
+	if err != nil {
+		return result, err
+	}
+
+	// Utilisasi kursi: rata-rata peserta / kapasitas room per baris reservation_details
+	seatQuery := `
+		SELECT COALESCE(AVG(rd.total_participants::float / NULLIF(rm.capacity, 0)) * 100, 0)
+		FROM reservations res
+		JOIN reservation_details rd ON res.id = rd.reservation_id
+		JOIN rooms rm ON rm.id = rd.room_id
+	` + filterConditions
+	err = r.db.QueryRow(seatQuery, args...).Scan(&result.AverageSeatUtilization)
+	if err != nil {
+		return result, err
+	}
+
 	// D. HITUNG ROOM STATS (Per Ruangan)
 	// filter dulu reservasinya di dalam subquery (FilteredRes),
 	// baru LEFT JOIN ke tabel rooms
@@ -82,17 +138,19 @@ func (r *dashboardRepository) GetDashboardData(startDate, endDate time.Time) (en
 			CASE 
 				WHEN $` + strconv.Itoa(argIdx) + ` = 0 THEN 0
 				ELSE (COUNT(DISTINCT FilteredRes.reservation_id)::float / $` + strconv.Itoa(argIdx) + `::float) * 100
-			END AS percentage_of_usage
+			END AS percentage_of_usage,
+
+			COALESCE(AVG(FilteredRes.total_participants::float / NULLIF(r.capacity, 0)) * 100, 0) AS seat_utilization
 
 		FROM rooms r
 		LEFT JOIN (
-			SELECT res.id as reservation_id, res.total, rd.room_id
+			SELECT res.id as reservation_id, res.total, rd.room_id, rd.total_participants
 			FROM reservations res
 			JOIN reservation_details rd ON res.id = rd.reservation_id
 			` + filterConditions + `
 		) FilteredRes ON r.id = FilteredRes.room_id
 		
-		GROUP BY r.id, r.name
+		GROUP BY r.id, r.name, r.capacity
 		ORDER BY omzet DESC
 	`
 
@@ -107,7 +165,7 @@ func (r *dashboardRepository) GetDashboardData(startDate, endDate time.Time) (en
 
 	for rows.Next() {
 		var room entities.DashboardRoom
-		if err := rows.Scan(&room.ID, &room.Name, &room.Omzet, &room.PercentageOfUsage); err != nil {
+		if err := rows.Scan(&room.ID, &room.Name, &room.Omzet, &room.PercentageOfUsage, &room.SeatUtilization); err != nil {
 			return result, err
 		}
 		result.Rooms = append(result.Rooms, room)
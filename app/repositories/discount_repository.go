@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"BE-E-Meeting/app/entities"
+
+	"github.com/lib/pq"
+)
+
+type DiscountRepository interface {
+	GetPromoCodes() ([]entities.PromoCode, error)
+	GetPromoCodeByCode(code string) (entities.PromoCode, error)
+	CreatePromoCode(promo entities.PromoCode) (entities.PromoCode, error)
+	UpdatePromoCode(promo entities.PromoCode) (entities.PromoCode, error)
+	DeletePromoCode(id int) error
+	PromoCodeUsage(id, userID int) (int, int, error)
+	GetCompanyAgreements() ([]entities.CompanyAgreement, error)
+	GetCompanyAgreementByCode(code string) (entities.CompanyAgreement, error)
+	CreateCompanyAgreement(agreement entities.CompanyAgreement) (entities.CompanyAgreement, error)
+	UpdateCompanyAgreement(agreement entities.CompanyAgreement) (entities.CompanyAgreement, error)
+	DeleteCompanyAgreement(id int) error
+	CompanyAgreementUsage(id int) (int, error)
+}
+
+type discountRepository struct {
+	db *sql.DB
+}
+
+func NewDiscountRepository(db *sql.DB) DiscountRepository {
+	return &discountRepository{db: db}
+}
+
+// Reservasi yang dihitung sebagai pemakaian diskon: belum cancel/refunded dan bukan hold yang expired
+const discountUsageFilter = ` status_reservation NOT IN ('cancel', 'refunded')
+	AND (status_reservation <> 'hold' OR hold_expires_at > NOW()) `
+
+// Satu series recurring dihitung satu pemakaian, bukan per occurrence
+const discountUsageCount = `COUNT(DISTINCT COALESCE(-series_id, id))`
+
+const discountTermsColumns = `discount_type, discount_value, valid_from, valid_until, usage_limit, room_types::text[], snack_ids`
+
+// scanDiscountTerms: snack_ids discan ke snackIDs lalu disalin dengan setSnackIDs setelah Scan
+func scanDiscountTerms(t *entities.DiscountTerms, snackIDs *pq.Int64Array) []interface{} {
+	return []interface{}{&t.DiscountType, &t.DiscountValue, &t.ValidFrom, &t.ValidUntil, &t.UsageLimit, (*pq.StringArray)(&t.RoomTypes), snackIDs}
+}
+
+func setSnackIDs(t *entities.DiscountTerms, snackIDs pq.Int64Array) {
+	t.SnackIDs = make([]int, 0, len(snackIDs))
+	for _, id := range snackIDs {
+		t.SnackIDs = append(t.SnackIDs, int(id))
+	}
+}
+
+func discountTermsArgs(t entities.DiscountTerms) []interface{} {
+	roomTypes := t.RoomTypes
+	if roomTypes == nil {
+		roomTypes = []string{}
+	}
+	snackIDs := make(pq.Int64Array, 0, len(t.SnackIDs))
+	for _, id := range t.SnackIDs {
+		snackIDs = append(snackIDs, int64(id))
+	}
+	return []interface{}{t.DiscountType, t.DiscountValue, t.ValidFrom, t.ValidUntil, t.UsageLimit, pq.StringArray(roomTypes), snackIDs}
+}
+
+// duplicateCodeError: unique index code dilanggar
+func duplicateCodeError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return &entities.BadRequestError{Message: "code already exists"}
+	}
+	return err
+}
+
+// --- PROMO CODE ---
+
+const promoCodeColumns = `id, code, name, ` + discountTermsColumns + `, per_user_limit,
+	(SELECT ` + discountUsageCount + ` FROM reservations WHERE promo_code_id = promo_codes.id AND` + discountUsageFilter + `),
+	created_at, COALESCE(updated_at, created_at)`
+
+func scanPromoCode(row interface{ Scan(...interface{}) error }) (entities.PromoCode, error) {
+	var p entities.PromoCode
+	var snackIDs pq.Int64Array
+	dest := append([]interface{}{&p.ID, &p.Code, &p.Name}, scanDiscountTerms(&p.DiscountTerms, &snackIDs)...)
+	dest = append(dest, &p.PerUserLimit, &p.UsedCount, &p.CreatedAt, &p.UpdatedAt)
+	err := row.Scan(dest...)
+	setSnackIDs(&p.DiscountTerms, snackIDs)
+	return p, err
+}
+
+func (r *discountRepository) GetPromoCodes() ([]entities.PromoCode, error) {
+	rows, err := r.db.Query(`SELECT ` + promoCodeColumns + ` FROM promo_codes ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promos := []entities.PromoCode{}
+	for rows.Next() {
+		p, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		promos = append(promos, p)
+	}
+	return promos, nil
+}
+
+// GetPromoCodeByCode: pencarian code tidak membedakan huruf besar / kecil
+func (r *discountRepository) GetPromoCodeByCode(code string) (entities.PromoCode, error) {
+	return scanPromoCode(r.db.QueryRow(`SELECT `+promoCodeColumns+` FROM promo_codes WHERE UPPER(code) = UPPER($1)`, strings.TrimSpace(code)))
+}
+
+func (r *discountRepository) CreatePromoCode(promo entities.PromoCode) (entities.PromoCode, error) {
+	args := append([]interface{}{promo.Code, promo.Name}, discountTermsArgs(promo.DiscountTerms)...)
+	args = append(args, promo.PerUserLimit)
+	saved, err := scanPromoCode(r.db.QueryRow(`
+		INSERT INTO promo_codes (code, name, discount_type, discount_value, valid_from, valid_until, usage_limit, room_types, snack_ids, per_user_limit, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::room_type[], $9, $10, NOW())
+		RETURNING `+promoCodeColumns, args...))
+	return saved, duplicateCodeError(err)
+}
+
+func (r *discountRepository) UpdatePromoCode(promo entities.PromoCode) (entities.PromoCode, error) {
+	args := append([]interface{}{promo.Code, promo.Name}, discountTermsArgs(promo.DiscountTerms)...)
+	args = append(args, promo.PerUserLimit, promo.ID)
+	saved, err := scanPromoCode(r.db.QueryRow(`
+		UPDATE promo_codes
+		SET code = $1, name = $2, discount_type = $3, discount_value = $4, valid_from = $5, valid_until = $6,
+			usage_limit = $7, room_types = $8::room_type[], snack_ids = $9, per_user_limit = $10, updated_at = NOW()
+		WHERE id = $11
+		RETURNING `+promoCodeColumns, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return saved, fmt.Errorf("promo code %w", entities.ErrNotFound)
+	}
+	return saved, duplicateCodeError(err)
+}
+
+func (r *discountRepository) DeletePromoCode(id int) error {
+	return deleteByID(r.db, "promo_codes", id, "promo code")
+}
+
+// PromoCodeUsage: jumlah pemakaian promo code (total dan oleh user tersebut)
+func (r *discountRepository) PromoCodeUsage(id, userID int) (int, int, error) {
+	var total, byUser int
+	err := r.db.QueryRow(`
+		SELECT `+discountUsageCount+`, `+discountUsageCount+` FILTER (WHERE user_id = $2) FROM reservations
+		WHERE promo_code_id = $1 AND`+discountUsageFilter, id, userID).Scan(&total, &byUser)
+	return total, byUser, err
+}
+
+// --- COMPANY AGREEMENT ---
+
+const companyAgreementColumns = `id, code, company_name, ` + discountTermsColumns + `,
+	(SELECT ` + discountUsageCount + ` FROM reservations WHERE company_agreement_id = company_agreements.id AND` + discountUsageFilter + `),
+	created_at, COALESCE(updated_at, created_at)`
+
+func scanCompanyAgreement(row interface{ Scan(...interface{}) error }) (entities.CompanyAgreement, error) {
+	var a entities.CompanyAgreement
+	var snackIDs pq.Int64Array
+	dest := append([]interface{}{&a.ID, &a.Code, &a.CompanyName}, scanDiscountTerms(&a.DiscountTerms, &snackIDs)...)
+	dest = append(dest, &a.UsedCount, &a.CreatedAt, &a.UpdatedAt)
+	err := row.Scan(dest...)
+	setSnackIDs(&a.DiscountTerms, snackIDs)
+	return a, err
+}
+
+func (r *discountRepository) GetCompanyAgreements() ([]entities.CompanyAgreement, error) {
+	rows, err := r.db.Query(`SELECT ` + companyAgreementColumns + ` FROM company_agreements ORDER BY company_name, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	agreements := []entities.CompanyAgreement{}
+	for rows.Next() {
+		a, err := scanCompanyAgreement(rows)
+		if err != nil {
+			return nil, err
+		}
+		agreements = append(agreements, a)
+	}
+	return agreements, nil
+}
+
+func (r *discountRepository) GetCompanyAgreementByCode(code string) (entities.CompanyAgreement, error) {
+	return scanCompanyAgreement(r.db.QueryRow(`SELECT `+companyAgreementColumns+` FROM company_agreements WHERE UPPER(code) = UPPER($1)`, strings.TrimSpace(code)))
+}
+
+func (r *discountRepository) CreateCompanyAgreement(agreement entities.CompanyAgreement) (entities.CompanyAgreement, error) {
+	args := append([]interface{}{agreement.Code, agreement.CompanyName}, discountTermsArgs(agreement.DiscountTerms)...)
+	saved, err := scanCompanyAgreement(r.db.QueryRow(`
+		INSERT INTO company_agreements (code, company_name, discount_type, discount_value, valid_from, valid_until, usage_limit, room_types, snack_ids, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::room_type[], $9, NOW())
+		RETURNING `+companyAgreementColumns, args...))
+	return saved, duplicateCodeError(err)
+}
+
+func (r *discountRepository) UpdateCompanyAgreement(agreement entities.CompanyAgreement) (entities.CompanyAgreement, error) {
+	args := append([]interface{}{agreement.Code, agreement.CompanyName}, discountTermsArgs(agreement.DiscountTerms)...)
+	args = append(args, agreement.ID)
+	saved, err := scanCompanyAgreement(r.db.QueryRow(`
+		UPDATE company_agreements
+		SET code = $1, company_name = $2, discount_type = $3, discount_value = $4, valid_from = $5, valid_until = $6,
+			usage_limit = $7, room_types = $8::room_type[], snack_ids = $9, updated_at = NOW()
+		WHERE id = $10
+		RETURNING `+companyAgreementColumns, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return saved, fmt.Errorf("company agreement %w", entities.ErrNotFound)
+	}
+	return saved, duplicateCodeError(err)
+}
+
+func (r *discountRepository) DeleteCompanyAgreement(id int) error {
+	return deleteByID(r.db, "company_agreements", id, "company agreement")
+}
+
+func (r *discountRepository) CompanyAgreementUsage(id int) (int, error) {
+	var total int
+	err := r.db.QueryRow(`SELECT `+discountUsageCount+` FROM reservations WHERE company_agreement_id = $1 AND`+discountUsageFilter, id).Scan(&total)
+	return total, err
+}
+
+func deleteByID(db *sql.DB, table string, id int, resource string) error {
+	res, err := db.Exec(fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, table), id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s %w", resource, entities.ErrNotFound)
+	}
+	return nil
+}
+
+// checkDiscountUsage mengecek ulang batas pemakaian diskon di dalam transaksi insert.
+// Row diskon di-lock agar dua reservasi bersamaan tidak melewati batas.
+func checkDiscountUsage(tx *sql.Tx, res entities.ReservationData) error {
+	if res.PromoCodeID > 0 {
+		var limit, perUser int
+		err := tx.QueryRow(`SELECT usage_limit, per_user_limit FROM promo_codes WHERE id = $1 FOR UPDATE`, res.PromoCodeID).Scan(&limit, &perUser)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("promo code %w", entities.ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		var total, byUser int
+		err = tx.QueryRow(`
+			SELECT `+discountUsageCount+`, `+discountUsageCount+` FILTER (WHERE user_id = $2) FROM reservations
+			WHERE promo_code_id = $1 AND`+discountUsageFilter, res.PromoCodeID, res.UserID).Scan(&total, &byUser)
+		if err != nil {
+			return err
+		}
+		if limit > 0 && total >= limit {
+			return &entities.BadRequestError{Message: "promo code usage limit has been reached"}
+		}
+		if perUser > 0 && byUser >= perUser {
+			return &entities.BadRequestError{Message: "you have reached the usage limit of this promo code"}
+		}
+	}
+
+	if res.CompanyAgreementID > 0 {
+		var limit int
+		err := tx.QueryRow(`SELECT usage_limit FROM company_agreements WHERE id = $1 FOR UPDATE`, res.CompanyAgreementID).Scan(&limit)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("company agreement %w", entities.ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		var total int
+		err = tx.QueryRow(`SELECT `+discountUsageCount+` FROM reservations WHERE company_agreement_id = $1 AND`+discountUsageFilter, res.CompanyAgreementID).Scan(&total)
+		if err != nil {
+			return err
+		}
+		if limit > 0 && total >= limit {
+			return &entities.BadRequestError{Message: "company agreement usage limit has been reached"}
+		}
+	}
+	return nil
+}
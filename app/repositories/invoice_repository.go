@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"BE-E-Meeting/app/entities"
+)
+
+type InvoiceRepository interface {
+	IssueNumber(reservationID int, kind string) (entities.InvoiceNumber, error)
+}
+
+type invoiceRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+// invoicePrefixes: INV/2026/000001, RCP/2026/000001
+var invoicePrefixes = map[string]string{"invoice": "INV", "receipt": "RCP"}
+
+// IssueNumber mengembalikan nomor yang sudah ada, atau menerbitkan nomor berikutnya.
+// Counter per jenis & tahun dikunci sampai commit, jadi nomor tidak loncat dan tidak dobel.
+func (r *invoiceRepository) IssueNumber(reservationID int, kind string) (entities.InvoiceNumber, error) {
+	number := entities.InvoiceNumber{ReservationID: reservationID, Kind: kind}
+	prefix, ok := invoicePrefixes[kind]
+	if !ok {
+		return number, fmt.Errorf("unknown invoice type %q", kind)
+	}
+
+	existing := `SELECT invoice_number, issued_at FROM reservation_invoices WHERE reservation_id = $1 AND kind = $2`
+	err := r.db.QueryRow(existing, reservationID, kind).Scan(&number.Number, &number.IssuedAt)
+	if err == nil || !errors.Is(err, sql.ErrNoRows) {
+		return number, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return number, err
+	}
+	defer tx.Rollback()
+
+	var year, sequence int
+	err = tx.QueryRow(`
+		INSERT INTO invoice_sequences (kind, year, last_number)
+		VALUES ($1, EXTRACT(YEAR FROM NOW())::int, 1)
+		ON CONFLICT (kind, year) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING year, last_number`, kind).Scan(&year, &sequence)
+	if err != nil {
+		return number, err
+	}
+
+	number.Number = strings.Join([]string{prefix, fmt.Sprint(year), fmt.Sprintf("%06d", sequence)}, "/")
+	err = tx.QueryRow(`
+		INSERT INTO reservation_invoices (reservation_id, kind, invoice_number, issued_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (reservation_id, kind) DO NOTHING
+		RETURNING issued_at`, reservationID, kind, number.Number).Scan(&number.IssuedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Request paralel sudah menerbitkan nomor, counter di-rollback
+		tx.Rollback()
+		err = r.db.QueryRow(existing, reservationID, kind).Scan(&number.Number, &number.IssuedAt)
+		return number, err
+	}
+	if err != nil {
+		return number, err
+	}
+	return number, tx.Commit()
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"BE-E-Meeting/app/entities"
+)
+
+type OpeningHoursRepository interface {
+	GetOperatingHours(roomID int) ([]entities.OperatingHour, error)
+	GetEffectiveOperatingHour(roomID, weekday int) (entities.OperatingHour, error)
+	ReplaceOperatingHours(roomID int, hours []entities.OperatingHour) error
+	GetBlackoutDates(roomID int, startDate, endDate string) ([]entities.BlackoutDate, error)
+	CreateBlackoutDate(b entities.BlackoutDate) (entities.BlackoutDate, error)
+	DeleteBlackoutDate(id int) error
+}
+
+type openingHoursRepository struct {
+	db *sql.DB
+}
+
+func NewOpeningHoursRepository(db *sql.DB) OpeningHoursRepository {
+	return &openingHoursRepository{db: db}
+}
+
+const operatingHourColumns = `COALESCE(room_id, 0), weekday, to_char(open_time, 'HH24:MI'), to_char(close_time, 'HH24:MI'), is_closed`
+
+func scanOperatingHour(row interface{ Scan(...interface{}) error }) (entities.OperatingHour, error) {
+	var h entities.OperatingHour
+	err := row.Scan(&h.RoomID, &h.Weekday, &h.OpenTime, &h.CloseTime, &h.Closed)
+	return h, err
+}
+
+// GetOperatingHours: jam buka yang diatur untuk room tersebut saja (roomID 0 = global)
+func (r *openingHoursRepository) GetOperatingHours(roomID int) ([]entities.OperatingHour, error) {
+	rows, err := r.db.Query(`SELECT `+operatingHourColumns+` FROM room_operating_hours
+		WHERE room_id IS NOT DISTINCT FROM $1 ORDER BY weekday`, nullableID(roomID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := []entities.OperatingHour{}
+	for rows.Next() {
+		h, err := scanOperatingHour(rows)
+		if err != nil {
+			return nil, err
+		}
+		hours = append(hours, h)
+	}
+	return hours, nil
+}
+
+// GetEffectiveOperatingHour mengambil jam buka room di hari tersebut, fallback ke default global.
+// sql.ErrNoRows = tidak ada pengaturan (buka 24 jam).
+func (r *openingHoursRepository) GetEffectiveOperatingHour(roomID, weekday int) (entities.OperatingHour, error) {
+	return scanOperatingHour(r.db.QueryRow(`
+		SELECT `+operatingHourColumns+` FROM room_operating_hours
+		WHERE weekday = $2 AND (room_id = $1 OR room_id IS NULL)
+		ORDER BY room_id NULLS LAST
+		LIMIT 1`, roomID, weekday))
+}
+
+// ReplaceOperatingHours mengganti seluruh jam buka mingguan room (roomID 0 = global)
+func (r *openingHoursRepository) ReplaceOperatingHours(roomID int, hours []entities.OperatingHour) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM room_operating_hours WHERE room_id IS NOT DISTINCT FROM $1`, nullableID(roomID)); err != nil {
+		return err
+	}
+	for _, h := range hours {
+		_, err := tx.Exec(`
+			INSERT INTO room_operating_hours (room_id, weekday, open_time, close_time, is_closed, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW())`,
+			nullableID(roomID), h.Weekday, h.OpenTime, h.CloseTime, h.Closed)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const blackoutDateColumns = `id, COALESCE(room_id, 0), to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), name, created_at`
+
+func scanBlackoutDate(row interface{ Scan(...interface{}) error }) (entities.BlackoutDate, error) {
+	var b entities.BlackoutDate
+	err := row.Scan(&b.ID, &b.RoomID, &b.StartDate, &b.EndDate, &b.Name, &b.CreatedAt)
+	return b, err
+}
+
+// GetBlackoutDates: tanggal tutup yang bersinggungan dengan rentang tanggal (opsional).
+// roomID > 0 = blackout room tersebut + blackout semua room, roomID 0 = semua data.
+func (r *openingHoursRepository) GetBlackoutDates(roomID int, startDate, endDate string) ([]entities.BlackoutDate, error) {
+	query := `SELECT ` + blackoutDateColumns + ` FROM blackout_dates WHERE 1=1`
+	args := []interface{}{}
+	argIdx := 1
+
+	if roomID > 0 {
+		query += fmt.Sprintf(" AND (room_id = $%d OR room_id IS NULL)", argIdx)
+		args = append(args, roomID)
+		argIdx++
+	}
+	if startDate != "" {
+		query += fmt.Sprintf(" AND end_date >= $%d", argIdx)
+		args = append(args, startDate)
+		argIdx++
+	}
+	if endDate != "" {
+		query += fmt.Sprintf(" AND start_date <= $%d", argIdx)
+		args = append(args, endDate)
+		argIdx++
+	}
+	query += " ORDER BY start_date ASC, id ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dates := []entities.BlackoutDate{}
+	for rows.Next() {
+		b, err := scanBlackoutDate(rows)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, b)
+	}
+	return dates, nil
+}
+
+func (r *openingHoursRepository) CreateBlackoutDate(b entities.BlackoutDate) (entities.BlackoutDate, error) {
+	return scanBlackoutDate(r.db.QueryRow(`
+		INSERT INTO blackout_dates (room_id, start_date, end_date, name, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING `+blackoutDateColumns,
+		nullableID(b.RoomID), b.StartDate, b.EndDate, b.Name))
+}
+
+func (r *openingHoursRepository) DeleteBlackoutDate(id int) error {
+	res, err := r.db.Exec(`DELETE FROM blackout_dates WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("blackout date %w", entities.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"BE-E-Meeting/app/entities"
+)
+
+type PaymentRepository interface {
+	GetPendingPayment(reservationID int) (entities.Payment, error)
+	GetPayments(reservationID int) ([]entities.Payment, error)
+	CreatePayment(payment entities.Payment) (entities.Payment, error)
+	ExpirePayment(id int, reason string) error
+	ApplyWebhook(event entities.PaymentWebhookEvent) (entities.PaymentWebhookResult, error)
+}
+
+type paymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+const paymentColumns = `id, reservation_id, provider, reference, COALESCE(provider_reference, ''), amount, status,
+	COALESCE(payment_url, ''), expires_at, COALESCE(paid_amount, 0), paid_at, COALESCE(failure_reason, ''), created_at`
+
+func scanPayment(row interface{ Scan(...interface{}) error }) (entities.Payment, error) {
+	var p entities.Payment
+	var expiresAt, paidAt sql.NullTime
+	err := row.Scan(&p.ID, &p.ReservationID, &p.Provider, &p.Reference, &p.ProviderReference, &p.Amount, &p.Status,
+		&p.PaymentURL, &expiresAt, &p.PaidAmount, &paidAt, &p.FailureReason, &p.CreatedAt)
+	if expiresAt.Valid {
+		p.ExpiresAt = &expiresAt.Time
+	}
+	if paidAt.Valid {
+		p.PaidAt = &paidAt.Time
+	}
+	return p, err
+}
+
+// GetPendingPayment: intent yang masih menunggu pembayaran (maksimal satu per reservasi)
+func (r *paymentRepository) GetPendingPayment(reservationID int) (entities.Payment, error) {
+	return scanPayment(r.db.QueryRow(`SELECT `+paymentColumns+` FROM reservation_payments
+		WHERE reservation_id = $1 AND status = 'pending'`, reservationID))
+}
+
+func (r *paymentRepository) GetPayments(reservationID int) ([]entities.Payment, error) {
+	rows, err := r.db.Query(`SELECT `+paymentColumns+` FROM reservation_payments
+		WHERE reservation_id = $1 ORDER BY created_at DESC, id DESC`, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []entities.Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, nil
+}
+
+// CreatePayment menyimpan intent baru. Jika request paralel sudah membuat intent pending,
+// intent tersebut yang dikembalikan.
+func (r *paymentRepository) CreatePayment(payment entities.Payment) (entities.Payment, error) {
+	var expiresAt interface{}
+	if payment.ExpiresAt != nil {
+		expiresAt = *payment.ExpiresAt
+	}
+	created, err := scanPayment(r.db.QueryRow(`
+		INSERT INTO reservation_payments (reservation_id, provider, reference, provider_reference, amount, status, payment_url, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, 'pending', $6, $7, NOW())
+		ON CONFLICT (reservation_id) WHERE status = 'pending' DO NOTHING
+		RETURNING `+paymentColumns,
+		payment.ReservationID, payment.Provider, payment.Reference, nullableString(payment.ProviderReference),
+		payment.Amount, payment.PaymentURL, expiresAt))
+	if errors.Is(err, sql.ErrNoRows) {
+		return r.GetPendingPayment(payment.ReservationID)
+	}
+	return created, err
+}
+
+// ExpirePayment: intent pending yang sudah lewat / nominalnya tidak sesuai lagi dengan total reservasi
+func (r *paymentRepository) ExpirePayment(id int, reason string) error {
+	_, err := r.db.Exec(`
+		UPDATE reservation_payments SET status = 'expired', failure_reason = $1, updated_at = NOW()
+		WHERE id = $2 AND status = 'pending'`, reason, id)
+	return err
+}
+
+// ApplyWebhook memproses callback provider dalam satu transaksi. Event yang sama (provider + eventId)
+// hanya diproses sekali; pembayaran yang sudah paid tidak bisa berubah lagi.
+// Reservasi booked menjadi paid jika nominal yang dibayar menutup total reservasi.
+func (r *paymentRepository) ApplyWebhook(event entities.PaymentWebhookEvent) (entities.PaymentWebhookResult, error) {
+	result := entities.PaymentWebhookResult{Reference: event.Reference}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	payment, err := scanPayment(tx.QueryRow(`SELECT `+paymentColumns+` FROM reservation_payments
+		WHERE reference = $1 AND provider = $2 FOR UPDATE`, event.Reference, event.Provider))
+	if errors.Is(err, sql.ErrNoRows) {
+		return result, fmt.Errorf("payment %w", entities.ErrNotFound)
+	}
+	if err != nil {
+		return result, err
+	}
+	result.ReservationID = payment.ReservationID
+	result.PaymentStatus = payment.Status
+
+	var eventID int
+	err = tx.QueryRow(`
+		INSERT INTO payment_webhook_events (provider, event_id, reference, status, amount, payload, received_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (provider, event_id) DO NOTHING
+		RETURNING id`, event.Provider, event.EventID, event.Reference, event.Status, event.Amount, event.Payload).Scan(&eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		result.Duplicate = true
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	switch {
+	case payment.Status == "paid":
+		// Sudah lunas, event lain (mis. expired yang terlambat) diabaikan
+		result.Duplicate = true
+	case event.Status == "paid" && event.Amount+0.005 < payment.Amount:
+		_, err = tx.Exec(`
+			UPDATE reservation_payments SET status = 'failed', paid_amount = $1, failure_reason = $2, updated_at = NOW()
+			WHERE id = $3`, event.Amount, fmt.Sprintf("paid amount %.2f is less than %.2f", event.Amount, payment.Amount), payment.ID)
+		result.PaymentStatus = "failed"
+	case event.Status == "paid":
+		// Pembayaran yang masuk setelah intent expired / failed tetap dicatat
+		_, err = tx.Exec(`
+			UPDATE reservation_payments SET status = 'paid', paid_amount = $1, paid_at = NOW(), failure_reason = NULL,
+				provider_reference = COALESCE($2, provider_reference), updated_at = NOW()
+			WHERE id = $3`, event.Amount, nullableString(event.ProviderReference), payment.ID)
+		if err != nil {
+			return result, err
+		}
+		result.PaymentStatus = "paid"
+		result.ReservationPaid, err = markReservationPaid(tx, payment, event.Amount)
+	case payment.Status == "pending":
+		_, err = tx.Exec(`
+			UPDATE reservation_payments SET status = $1, failure_reason = $2, updated_at = NOW()
+			WHERE id = $3`, event.Status, nullableString(event.Reason), payment.ID)
+		result.PaymentStatus = event.Status
+	}
+	if err != nil {
+		return result, err
+	}
+	return result, tx.Commit()
+}
+
+// markReservationPaid: booked -> paid dengan referensi & nominal pembayaran, dicatat di status history
+// tanpa changed_by (oleh sistem). Reservasi yang sudah tidak booked atau totalnya naik tidak diubah.
+func markReservationPaid(tx *sql.Tx, payment entities.Payment, amount float64) (bool, error) {
+	res, err := tx.Exec(`
+		UPDATE reservations SET status_reservation = 'paid', payment_reference = $1, paid_amount = $2, paid_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND status_reservation = 'booked' AND total <= $2`, payment.Reference, amount, payment.ReservationID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	_, err = tx.Exec(`UPDATE reservation_details SET sequence = sequence + 1, updated_at = NOW() WHERE reservation_id = $1`, payment.ReservationID)
+	if err != nil {
+		return false, err
+	}
+	reason := fmt.Sprintf("payment %s via %s", payment.Reference, payment.Provider)
+	if err := insertStatusHistory(tx, payment.ReservationID, 0, "booked", "paid", reason); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"BE-E-Meeting/app/entities"
+)
+
+type PolicyRepository interface {
+	GetCancellationPolicies() ([]entities.CancellationPolicy, error)
+	GetCancellationPolicy(roomType string) (entities.CancellationPolicy, error)
+	UpsertCancellationPolicy(policy entities.CancellationPolicy) (entities.CancellationPolicy, error)
+	GetBookingRules() ([]entities.BookingRule, error)
+	GetBookingRule(roomType string) (entities.BookingRule, error)
+	UpsertBookingRule(rule entities.BookingRule) (entities.BookingRule, error)
+}
+
+type policyRepository struct {
+	db *sql.DB
+}
+
+func NewPolicyRepository(db *sql.DB) PolicyRepository {
+	return &policyRepository{db: db}
+}
+
+const cancellationPolicyColumns = `id, COALESCE(room_type::text, ''), free_cancel_hours, late_fee_percent, no_show_fee_percent, COALESCE(updated_at, created_at)`
+
+func scanCancellationPolicy(row interface{ Scan(...interface{}) error }) (entities.CancellationPolicy, error) {
+	var p entities.CancellationPolicy
+	err := row.Scan(&p.ID, &p.RoomType, &p.FreeCancelHours, &p.LateFeePercent, &p.NoShowFeePercent, &p.UpdatedAt)
+	return p, err
+}
+
+func (r *policyRepository) GetCancellationPolicies() ([]entities.CancellationPolicy, error) {
+	rows, err := r.db.Query(`SELECT ` + cancellationPolicyColumns + ` FROM cancellation_policies ORDER BY room_type NULLS FIRST`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := []entities.CancellationPolicy{}
+	for rows.Next() {
+		p, err := scanCancellationPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// GetCancellationPolicy mengambil policy room type tersebut, fallback ke policy global
+func (r *policyRepository) GetCancellationPolicy(roomType string) (entities.CancellationPolicy, error) {
+	return scanCancellationPolicy(r.db.QueryRow(`
+		SELECT `+cancellationPolicyColumns+` FROM cancellation_policies
+		WHERE room_type::text = $1 OR room_type IS NULL
+		ORDER BY room_type NULLS LAST
+		LIMIT 1`, roomType))
+}
+
+func (r *policyRepository) UpsertCancellationPolicy(policy entities.CancellationPolicy) (entities.CancellationPolicy, error) {
+	var roomType interface{}
+	if policy.RoomType != "" {
+		roomType = policy.RoomType
+	}
+
+	saved, err := scanCancellationPolicy(r.db.QueryRow(`
+		UPDATE cancellation_policies
+		SET free_cancel_hours = $1, late_fee_percent = $2, no_show_fee_percent = $3, updated_at = NOW()
+		WHERE room_type IS NOT DISTINCT FROM $4::room_type
+		RETURNING `+cancellationPolicyColumns,
+		policy.FreeCancelHours, policy.LateFeePercent, policy.NoShowFeePercent, roomType))
+	if err != sql.ErrNoRows {
+		return saved, err
+	}
+
+	return scanCancellationPolicy(r.db.QueryRow(`
+		INSERT INTO cancellation_policies (room_type, free_cancel_hours, late_fee_percent, no_show_fee_percent, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING `+cancellationPolicyColumns,
+		roomType, policy.FreeCancelHours, policy.LateFeePercent, policy.NoShowFeePercent))
+}
+
+const bookingRuleColumns = `id, COALESCE(room_type::text, ''), min_lead_minutes, max_horizon_days, min_duration_minutes, max_duration_minutes, slot_minutes, capacity_overflow_percent,
+	requires_approval, approval_price_threshold, COALESCE(updated_at, created_at)`
+
+func scanBookingRule(row interface{ Scan(...interface{}) error }) (entities.BookingRule, error) {
+	var b entities.BookingRule
+	err := row.Scan(&b.ID, &b.RoomType, &b.MinLeadMinutes, &b.MaxHorizonDays, &b.MinDurationMinutes, &b.MaxDurationMinutes, &b.SlotMinutes, &b.CapacityOverflowPercent,
+		&b.RequiresApproval, &b.ApprovalPriceThreshold, &b.UpdatedAt)
+	return b, err
+}
+
+func (r *policyRepository) GetBookingRules() ([]entities.BookingRule, error) {
+	rows, err := r.db.Query(`SELECT ` + bookingRuleColumns + ` FROM booking_rules ORDER BY room_type NULLS FIRST`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []entities.BookingRule{}
+	for rows.Next() {
+		b, err := scanBookingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, b)
+	}
+	return rules, nil
+}
+
+// GetBookingRule mengambil aturan room type tersebut, fallback ke aturan global
+func (r *policyRepository) GetBookingRule(roomType string) (entities.BookingRule, error) {
+	return scanBookingRule(r.db.QueryRow(`
+		SELECT `+bookingRuleColumns+` FROM booking_rules
+		WHERE room_type::text = $1 OR room_type IS NULL
+		ORDER BY room_type NULLS LAST
+		LIMIT 1`, roomType))
+}
+
+func (r *policyRepository) UpsertBookingRule(rule entities.BookingRule) (entities.BookingRule, error) {
+	var roomType interface{}
+	if rule.RoomType != "" {
+		roomType = rule.RoomType
+	}
+
+	saved, err := scanBookingRule(r.db.QueryRow(`
+		UPDATE booking_rules
+		SET min_lead_minutes = $1, max_horizon_days = $2, min_duration_minutes = $3, max_duration_minutes = $4,
+			slot_minutes = $5, capacity_overflow_percent = $6, requires_approval = $7, approval_price_threshold = $8, updated_at = NOW()
+		WHERE room_type IS NOT DISTINCT FROM $9::room_type
+		RETURNING `+bookingRuleColumns,
+		rule.MinLeadMinutes, rule.MaxHorizonDays, rule.MinDurationMinutes, rule.MaxDurationMinutes, rule.SlotMinutes,
+		rule.CapacityOverflowPercent, rule.RequiresApproval, rule.ApprovalPriceThreshold, roomType))
+	if err != sql.ErrNoRows {
+		return saved, err
+	}
+
+	return scanBookingRule(r.db.QueryRow(`
+		INSERT INTO booking_rules (room_type, min_lead_minutes, max_horizon_days, min_duration_minutes, max_duration_minutes,
+			slot_minutes, capacity_overflow_percent, requires_approval, approval_price_threshold, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING `+bookingRuleColumns,
+		roomType, rule.MinLeadMinutes, rule.MaxHorizonDays, rule.MinDurationMinutes, rule.MaxDurationMinutes, rule.SlotMinutes,
+		rule.CapacityOverflowPercent, rule.RequiresApproval, rule.ApprovalPriceThreshold))
+}
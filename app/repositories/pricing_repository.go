@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"BE-E-Meeting/app/entities"
+
+	"github.com/lib/pq"
+)
+
+type PricingRepository interface {
+	GetPricingRules() ([]entities.PricingRule, error)
+	GetPricingRulesForRoomType(roomType string) ([]entities.PricingRule, error)
+	CreatePricingRule(rule entities.PricingRule) (entities.PricingRule, error)
+	UpdatePricingRule(rule entities.PricingRule) (entities.PricingRule, error)
+	DeletePricingRule(id int) error
+}
+
+type pricingRepository struct {
+	db *sql.DB
+}
+
+func NewPricingRepository(db *sql.DB) PricingRepository {
+	return &pricingRepository{db: db}
+}
+
+const pricingRuleColumns = `id, name, kind, COALESCE(room_type::text, ''), weekdays,
+	COALESCE(to_char(start_time, 'HH24:MI'), ''), COALESCE(to_char(end_time, 'HH24:MI'), ''),
+	COALESCE(to_char(start_date, 'YYYY-MM-DD'), ''), COALESCE(to_char(end_date, 'YYYY-MM-DD'), ''),
+	multiplier, duration_minutes, package_price, discount_percent, created_at, COALESCE(updated_at, created_at)`
+
+func scanPricingRule(row interface{ Scan(...interface{}) error }) (entities.PricingRule, error) {
+	var p entities.PricingRule
+	var weekdays pq.Int64Array
+	err := row.Scan(&p.ID, &p.Name, &p.Kind, &p.RoomType, &weekdays,
+		&p.StartTime, &p.EndTime, &p.StartDate, &p.EndDate,
+		&p.Multiplier, &p.DurationMinutes, &p.PackagePrice, &p.DiscountPercent, &p.CreatedAt, &p.UpdatedAt)
+	p.Weekdays = make([]int, 0, len(weekdays))
+	for _, w := range weekdays {
+		p.Weekdays = append(p.Weekdays, int(w))
+	}
+	return p, err
+}
+
+func (r *pricingRepository) queryPricingRules(query string, args ...interface{}) ([]entities.PricingRule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []entities.PricingRule{}
+	for rows.Next() {
+		p, err := scanPricingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, p)
+	}
+	return rules, nil
+}
+
+func (r *pricingRepository) GetPricingRules() ([]entities.PricingRule, error) {
+	return r.queryPricingRules(`SELECT ` + pricingRuleColumns + ` FROM pricing_rules ORDER BY kind, room_type NULLS FIRST, id`)
+}
+
+// GetPricingRulesForRoomType: rule khusus room type tersebut + rule untuk semua room
+func (r *pricingRepository) GetPricingRulesForRoomType(roomType string) ([]entities.PricingRule, error) {
+	return r.queryPricingRules(`SELECT `+pricingRuleColumns+` FROM pricing_rules
+		WHERE room_type::text = $1 OR room_type IS NULL
+		ORDER BY id`, roomType)
+}
+
+func pricingRuleArgs(rule entities.PricingRule) []interface{} {
+	weekdays := make(pq.Int64Array, 0, len(rule.Weekdays))
+	for _, w := range rule.Weekdays {
+		weekdays = append(weekdays, int64(w))
+	}
+	return []interface{}{
+		rule.Name, rule.Kind, nullableString(rule.RoomType), weekdays,
+		nullableString(rule.StartTime), nullableString(rule.EndTime),
+		nullableString(rule.StartDate), nullableString(rule.EndDate),
+		rule.Multiplier, rule.DurationMinutes, rule.PackagePrice, rule.DiscountPercent,
+	}
+}
+
+func (r *pricingRepository) CreatePricingRule(rule entities.PricingRule) (entities.PricingRule, error) {
+	return scanPricingRule(r.db.QueryRow(`
+		INSERT INTO pricing_rules (name, kind, room_type, weekdays, start_time, end_time, start_date, end_date,
+			multiplier, duration_minutes, package_price, discount_percent, created_at)
+		VALUES ($1, $2, $3::room_type, $4, $5::time, $6::time, $7::date, $8::date, $9, $10, $11, $12, NOW())
+		RETURNING `+pricingRuleColumns, pricingRuleArgs(rule)...))
+}
+
+func (r *pricingRepository) UpdatePricingRule(rule entities.PricingRule) (entities.PricingRule, error) {
+	args := append(pricingRuleArgs(rule), rule.ID)
+	saved, err := scanPricingRule(r.db.QueryRow(`
+		UPDATE pricing_rules
+		SET name = $1, kind = $2, room_type = $3::room_type, weekdays = $4, start_time = $5::time, end_time = $6::time,
+			start_date = $7::date, end_date = $8::date, multiplier = $9, duration_minutes = $10,
+			package_price = $11, discount_percent = $12, updated_at = NOW()
+		WHERE id = $13
+		RETURNING `+pricingRuleColumns, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return saved, fmt.Errorf("pricing rule %w", entities.ErrNotFound)
+	}
+	return saved, err
+}
+
+func (r *pricingRepository) DeletePricingRule(id int) error {
+	res, err := r.db.Exec(`DELETE FROM pricing_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("pricing rule %w", entities.ErrNotFound)
+	}
+	return nil
+}
@@ -2,22 +2,59 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
 	"BE-E-Meeting/app/entities"
+
+	"github.com/lib/pq"
 )
 
 type ReservationRepository interface {
 	CheckAvailability(roomID int, startTime, endTime time.Time) (bool, error)
-	Create(reservation entities.ReservationData, details []entities.ReservationDetailData) error
+	Create(reservation entities.ReservationData, details []entities.ReservationDetailData) (int, error)
+	CreateSeries(series entities.ReservationSeriesData, occurrences []entities.ReservationOccurrenceData) (int, error)
+	GetSeriesOccurrences(seriesID int) ([]entities.SeriesOccurrence, error)
+	GetDetails(reservationID int) ([]entities.ReservationDetailData, error)
+	Reschedule(occurrences []entities.ReservationOccurrenceData, changes []entities.ReservationChangeData) error
+	CheckAvailabilityExcept(roomID int, startTime, endTime time.Time, reservationID int) (bool, error)
+	GetChangeHistories(reservationID int) ([]entities.ReservationChangeHistory, error)
 	GetHistory(userID int, startDate, endDate, roomType, status string, limit, offset int) ([]entities.ReservationHistoryData, int, error)
 	GetByID(id int) (entities.ReservationHistoryData, error)
-	UpdateStatus(id int, status string) error
+	UpdateStatus(id int, fromStatus, toStatus string, changedBy int, reason string, settlement *entities.CancellationSettlement) error
+	UpdateStatuses(changes []entities.StatusChangeData, toStatus string, changedBy int, reason string) error
+	GetStatusHistories(reservationID int) ([]entities.ReservationStatusHistory, error)
 	GetUserIDByUsername(username string) (int, error)
 	GetSchedules(startDate, endDate string, limit, offset int) ([]entities.RoomScheduleInfo, int, error)
-	GetReservationsByRoomID(roomID int, start, end time.Time) ([]entities.RoomSchedule, error)
+	GetReservationsByRoomID(roomID int, start, end time.Time, includeCancelled bool) ([]entities.RoomSchedule, error)
+	CancelDetails(reservationID int, fromStatus string, detailIDs []int, after entities.ReservationOccurrenceData, change entities.ReservationChangeData, settlement entities.CancellationSettlement) (entities.ReservationData, error)
+	GetInvoiceLines(reservationID int) ([]entities.InvoiceLine, error)
+	ReleaseExpiredHolds() ([]entities.ExpiredHold, error)
+	CheckIn(reservationID int, openBeforeMinutes int) ([]entities.ReservationDetailData, error)
+	CheckInByRoomToken(token string, openBeforeMinutes int) ([]entities.ReservationDetailData, error)
+	MarkNoShows(graceMinutes int) ([]entities.ReservationDetailData, error)
+	GetPendingApprovals() ([]entities.PendingApproval, error)
+	GetRequesterEmail(reservationID int) (string, error)
+}
+
+// Filter reservasi yang masih menempati slot room (alias reservations: res, reservation_details: rd).
+// Reservasi yang sudah cancel/refunded, hold yang sudah lewat masa berlakunya dan detail
+// yang dicancel sebagian tidak dihitung agar slotnya bisa dibooking lagi.
+// Detail yang ditandai no-show juga dilepas (sisa slotnya bisa dibooking lagi).
+const activeReservationFilter = ` res.status_reservation NOT IN ('cancel', 'refunded')
+	AND (res.status_reservation <> 'hold' OR res.hold_expires_at > NOW())
+	AND rd.cancelled_at IS NULL AND rd.no_show_at IS NULL `
+
+// bufferedOverlap: slot detail rd dan rentang startArg-endArg bersinggungan jika keduanya
+// diperluas dengan buffer setup (sebelum) dan teardown (sesudah) milik room rm.
+// Jam meeting yang tersimpan tidak berubah, buffer hanya dipakai saat cek bentrok.
+func bufferedOverlap(startArg, endArg string) string {
+	return fmt.Sprintf(`(rd.start_at - rm.setup_buffer_minutes * INTERVAL '1 minute', rd.end_at + rm.teardown_buffer_minutes * INTERVAL '1 minute')
+		OVERLAPS (%s::timestamptz - rm.setup_buffer_minutes * INTERVAL '1 minute', %s::timestamptz + rm.teardown_buffer_minutes * INTERVAL '1 minute')`,
+		startArg, endArg)
 }
 
 type reservationRepository struct {
@@ -31,45 +68,343 @@ func NewReservationRepository(db *sql.DB) ReservationRepository {
 // 1. Availability
 func (r *reservationRepository) CheckAvailability(roomID int, startTime, endTime time.Time) (bool, error) {
 	var existing int
-	query := `SELECT COUNT(*) FROM reservation_details WHERE room_id = $1 AND (start_at, end_at) OVERLAPS ($2, $3)`
+	query := `
+		SELECT COUNT(*) FROM reservation_details rd
+		JOIN reservations res ON rd.reservation_id = res.id
+		JOIN rooms rm ON rm.id = rd.room_id
+		WHERE rd.room_id = $1 AND ` + bufferedOverlap("$2", "$3") + ` AND` + activeReservationFilter
 	err := r.db.QueryRow(query, roomID, startTime, endTime).Scan(&existing)
 	return existing == 0, err
 }
 
 // 2. Create
-func (r *reservationRepository) Create(res entities.ReservationData, details []entities.ReservationDetailData) error {
+// Cek availability dan insert dilakukan di transaksi yang sama. Row room dikunci
+// (SELECT ... FOR UPDATE) supaya booking paralel untuk room yang sama antri.
+func (r *reservationRepository) Create(res entities.ReservationData, details []entities.ReservationDetailData) (int, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer tx.Rollback()
 
+	if _, err := lockRooms(tx, details); err != nil {
+		return 0, err
+	}
+	if err := checkDiscountUsage(tx, res); err != nil {
+		return 0, err
+	}
+	reservationID, err := insertReservation(tx, res, details)
+	if err != nil {
+		return 0, err
+	}
+	return reservationID, tx.Commit()
+}
+
+// CreateSeries menyimpan series beserta semua occurrence-nya dalam satu transaksi.
+// Jika salah satu occurrence bentrok, seluruh series batal.
+func (r *reservationRepository) CreateSeries(series entities.ReservationSeriesData, occurrences []entities.ReservationOccurrenceData) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var allDetails []entities.ReservationDetailData
+	for _, o := range occurrences {
+		allDetails = append(allDetails, o.Details...)
+	}
+	if _, err := lockRooms(tx, allDetails); err != nil {
+		return 0, err
+	}
+	// Diskon dipakai sekali untuk seluruh series
+	if len(occurrences) > 0 {
+		if err := checkDiscountUsage(tx, occurrences[0].Reservation); err != nil {
+			return 0, err
+		}
+	}
+
+	var seriesID int
+	err = tx.QueryRow(`
+		INSERT INTO reservation_series (user_id, frequency, interval_value, by_weekday, until_at, occurrence_count, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW()) RETURNING id`,
+		series.UserID, series.Frequency, series.Interval, series.ByWeekday, series.Until, series.Count,
+	).Scan(&seriesID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, o := range occurrences {
+		o.Reservation.SeriesID = seriesID
+		if _, err := insertReservation(tx, o.Reservation, o.Details); err != nil {
+			return 0, err
+		}
+	}
+
+	return seriesID, tx.Commit()
+}
+
+// lockRooms mengunci row room dengan urutan ID yang tetap agar tidak deadlock.
+// Mengembalikan jeda minimal antar booking per room (buffer teardown + setup).
+func lockRooms(tx *sql.Tx, details []entities.ReservationDetailData) (map[int]time.Duration, error) {
+	roomIDs := make([]int, 0, len(details))
+	seen := make(map[int]bool)
+	for _, d := range details {
+		if !seen[d.RoomID] {
+			seen[d.RoomID] = true
+			roomIDs = append(roomIDs, d.RoomID)
+		}
+	}
+	sort.Ints(roomIDs)
+	gaps := make(map[int]time.Duration, len(roomIDs))
+	for _, roomID := range roomIDs {
+		var setup, teardown int
+		if err := tx.QueryRow(`SELECT setup_buffer_minutes, teardown_buffer_minutes FROM rooms WHERE id = $1 FOR UPDATE`, roomID).Scan(&setup, &teardown); err != nil {
+			return nil, err
+		}
+		gaps[roomID] = time.Duration(setup+teardown) * time.Minute
+	}
+	return gaps, nil
+}
+
+// overlapQuery menghitung detail aktif yang bentrok, kecuali milik reservasi di $4
+var overlapQuery = `
+	SELECT COUNT(*) FROM reservation_details rd
+	JOIN reservations res ON rd.reservation_id = res.id
+	JOIN rooms rm ON rm.id = rd.room_id
+	WHERE rd.room_id = $1 AND ` + bufferedOverlap("$2", "$3") + `
+	AND rd.reservation_id <> ALL($4) AND` + activeReservationFilter
+
+// insertReservation insert header + detail. Room harus sudah di-lock (lockRooms) dan
+// batas pemakaian diskon sudah dicek (checkDiscountUsage).
+func insertReservation(tx *sql.Tx, res entities.ReservationData, details []entities.ReservationDetailData) (int, error) {
 	var reservationID int
 	queryHeader := `
-		INSERT INTO reservations (user_id, contact_name, contact_phone, contact_company, note, status_reservation, subtotal_room, subtotal_snack, total, duration_minute, total_participants, add_snack, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, 'booked', $6, $7, $8, 0, $9, $10, NOW(), NOW()) RETURNING id`
+		INSERT INTO reservations (user_id, contact_name, contact_phone, contact_company, note, status_reservation, subtotal_room, subtotal_snack, total, duration_minute, total_participants, add_snack, series_id, occurrence_start, hold_expires_at, approval_reason,
+			promo_code_id, company_agreement_id, discount_amount, service_charge, tax_amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 0, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, NOW(), NOW()) RETURNING id`
 
+	var seriesID, occurrenceStart interface{}
+	if res.SeriesID > 0 {
+		seriesID = res.SeriesID
+		occurrenceStart = res.OccurrenceStart
+	}
+	status := res.StatusReservation
+	if status == "" {
+		status = "booked"
+	}
 	// Perhatikan mapping $ nya
-	err = tx.QueryRow(queryHeader,
-		res.UserID, res.ContactName, res.ContactPhone, res.ContactCompany, res.Note,
+	err := tx.QueryRow(queryHeader,
+		res.UserID, res.ContactName, res.ContactPhone, res.ContactCompany, res.Note, status,
 		res.SubTotalRoom, res.SubTotalSnack, res.Total, res.TotalParticipants, res.AddSnack,
+		seriesID, occurrenceStart, nullableTime(res.HoldExpiresAt), nullableString(res.ApprovalReason),
+		nullableID(res.PromoCodeID), nullableID(res.CompanyAgreementID), res.DiscountAmount, res.ServiceCharge, res.TaxAmount,
 	).Scan(&reservationID)
 
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	queryDetail := `
-		INSERT INTO reservation_details (reservation_id, room_id, room_name, room_price, snack_id, snack_name, snack_price, duration_minute, total_participants, total_room, total_snack, start_at, end_at, created_at, updated_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,NOW(),NOW())`
+		INSERT INTO reservation_details (reservation_id, room_id, room_name, room_type, room_price, snack_id, snack_name, snack_price, duration_minute, total_participants, total_room, total_snack, start_at, end_at, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,NOW(),NOW()) RETURNING id`
 
-	for _, d := range details {
-		_, err = tx.Exec(queryDetail, reservationID, d.RoomID, d.RoomName, d.RoomPrice, d.SnackID, d.SnackName, d.SnackPrice, d.DurationMinute, d.TotalParticipants, d.TotalRoom, d.TotalSnack, d.StartAt, d.EndAt)
+	details = append([]entities.ReservationDetailData(nil), details...)
+	for i, d := range details {
+		// Cek ulang di dalam transaksi (termasuk detail yang baru di-insert di request yang sama)
+		var existing int
+		if err := tx.QueryRow(overlapQuery, d.RoomID, d.StartAt, d.EndAt, pq.Array([]int{})).Scan(&existing); err != nil {
+			return 0, err
+		}
+		if existing > 0 {
+			return 0, &entities.ConflictError{RoomID: d.RoomID}
+		}
+
+		err = tx.QueryRow(queryDetail, reservationID, d.RoomID, d.RoomName, nullableString(d.RoomType), d.RoomPrice, nullableID(d.SnackID), d.SnackName, d.SnackPrice, d.DurationMinute, d.TotalParticipants, d.TotalRoom, d.TotalSnack, d.StartAt, d.EndAt).Scan(&details[i].ID)
+		if err != nil {
+			return 0, err
+		}
+		if err := insertSnackLines(tx, reservationID, details[i].ID, d.SnackLines); err != nil {
+			return 0, err
+		}
+	}
+	if err := insertInvoiceLines(tx, reservationID, res, details); err != nil {
+		return 0, err
+	}
+	return reservationID, nil
+}
+
+// insertSnackLines menyimpan item snack satu detail reservasi
+func insertSnackLines(tx *sql.Tx, reservationID, detailID int, lines []entities.SnackLine) error {
+	for _, l := range lines {
+		_, err := tx.Exec(`
+			INSERT INTO reservation_snack_lines (reservation_id, reservation_detail_id, snack_id, snack_name, snack_unit, snack_price, quantity, serve_at, subtotal, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())`,
+			reservationID, detailID, nullableID(l.SnackID), l.Name, l.Unit, l.Price, l.Quantity, l.ServeAt, l.Subtotal)
 		if err != nil {
 			return err
 		}
 	}
-	return tx.Commit()
+	return nil
+}
+
+// getSnackLines: item snack per ID detail, urut jam penyajian
+func (r *reservationRepository) getSnackLines(detailIDs []int) (map[int][]entities.SnackLine, error) {
+	result := map[int][]entities.SnackLine{}
+	if len(detailIDs) == 0 {
+		return result, nil
+	}
+	rows, err := r.db.Query(`
+		SELECT reservation_detail_id, COALESCE(snack_id, 0), snack_name, snack_unit, snack_price, quantity, serve_at, subtotal
+		FROM reservation_snack_lines
+		WHERE reservation_detail_id = ANY($1)
+		ORDER BY serve_at ASC, id ASC`, pq.Array(detailIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var detailID int
+		var l entities.SnackLine
+		if err := rows.Scan(&detailID, &l.SnackID, &l.Name, &l.Unit, &l.Price, &l.Quantity, &l.ServeAt, &l.Subtotal); err != nil {
+			return nil, err
+		}
+		result[detailID] = append(result[detailID], l)
+	}
+	return result, rows.Err()
+}
+
+// attachSnackLines mengisi snacks setiap room di response history / detail
+func (r *reservationRepository) attachSnackLines(rooms []entities.ReservationRoomDetail) error {
+	ids := make([]int, 0, len(rooms))
+	for _, room := range rooms {
+		ids = append(ids, room.DetailID)
+	}
+	lines, err := r.getSnackLines(ids)
+	if err != nil {
+		return err
+	}
+	for i := range rooms {
+		rooms[i].Snacks = lines[rooms[i].DetailID]
+		if rooms[i].Snacks == nil {
+			rooms[i].Snacks = []entities.SnackLine{}
+		}
+	}
+	return nil
+}
+
+// insertInvoiceLines menyimpan baris invoice per detail (ID detail harus sudah terisi),
+// lalu baris level reservasi (diskon, service charge, pajak) sesuai urutan
+func insertInvoiceLines(tx *sql.Tx, reservationID int, res entities.ReservationData, details []entities.ReservationDetailData) error {
+	query := `
+		INSERT INTO reservation_invoice_lines (reservation_id, reservation_detail_id, position, line_type, description, quantity, unit, unit_price,
+			rate, amount, tax_component_id, rounding, rounding_unit, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW())`
+
+	position := 0
+	insert := func(detailID int, l entities.InvoiceLine) error {
+		position++
+		var roundingUnit interface{}
+		if l.RoundingUnit > 0 {
+			roundingUnit = l.RoundingUnit
+		}
+		_, err := tx.Exec(query, reservationID, nullableID(detailID), position, l.Type, l.Description, l.Quantity, nullableString(l.Unit), l.UnitPrice,
+			l.Rate, l.Amount, nullableID(l.TaxComponentID), nullableString(l.Rounding), roundingUnit)
+		return err
+	}
+
+	for _, d := range details {
+		for _, l := range d.InvoiceLines {
+			if err := insert(d.ID, l); err != nil {
+				return err
+			}
+		}
+	}
+	for _, l := range res.InvoiceLines {
+		if err := insert(0, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceInvoiceLines: baris invoice lama diganti hasil hitung ulang (modify, reschedule, partial cancel)
+func replaceInvoiceLines(tx *sql.Tx, res entities.ReservationData, details []entities.ReservationDetailData) error {
+	if _, err := tx.Exec(`DELETE FROM reservation_invoice_lines WHERE reservation_id = $1`, res.ID); err != nil {
+		return err
+	}
+	return insertInvoiceLines(tx, res.ID, res, details)
+}
+
+// GetInvoiceLines mengambil baris invoice yang tersimpan, urut seperti saat disimpan
+func (r *reservationRepository) GetInvoiceLines(reservationID int) ([]entities.InvoiceLine, error) {
+	rows, err := r.db.Query(`
+		SELECT id, COALESCE(reservation_detail_id, 0), line_type, description, quantity, COALESCE(unit, ''), unit_price,
+			rate, amount, COALESCE(tax_component_id, 0), COALESCE(rounding, ''), COALESCE(rounding_unit, 0)
+		FROM reservation_invoice_lines
+		WHERE reservation_id = $1
+		ORDER BY position ASC, id ASC`, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []entities.InvoiceLine
+	for rows.Next() {
+		var l entities.InvoiceLine
+		if err := rows.Scan(&l.ID, &l.DetailID, &l.Type, &l.Description, &l.Quantity, &l.Unit, &l.UnitPrice,
+			&l.Rate, &l.Amount, &l.TaxComponentID, &l.Rounding, &l.RoundingUnit); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+// nullableString: string kosong disimpan sebagai NULL
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// nullableID: id 0 disimpan sebagai NULL (foreign key opsional)
+func nullableID(id int) interface{} {
+	if id > 0 {
+		return id
+	}
+	return nil
+}
+
+// Kolom snapshot reservation_details (alias rd) untuk ReservationRoomDetail, urutan sesuai roomDetailRow.dest
+const roomDetailColumns = `rd.id, COALESCE(rd.room_id, 0), rd.room_name, COALESCE(rd.room_type, ''), rd.room_price,
+	rd.start_at, rd.end_at, COALESCE(rd.duration_minute, 0), COALESCE(rd.total_participants, 0),
+	COALESCE(rd.snack_id, 0), rd.snack_name, rd.snack_price, COALESCE(rd.total_room, 0), COALESCE(rd.total_snack, 0), rd.cancelled_at`
+
+type roomDetailRow struct {
+	room        entities.ReservationRoomDetail
+	snack       entities.Snack
+	cancelledAt sql.NullTime
+}
+
+func (r *roomDetailRow) dest() []interface{} {
+	return []interface{}{&r.room.DetailID, &r.room.ID, &r.room.Name, &r.room.Type, &r.room.Price,
+		&r.room.StartTime, &r.room.EndTime, &r.room.Duration, &r.room.Participant,
+		&r.snack.ID, &r.snack.Name, &r.snack.Price, &r.room.TotalRoom, &r.room.TotalSnack, &r.cancelledAt}
+}
+
+// detail: snack tetap ditampilkan dari snapshot walaupun snack-nya sudah dihapus (ID 0)
+func (r *roomDetailRow) detail() entities.ReservationRoomDetail {
+	room := r.room
+	if r.snack.Name != "" {
+		snack := r.snack
+		room.Snack = &snack
+	}
+	if r.cancelledAt.Valid {
+		room.CancelledAt = &r.cancelledAt.Time
+	}
+	return room
 }
 
 // 3. Get History
@@ -79,18 +414,16 @@ func (r *reservationRepository) GetHistory(userID int, startDate, endDate, roomT
 		SELECT COUNT(DISTINCT r.id) 
 		FROM reservations r 
 		JOIN reservation_details rd ON r.id = rd.reservation_id
-		JOIN rooms rm ON rd.room_id = rm.id 
 		WHERE 1=1 `
 
-	// Query Data (Perbaikan: rm.type -> rm.room_type)
+	// Room dari snapshot reservation_details, tidak join ke rooms (room bisa sudah diubah / dihapus)
 	query := `
 		SELECT 
 			r.id, r.contact_name, r.contact_phone, r.contact_company, 
-			r.subtotal_snack, r.subtotal_room, r.total, r.status_reservation, r.created_at,
-			rd.room_id, rm.name, rm.room_type, rm.price_per_hour, rd.total_room, rd.total_snack
+			r.subtotal_snack, r.subtotal_room, r.total, r.status_reservation, r.cancellation_fee, r.refund_amount, r.created_at,
+			` + roomDetailColumns + `
 		FROM reservations r
 		JOIN reservation_details rd ON r.id = rd.reservation_id
-		JOIN rooms rm ON rd.room_id = rm.id
 		WHERE 1=1 `
 
 	var args []interface{}
@@ -112,9 +445,8 @@ func (r *reservationRepository) GetHistory(userID int, startDate, endDate, roomT
 		argCount += 2
 	}
 
-	// Perbaikan Filter Room Type (rm.type -> rm.room_type)
 	if roomType != "" {
-		filter := fmt.Sprintf(" AND rm.room_type = $%d", argCount)
+		filter := fmt.Sprintf(" AND rd.room_type = $%d", argCount)
 		countQuery += filter
 		query += filter
 		args = append(args, roomType)
@@ -135,7 +467,7 @@ func (r *reservationRepository) GetHistory(userID int, startDate, endDate, roomT
 		return nil, 0, err
 	}
 
-	query += fmt.Sprintf(" ORDER BY r.created_at DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	query += fmt.Sprintf(" ORDER BY r.created_at DESC, rd.start_at ASC, rd.id ASC LIMIT $%d OFFSET $%d", argCount, argCount+1)
 	args = append(args, limit, offset)
 
 	rows, err := r.db.Query(query, args...)
@@ -150,15 +482,12 @@ func (r *reservationRepository) GetHistory(userID int, startDate, endDate, roomT
 	for rows.Next() {
 		var resID int
 		var name, phone, company, stat string
-		var subSnack, subRoom, total float64
+		var subSnack, subRoom, total, fee, refund float64
 		var createdAt time.Time
-		var roomID int
-		var roomName, rType string
-		var rPrice, rTotal, rSnackTotal float64
+		var room roomDetailRow
 
-		err := rows.Scan(&resID, &name, &phone, &company, &subSnack, &subRoom, &total, &stat, &createdAt,
-			&roomID, &roomName, &rType, &rPrice, &rTotal, &rSnackTotal)
-		if err != nil {
+		dest := append([]interface{}{&resID, &name, &phone, &company, &subSnack, &subRoom, &total, &stat, &fee, &refund, &createdAt}, room.dest()...)
+		if err := rows.Scan(dest...); err != nil {
 			return nil, 0, err
 		}
 
@@ -166,20 +495,30 @@ func (r *reservationRepository) GetHistory(userID int, startDate, endDate, roomT
 			resultMap[resID] = &entities.ReservationHistoryData{
 				ID: resID, Name: name, PhoneNumber: phone, Company: company,
 				SubTotalSnack: subSnack, SubTotalRoom: subRoom, Total: total, Status: stat,
+				CancellationFee: fee, RefundAmount: refund,
 				CreatedAt: createdAt,
 				Rooms:     []entities.ReservationRoomDetail{},
 			}
 			order = append(order, resID)
 		}
 
-		resultMap[resID].Rooms = append(resultMap[resID].Rooms, entities.ReservationRoomDetail{
-			ID: roomID, Name: roomName, Type: rType, Price: rPrice, TotalRoom: rTotal, TotalSnack: rSnackTotal,
-		})
+		resultMap[resID].Rooms = append(resultMap[resID].Rooms, room.detail())
+	}
+
+	// Item snack semua room di halaman ini diambil sekaligus
+	var rooms []entities.ReservationRoomDetail
+	for _, id := range order {
+		rooms = append(rooms, resultMap[id].Rooms...)
+	}
+	if err := r.attachSnackLines(rooms); err != nil {
+		return nil, 0, err
 	}
 
 	var finalResult []entities.ReservationHistoryData
 	for _, id := range order {
-		finalResult = append(finalResult, *resultMap[id])
+		data := *resultMap[id]
+		data.Rooms, rooms = rooms[:len(data.Rooms)], rooms[len(data.Rooms):]
+		finalResult = append(finalResult, data)
 	}
 
 	return finalResult, totalData, nil
@@ -190,22 +529,34 @@ func (r *reservationRepository) GetByID(id int) (entities.ReservationHistoryData
 	var data entities.ReservationHistoryData
 
 	queryHeader := `
-		SELECT id, contact_name, contact_phone, contact_company, subtotal_snack, subtotal_room, total, status_reservation, created_at 
+		SELECT id, COALESCE(user_id, 0), COALESCE(series_id, 0), contact_name, contact_phone, contact_company, COALESCE(note, ''), subtotal_snack, subtotal_room, discount_amount,
+			service_charge, tax_amount, total, status_reservation,
+			cancellation_fee, refund_amount, hold_expires_at, COALESCE(approval_reason, ''),
+			COALESCE(payment_reference, ''), COALESCE(paid_amount, 0), paid_at, created_at 
 		FROM reservations WHERE id = $1`
 
+	var holdExpiresAt, paidAt sql.NullTime
 	err := r.db.QueryRow(queryHeader, id).Scan(
-		&data.ID, &data.Name, &data.PhoneNumber, &data.Company,
-		&data.SubTotalSnack, &data.SubTotalRoom, &data.Total, &data.Status, &data.CreatedAt,
+		&data.ID, &data.UserID, &data.SeriesID, &data.Name, &data.PhoneNumber, &data.Company, &data.Notes,
+		&data.SubTotalSnack, &data.SubTotalRoom, &data.Discount, &data.ServiceCharge, &data.Tax, &data.Total, &data.Status,
+		&data.CancellationFee, &data.RefundAmount, &holdExpiresAt, &data.ApprovalReason,
+		&data.PaymentReference, &data.PaidAmount, &paidAt, &data.CreatedAt,
 	)
 	if err != nil {
 		return data, err
 	}
+	if holdExpiresAt.Valid {
+		data.HoldExpiresAt = &holdExpiresAt.Time
+	}
+	if paidAt.Valid {
+		data.PaidAt = &paidAt.Time
+	}
 
 	queryDetails := `
-		SELECT rd.room_id, r.name, r.room_type, r.price_per_hour, rd.total_room, rd.total_snack
+		SELECT ` + roomDetailColumns + `
 		FROM reservation_details rd
-		JOIN rooms r ON rd.room_id = r.id
-		WHERE rd.reservation_id = $1`
+		WHERE rd.reservation_id = $1
+		ORDER BY rd.start_at ASC, rd.id ASC`
 
 	rows, err := r.db.Query(queryDetails, id)
 	if err != nil {
@@ -214,12 +565,244 @@ func (r *reservationRepository) GetByID(id int) (entities.ReservationHistoryData
 	defer rows.Close()
 
 	for rows.Next() {
-		var room entities.ReservationRoomDetail
-		rows.Scan(&room.ID, &room.Name, &room.Type, &room.Price, &room.TotalRoom, &room.TotalSnack)
-		data.Rooms = append(data.Rooms, room)
+		var room roomDetailRow
+		if err := rows.Scan(room.dest()...); err != nil {
+			return data, err
+		}
+		data.Rooms = append(data.Rooms, room.detail())
+	}
+	if err := r.attachSnackLines(data.Rooms); err != nil {
+		return data, err
 	}
 
-	return data, nil
+	data.InvoiceLines, err = r.GetInvoiceLines(id)
+	return data, err
+}
+
+// GetSeriesOccurrences mengambil semua reservasi dalam satu series, urut per occurrence
+func (r *reservationRepository) GetSeriesOccurrences(seriesID int) ([]entities.SeriesOccurrence, error) {
+	rows, err := r.db.Query(`
+		SELECT id, COALESCE(user_id, 0), status_reservation, occurrence_start
+		FROM reservations
+		WHERE series_id = $1
+		ORDER BY occurrence_start ASC`, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var occurrences []entities.SeriesOccurrence
+	for rows.Next() {
+		var o entities.SeriesOccurrence
+		if err := rows.Scan(&o.ReservationID, &o.UserID, &o.Status, &o.OccurrenceStart); err != nil {
+			return nil, err
+		}
+		occurrences = append(occurrences, o)
+	}
+	return occurrences, nil
+}
+
+// GetDetails mengambil snapshot detail (per room) dari satu reservasi, tanpa detail yang sudah dicancel
+func (r *reservationRepository) GetDetails(reservationID int) ([]entities.ReservationDetailData, error) {
+	rows, err := r.db.Query(`
+		SELECT id, reservation_id, COALESCE(room_id, 0), room_name, COALESCE(room_type, ''), room_price, COALESCE(snack_id, 0), snack_name, snack_price,
+			COALESCE(duration_minute, 0), COALESCE(total_participants, 0), COALESCE(total_room, 0), COALESCE(total_snack, 0), start_at, end_at
+		FROM reservation_details
+		WHERE reservation_id = $1 AND cancelled_at IS NULL
+		ORDER BY start_at ASC, id ASC`, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var details []entities.ReservationDetailData
+	for rows.Next() {
+		var d entities.ReservationDetailData
+		if err := rows.Scan(&d.ID, &d.ReservationID, &d.RoomID, &d.RoomName, &d.RoomType, &d.RoomPrice, &d.SnackID, &d.SnackName, &d.SnackPrice,
+			&d.DurationMinute, &d.TotalParticipants, &d.TotalRoom, &d.TotalSnack, &d.StartAt, &d.EndAt); err != nil {
+			return nil, err
+		}
+		details = append(details, d)
+	}
+
+	ids := make([]int, 0, len(details))
+	for _, d := range details {
+		ids = append(ids, d.ID)
+	}
+	lines, err := r.getSnackLines(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range details {
+		details[i].SnackLines = lines[details[i].ID]
+	}
+	return details, nil
+}
+
+// CheckAvailabilityExcept sama dengan CheckAvailability, tapi slot milik reservationID diabaikan
+func (r *reservationRepository) CheckAvailabilityExcept(roomID int, startTime, endTime time.Time, reservationID int) (bool, error) {
+	var existing int
+	err := r.db.QueryRow(overlapQuery, roomID, startTime, endTime, pq.Array([]int{reservationID})).Scan(&existing)
+	return existing == 0, err
+}
+
+// Reschedule menyimpan snapshot detail (jadwal, room, snack, peserta) & harga baru reservasi booked
+// beberapa reservasi sekaligus, beserta catatan perubahannya.
+// Slot lama milik reservasi yang sedang diubah diabaikan saat cek bentrok,
+// bentrok antar reservasi dalam batch dicek di sini juga.
+// Occurrence yang StatusReservation-nya "pending" sekalian dipindah ke antrian approval.
+func (r *reservationRepository) Reschedule(occurrences []entities.ReservationOccurrenceData, changes []entities.ReservationChangeData) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var ids []int
+	var allDetails []entities.ReservationDetailData
+	for _, o := range occurrences {
+		ids = append(ids, o.Reservation.ID)
+		allDetails = append(allDetails, o.Details...)
+	}
+	gaps, err := lockRooms(tx, allDetails)
+	if err != nil {
+		return err
+	}
+
+	for i, d := range allDetails {
+		gap := gaps[d.RoomID]
+		for _, other := range allDetails[i+1:] {
+			if d.RoomID == other.RoomID && d.StartAt.Before(other.EndAt.Add(gap)) && other.StartAt.Before(d.EndAt.Add(gap)) {
+				return &entities.ConflictError{RoomID: d.RoomID}
+			}
+		}
+
+		var existing int
+		if err := tx.QueryRow(overlapQuery, d.RoomID, d.StartAt, d.EndAt, pq.Array(ids)).Scan(&existing); err != nil {
+			return err
+		}
+		if existing > 0 {
+			return &entities.ConflictError{RoomID: d.RoomID}
+		}
+	}
+
+	changedBy := make(map[int]int, len(changes))
+	for _, ch := range changes {
+		changedBy[ch.ReservationID] = ch.ChangedBy
+	}
+
+	for _, o := range occurrences {
+		res := o.Reservation
+		// Hanya reservasi booked, status yang berubah di tengah jalan (mis. sudah paid) ditolak.
+		// StatusReservation diisi "pending" jika perubahan butuh approval admin, kosong = status tetap
+		result, err := tx.Exec(`
+			UPDATE reservations
+			SET subtotal_room=$1, subtotal_snack=$2, total=$3, note=$4, occurrence_start=COALESCE($5, occurrence_start),
+				total_participants=$6, add_snack=$7, discount_amount=$8, service_charge=$9, tax_amount=$10,
+				status_reservation=COALESCE($11::status_reservation, status_reservation),
+				approval_reason=COALESCE($12, approval_reason), updated_at=NOW()
+			WHERE id=$13 AND status_reservation = 'booked'`,
+			res.SubTotalRoom, res.SubTotalSnack, res.Total, res.Note, nullableTime(res.OccurrenceStart),
+			res.TotalParticipants, res.AddSnack, res.DiscountAmount, res.ServiceCharge, res.TaxAmount,
+			nullableString(res.StatusReservation), nullableString(res.ApprovalReason), res.ID)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return &entities.BadRequestError{Message: fmt.Sprintf("reservation %d is no longer booked", res.ID)}
+		}
+		if res.StatusReservation == "pending" {
+			if err := insertStatusHistory(tx, res.ID, changedBy[res.ID], "booked", "pending", res.ApprovalReason); err != nil {
+				return err
+			}
+		}
+
+		for _, d := range o.Details {
+			_, err := tx.Exec(`
+				UPDATE reservation_details
+				SET room_id=$1, room_name=$2, room_price=$3, snack_id=$4, snack_name=$5, snack_price=$6,
+					total_participants=$7, start_at=$8, end_at=$9, duration_minute=$10, total_room=$11, total_snack=$12,
+					room_type=$13, sequence=sequence+1, updated_at=NOW()
+				WHERE id=$14 AND reservation_id=$15`,
+				d.RoomID, d.RoomName, d.RoomPrice, nullableID(d.SnackID), d.SnackName, d.SnackPrice,
+				d.TotalParticipants, d.StartAt, d.EndAt, d.DurationMinute, d.TotalRoom, d.TotalSnack,
+				nullableString(d.RoomType), d.ID, res.ID)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(`DELETE FROM reservation_snack_lines WHERE reservation_detail_id = $1`, d.ID); err != nil {
+				return err
+			}
+			if err := insertSnackLines(tx, res.ID, d.ID, d.SnackLines); err != nil {
+				return err
+			}
+		}
+		if err := replaceInvoiceLines(tx, res, o.Details); err != nil {
+			return err
+		}
+	}
+
+	for _, ch := range changes {
+		if err := insertChangeHistory(tx, ch); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertChangeHistory(tx *sql.Tx, ch entities.ReservationChangeData) error {
+	before, err := json.Marshal(ch.Before)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(ch.After)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO reservation_change_histories (reservation_id, changed_by, reason, before_total, after_total, before_details, after_details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`,
+		ch.ReservationID, nullableID(ch.ChangedBy), ch.Reason, ch.BeforeTotal, ch.AfterTotal, before, after)
+	return err
+}
+
+func (r *reservationRepository) GetChangeHistories(reservationID int) ([]entities.ReservationChangeHistory, error) {
+	rows, err := r.db.Query(`
+		SELECT id, COALESCE(changed_by, 0), COALESCE(reason, ''), COALESCE(before_total, 0), COALESCE(after_total, 0),
+			COALESCE(before_details, '[]'::jsonb), COALESCE(after_details, '[]'::jsonb), created_at
+		FROM reservation_change_histories
+		WHERE reservation_id = $1
+		ORDER BY created_at ASC, id ASC`, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	histories := []entities.ReservationChangeHistory{}
+	for rows.Next() {
+		var h entities.ReservationChangeHistory
+		var before, after []byte
+		if err := rows.Scan(&h.ID, &h.ChangedBy, &h.Reason, &h.BeforeTotal, &h.AfterTotal, &before, &after, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		h.Before = before
+		h.After = after
+		histories = append(histories, h)
+	}
+	return histories, nil
+}
+
+// nullableTime: zero time disimpan sebagai NULL
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
 }
 
 func (r *reservationRepository) GetUserIDByUsername(username string) (int, error) {
@@ -228,13 +811,281 @@ func (r *reservationRepository) GetUserIDByUsername(username string) (int, error
 	return id, err
 }
 
-func (r *reservationRepository) UpdateStatus(id int, status string) error {
-	_, err := r.db.Exec(`UPDATE reservations SET status_reservation=$1 WHERE id=$2`, status, id)
+// UpdateStatus mengubah status dan mencatat perubahannya ke reservation_status_histories.
+// Update hanya berhasil jika status di DB masih sama dengan fromStatus, supaya
+// dua perubahan yang bersamaan tidak saling menimpa.
+func (r *reservationRepository) UpdateStatus(id int, fromStatus, toStatus string, changedBy int, reason string, settlement *entities.CancellationSettlement) error {
+	return r.UpdateStatuses([]entities.StatusChangeData{{ReservationID: id, FromStatus: fromStatus, Settlement: settlement}}, toStatus, changedBy, reason)
+}
+
+// UpdateStatuses mengubah status beberapa reservasi dalam satu transaksi (mis. cancel series),
+// jika salah satu gagal tidak ada yang berubah.
+func (r *reservationRepository) UpdateStatuses(changes []entities.StatusChangeData, toStatus string, changedBy int, reason string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, ch := range changes {
+		if err := updateStatus(tx, ch.ReservationID, ch.FromStatus, toStatus, changedBy, reason, ch.Settlement); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func updateStatus(tx *sql.Tx, id int, fromStatus, toStatus string, changedBy int, reason string, settlement *entities.CancellationSettlement) error {
+	// Hold yang sudah expired tidak bisa diubah lagi (akan dilepas oleh worker)
+	res, err := tx.Exec(`
+		UPDATE reservations SET status_reservation=$1, hold_expires_at=NULL, updated_at=NOW()
+		WHERE id=$2 AND status_reservation=$3 AND (status_reservation <> 'hold' OR hold_expires_at > NOW())`, toStatus, id, fromStatus)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &entities.BadRequestError{Message: fmt.Sprintf("reservation status has changed, current status is no longer %s", fromStatus)}
+	}
+
+	if settlement != nil {
+		_, err = tx.Exec(`UPDATE reservations SET cancellation_fee = cancellation_fee + $1, refund_amount = refund_amount + $2 WHERE id = $3`,
+			settlement.Fee, settlement.Refund, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Naikkan SEQUENCE agar kalender subscriber memperbarui event (mis. STATUS:CANCELLED)
+	_, err = tx.Exec(`UPDATE reservation_details SET sequence = sequence + 1, updated_at = NOW() WHERE reservation_id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return insertStatusHistory(tx, id, changedBy, fromStatus, toStatus, reason)
+}
+
+// ReleaseExpiredHolds mengubah hold yang sudah lewat hold_expires_at menjadi cancel
+// dan mengembalikan datanya untuk notifikasi ke pemegang hold.
+func (r *reservationRepository) ReleaseExpiredHolds() ([]entities.ExpiredHold, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		WITH expired AS (
+			UPDATE reservations SET status_reservation = 'cancel', updated_at = NOW()
+			WHERE status_reservation = 'hold' AND hold_expires_at <= NOW()
+			RETURNING id, user_id, contact_name, hold_expires_at
+		)
+		SELECT e.id, COALESCE(u.email, ''), e.contact_name, e.hold_expires_at
+		FROM expired e
+		LEFT JOIN users u ON u.id = e.user_id`)
+	if err != nil {
+		return nil, err
+	}
+
+	var holds []entities.ExpiredHold
+	for rows.Next() {
+		var h entities.ExpiredHold
+		if err := rows.Scan(&h.ReservationID, &h.Email, &h.ContactName, &h.ExpiredAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		holds = append(holds, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, h := range holds {
+		_, err := tx.Exec(`UPDATE reservation_details SET sequence = sequence + 1, updated_at = NOW() WHERE reservation_id = $1`, h.ReservationID)
+		if err != nil {
+			return nil, err
+		}
+		if err := insertStatusHistory(tx, h.ReservationID, 0, "hold", "cancel", "hold expired"); err != nil {
+			return nil, err
+		}
+	}
+
+	return holds, tx.Commit()
+}
+
+// Check-in dibuka openBefore menit sebelum start_at sampai end_at,
+// selama detail belum dicancel / ditandai no-show
+const checkInFilter = `
+	rd.cancelled_at IS NULL AND rd.no_show_at IS NULL AND rd.checked_in_at IS NULL
+	AND NOW() >= rd.start_at - ($2 * INTERVAL '1 minute') AND NOW() < rd.end_at
+	AND res.status_reservation IN ('booked', 'paid') `
+
+// CheckIn menandai detail reservasi yang sedang dalam jendela check-in
+func (r *reservationRepository) CheckIn(reservationID int, openBeforeMinutes int) ([]entities.ReservationDetailData, error) {
+	return r.updateDetails(`
+		UPDATE reservation_details rd SET checked_in_at = NOW(), updated_at = NOW()
+		FROM reservations res
+		WHERE res.id = rd.reservation_id AND rd.reservation_id = $1 AND`+checkInFilter+`
+		RETURNING rd.id, rd.reservation_id, COALESCE(rd.room_id, 0), rd.start_at, rd.end_at`, reservationID, openBeforeMinutes)
+}
+
+// CheckInByRoomToken: check-in lewat QR room, untuk reservasi yang sedang berjalan di room tersebut
+func (r *reservationRepository) CheckInByRoomToken(token string, openBeforeMinutes int) ([]entities.ReservationDetailData, error) {
+	return r.updateDetails(`
+		UPDATE reservation_details rd SET checked_in_at = NOW(), updated_at = NOW()
+		FROM reservations res, rooms rm
+		WHERE res.id = rd.reservation_id AND rm.id = rd.room_id AND rm.checkin_token = $1 AND`+checkInFilter+`
+		RETURNING rd.id, rd.reservation_id, COALESCE(rd.room_id, 0), rd.start_at, rd.end_at`, token, openBeforeMinutes)
+}
+
+// MarkNoShows menandai detail yang belum check-in sampai graceMinutes setelah start_at.
+// Hanya jadwal yang dimulai dalam 1 hari terakhir, agar data lama tidak ikut ditandai.
+func (r *reservationRepository) MarkNoShows(graceMinutes int) ([]entities.ReservationDetailData, error) {
+	return r.updateDetails(`
+		UPDATE reservation_details rd SET no_show_at = NOW(), sequence = sequence + 1, updated_at = NOW()
+		FROM reservations res
+		WHERE res.id = rd.reservation_id AND res.status_reservation IN ('booked', 'paid')
+		AND rd.cancelled_at IS NULL AND rd.no_show_at IS NULL AND rd.checked_in_at IS NULL
+		AND rd.start_at + ($1 * INTERVAL '1 minute') <= NOW() AND rd.start_at > NOW() - INTERVAL '1 day'
+		RETURNING rd.id, rd.reservation_id, COALESCE(rd.room_id, 0), rd.start_at, rd.end_at`, graceMinutes)
+}
+
+func (r *reservationRepository) updateDetails(query string, args ...interface{}) ([]entities.ReservationDetailData, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var details []entities.ReservationDetailData
+	for rows.Next() {
+		var d entities.ReservationDetailData
+		if err := rows.Scan(&d.ID, &d.ReservationID, &d.RoomID, &d.StartAt, &d.EndAt); err != nil {
+			return nil, err
+		}
+		details = append(details, d)
+	}
+	return details, rows.Err()
+}
+
+func insertStatusHistory(tx *sql.Tx, reservationID, changedBy int, fromStatus, toStatus, reason string) error {
+	_, err := tx.Exec(`
+		INSERT INTO reservation_status_histories (reservation_id, changed_by, from_status, to_status, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())`, reservationID, nullableID(changedBy), fromStatus, toStatus, reason)
 	return err
 }
 
+// CancelDetails mencancel sebagian baris reservation_details lalu menyimpan header & baris invoice
+// hasil hitung ulang dari detail yang tersisa (after) dalam satu transaksi.
+// Jika semua detail sudah dicancel, status reservasi ikut menjadi cancel.
+func (r *reservationRepository) CancelDetails(reservationID int, fromStatus string, detailIDs []int, after entities.ReservationOccurrenceData, change entities.ReservationChangeData, settlement entities.CancellationSettlement) (entities.ReservationData, error) {
+	res := after.Reservation
+	res.ID = reservationID
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	// Lock header agar tidak bersamaan dengan perubahan status lain
+	var status string
+	if err := tx.QueryRow(`SELECT status_reservation FROM reservations WHERE id = $1 FOR UPDATE`, reservationID).Scan(&status); err != nil {
+		return res, err
+	}
+	if status != fromStatus {
+		return res, &entities.BadRequestError{Message: fmt.Sprintf("reservation status has changed, current status is no longer %s", fromStatus)}
+	}
+
+	result, err := tx.Exec(`
+		UPDATE reservation_details
+		SET cancelled_at = NOW(), cancel_reason = $1, sequence = sequence + 1, updated_at = NOW()
+		WHERE reservation_id = $2 AND id = ANY($3) AND cancelled_at IS NULL`,
+		change.Reason, reservationID, pq.Array(detailIDs))
+	if err != nil {
+		return res, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return res, err
+	}
+	if int(affected) != len(detailIDs) {
+		return res, &entities.BadRequestError{Message: "some details are not found or already cancelled"}
+	}
+
+	// Total dihitung dari detail yang dibaca sebelum transaksi, pastikan detailnya masih sama
+	var remaining int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM reservation_details WHERE reservation_id = $1 AND cancelled_at IS NULL`, reservationID).Scan(&remaining); err != nil {
+		return res, err
+	}
+	if remaining != len(after.Details) {
+		return res, &entities.BadRequestError{Message: "reservation has been changed, please try again"}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE reservations SET
+			subtotal_room = $1, subtotal_snack = $2, discount_amount = $3, service_charge = $4, tax_amount = $5, total = $6,
+			total_participants = $7, add_snack = $8,
+			cancellation_fee = cancellation_fee + $9, refund_amount = refund_amount + $10,
+			updated_at = NOW()
+		WHERE id = $11`,
+		res.SubTotalRoom, res.SubTotalSnack, res.DiscountAmount, res.ServiceCharge, res.TaxAmount, res.Total,
+		res.TotalParticipants, res.AddSnack, settlement.Fee, settlement.Refund, reservationID)
+	if err != nil {
+		return res, err
+	}
+	if err := replaceInvoiceLines(tx, res, after.Details); err != nil {
+		return res, err
+	}
+
+	res.StatusReservation = status
+	if remaining == 0 {
+		_, err := tx.Exec(`UPDATE reservations SET status_reservation = 'cancel', updated_at = NOW() WHERE id = $1`, reservationID)
+		if err != nil {
+			return res, err
+		}
+		if err := insertStatusHistory(tx, reservationID, change.ChangedBy, status, "cancel", change.Reason); err != nil {
+			return res, err
+		}
+		res.StatusReservation = "cancel"
+	}
+
+	change.AfterTotal = res.Total
+	if err := insertChangeHistory(tx, change); err != nil {
+		return res, err
+	}
+
+	return res, tx.Commit()
+}
+
+func (r *reservationRepository) GetStatusHistories(reservationID int) ([]entities.ReservationStatusHistory, error) {
+	rows, err := r.db.Query(`
+		SELECT id, COALESCE(changed_by, 0), from_status, to_status, COALESCE(reason, ''), created_at
+		FROM reservation_status_histories
+		WHERE reservation_id = $1
+		ORDER BY created_at ASC, id ASC`, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	histories := []entities.ReservationStatusHistory{}
+	for rows.Next() {
+		var h entities.ReservationStatusHistory
+		if err := rows.Scan(&h.ID, &h.ChangedBy, &h.FromStatus, &h.ToStatus, &h.Reason, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		histories = append(histories, h)
+	}
+	return histories, nil
+}
+
 func (r *reservationRepository) GetSchedules(startDate, endDate string, limit, offset int) ([]entities.RoomScheduleInfo, int, error) {
-	filterSQL := " WHERE 1=1 "
+	filterSQL := " WHERE" + activeReservationFilter
 	args := []interface{}{}
 	argIdx := 1
 
@@ -252,7 +1103,7 @@ func (r *reservationRepository) GetSchedules(startDate, endDate string, limit, o
 	countQuery := `
 		SELECT COUNT(DISTINCT rd.room_id)
 		FROM reservation_details rd
-		LEFT JOIN reservations res ON rd.reservation_id = res.id
+		JOIN reservations res ON rd.reservation_id = res.id
 	` + filterSQL
 
 	var totalData int
@@ -270,7 +1121,7 @@ func (r *reservationRepository) GetSchedules(startDate, endDate string, limit, o
 			END as status
 		FROM reservation_details rd
 		JOIN rooms r ON r.id = rd.room_id
-		LEFT JOIN reservations res ON rd.reservation_id = res.id
+		JOIN reservations res ON rd.reservation_id = res.id
 	` + filterSQL + fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
 
 	args = append(args, limit, offset)
@@ -311,15 +1162,29 @@ func (r *reservationRepository) GetSchedules(startDate, endDate string, limit, o
 	return results, totalData, nil
 }
 
-func (r *reservationRepository) GetReservationsByRoomID(roomID int, start, end time.Time) ([]entities.RoomSchedule, error) {
+func (r *reservationRepository) GetReservationsByRoomID(roomID int, start, end time.Time, includeCancelled bool) ([]entities.RoomSchedule, error) {
 	query := `
-		SELECT rd.id, rd.start_at, rd.end_at, r.status_reservation, rd.total_participants 
+		SELECT rd.id, rd.start_at, rd.end_at,
+			rd.start_at - rm.setup_buffer_minutes * INTERVAL '1 minute',
+			rd.end_at + rm.teardown_buffer_minutes * INTERVAL '1 minute',
+			CASE
+				WHEN rd.cancelled_at IS NOT NULL THEN 'cancel'
+				WHEN rd.no_show_at IS NOT NULL THEN 'no_show'
+				ELSE res.status_reservation::text
+			END,
+			rd.total_participants 
 		FROM reservation_details rd 
-		JOIN reservations r ON rd.reservation_id = r.id 
+		JOIN reservations res ON rd.reservation_id = res.id 
+		JOIN rooms rm ON rm.id = rd.room_id
 		WHERE rd.room_id = $1 
-		AND (rd.start_at, rd.end_at) OVERLAPS ($2, $3) 
-		ORDER BY rd.start_at ASC
-	`
+		AND ` + bufferedOverlap("$2", "$3") + ` `
+	// Cancelled hanya ditampilkan kalau diminta (untuk audit). Status per baris: detail yang dicancel
+	// sebagian = cancel, ditandai no-show = no_show, selain itu status reservasinya
+	if !includeCancelled {
+		query += " AND" + activeReservationFilter
+	}
+	query += " ORDER BY rd.start_at ASC"
+
 	rows, err := r.db.Query(query, roomID, start, end)
 	if err != nil {
 		return nil, err
@@ -333,7 +1198,7 @@ func (r *reservationRepository) GetReservationsByRoomID(roomID int, start, end t
 		var status sql.NullString
 		var p sql.NullInt64
 
-		rows.Scan(&s.ID, &startAt, &endAt, &status, &p)
+		rows.Scan(&s.ID, &startAt, &endAt, &s.BlockedFrom, &s.BlockedUntil, &status, &p)
 
 		if startAt.Valid {
 			s.StartTime = startAt.Time
@@ -352,3 +1217,38 @@ func (r *reservationRepository) GetReservationsByRoomID(roomID int, start, end t
 	}
 	return schedules, nil
 }
+
+// GetPendingApprovals: antrian reservasi pending, yang paling lama menunggu lebih dulu
+func (r *reservationRepository) GetPendingApprovals() ([]entities.PendingApproval, error) {
+	rows, err := r.db.Query(`
+		SELECT res.id, COALESCE(res.user_id, 0), res.contact_name, COALESCE(res.contact_company, ''), COALESCE(u.email, ''),
+			res.total, COALESCE(res.approval_reason, ''), res.created_at
+		FROM reservations res
+		LEFT JOIN users u ON u.id = res.user_id
+		WHERE res.status_reservation = 'pending'
+		ORDER BY res.created_at ASC, res.id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvals := []entities.PendingApproval{}
+	for rows.Next() {
+		var a entities.PendingApproval
+		if err := rows.Scan(&a.ReservationID, &a.UserID, &a.Name, &a.Company, &a.Email, &a.Total, &a.ApprovalReason, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, a)
+	}
+	return approvals, rows.Err()
+}
+
+// GetRequesterEmail: email user pemilik reservasi (kosong jika tidak ada)
+func (r *reservationRepository) GetRequesterEmail(reservationID int) (string, error) {
+	var email string
+	err := r.db.QueryRow(`
+		SELECT COALESCE(u.email, '') FROM reservations res
+		LEFT JOIN users u ON u.id = res.user_id
+		WHERE res.id = $1`, reservationID).Scan(&email)
+	return email, err
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"BE-E-Meeting/app/entities"
 )
@@ -11,8 +12,10 @@ type RoomRepository interface {
 	Create(room entities.RoomRequest) error
 	GetAll(name, roomType, capacity string, limit, offset int) ([]entities.Room, int, error) // Return data + totalCount
 	GetByID(id int) (entities.Room, error)
+	GetAvailable(name, roomType, capacity string, start, end time.Time) ([]entities.Room, error)
 	Update(id int, room entities.RoomRequest) (int64, error) // Return rowsAffected
 	Delete(id int) (int64, error)                            // Return rowsAffected
+	GetOrCreateCheckInToken(id int, newToken string) (string, error)
 }
 
 type roomRepository struct {
@@ -26,17 +29,17 @@ func NewRoomRepository(db *sql.DB) RoomRepository {
 // 1. Create
 func (r *roomRepository) Create(room entities.RoomRequest) error {
 	query := `
-        INSERT INTO rooms (name, room_type, capacity, price_per_hour, picture_url, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+        INSERT INTO rooms (name, room_type, capacity, price_per_hour, picture_url, setup_buffer_minutes, teardown_buffer_minutes, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, COALESCE($6, 0), COALESCE($7, 0), NOW(), NOW())
     `
-	_, err := r.db.Exec(query, room.Name, room.Type, room.Capacity, room.PricePerHour, room.ImageURL)
+	_, err := r.db.Exec(query, room.Name, room.Type, room.Capacity, room.PricePerHour, room.ImageURL, room.SetupBufferMinutes, room.TeardownBufferMinutes)
 	return err
 }
 
 // 2. GetAll (Dengan Filter & Pagination)
 func (r *roomRepository) GetAll(name, roomType, capacity string, limit, offset int) ([]entities.Room, int, error) {
 	// Query Dasar
-	query := `SELECT id, name, room_type, capacity, price_per_hour, picture_url, created_at, updated_at FROM rooms WHERE 1=1`
+	query := `SELECT id, name, room_type, capacity, price_per_hour, picture_url, setup_buffer_minutes, teardown_buffer_minutes, created_at, updated_at FROM rooms WHERE 1=1`
 	countQuery := `SELECT COUNT(*) FROM rooms WHERE 1=1`
 
 	var args []interface{}
@@ -87,7 +90,8 @@ func (r *roomRepository) GetAll(name, roomType, capacity string, limit, offset i
 		var rm entities.Room
 		var createdAt, updatedAt sql.NullTime // Handle null time handling
 
-		if err := rows.Scan(&rm.ID, &rm.Name, &rm.RoomType, &rm.Capacity, &rm.PricePerHour, &rm.PictureURL, &createdAt, &updatedAt); err != nil {
+		if err := rows.Scan(&rm.ID, &rm.Name, &rm.RoomType, &rm.Capacity, &rm.PricePerHour, &rm.PictureURL,
+			&rm.SetupBufferMinutes, &rm.TeardownBufferMinutes, &createdAt, &updatedAt); err != nil {
 			return nil, 0, err
 		}
 
@@ -104,13 +108,79 @@ func (r *roomRepository) GetAll(name, roomType, capacity string, limit, offset i
 	return rooms, totalData, nil
 }
 
+// GetAvailable: filter sama seperti GetAll, ditambah hanya room yang tidak punya
+// reservasi aktif di rentang waktu tersebut (termasuk buffer room). Urut best fit (kapasitas terkecil, harga termurah).
+func (r *roomRepository) GetAvailable(name, roomType, capacity string, start, end time.Time) ([]entities.Room, error) {
+	query := `
+		SELECT rm.id, rm.name, rm.room_type, rm.capacity, rm.price_per_hour, rm.picture_url,
+			rm.setup_buffer_minutes, rm.teardown_buffer_minutes, rm.created_at, rm.updated_at
+		FROM rooms rm
+		WHERE NOT EXISTS (
+			SELECT 1 FROM reservation_details rd
+			JOIN reservations res ON rd.reservation_id = res.id
+			WHERE rd.room_id = rm.id AND ` + bufferedOverlap("$1", "$2") + ` AND` + activeReservationFilter + `
+		)`
+
+	args := []interface{}{start, end}
+	argIndex := 3
+
+	if name != "" {
+		query += fmt.Sprintf(" AND LOWER(rm.name) LIKE LOWER($%d)", argIndex)
+		args = append(args, "%"+name+"%")
+		argIndex++
+	}
+	if roomType != "" {
+		query += fmt.Sprintf(" AND rm.room_type = $%d", argIndex)
+		args = append(args, roomType)
+		argIndex++
+	}
+	if capacity != "" {
+		query += fmt.Sprintf(" AND rm.capacity >= $%d", argIndex)
+		args = append(args, capacity)
+		argIndex++
+	}
+
+	query += " ORDER BY rm.capacity ASC, rm.price_per_hour ASC, rm.id ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rooms := []entities.Room{}
+	for rows.Next() {
+		var rm entities.Room
+		var pictureURL sql.NullString
+		var createdAt, updatedAt sql.NullTime
+
+		if err := rows.Scan(&rm.ID, &rm.Name, &rm.RoomType, &rm.Capacity, &rm.PricePerHour, &pictureURL,
+			&rm.SetupBufferMinutes, &rm.TeardownBufferMinutes, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+
+		rm.PictureURL = pictureURL.String
+		if createdAt.Valid {
+			rm.CreatedAt = createdAt.Time
+		}
+		if updatedAt.Valid {
+			rm.UpdatedAt = updatedAt.Time
+		}
+
+		rooms = append(rooms, rm)
+	}
+
+	return rooms, nil
+}
+
 // 3. GetByID
 func (r *roomRepository) GetByID(id int) (entities.Room, error) {
-	query := `SELECT id, name, room_type, capacity, price_per_hour, picture_url, created_at, updated_at FROM rooms WHERE id = $1`
+	query := `SELECT id, name, room_type, capacity, price_per_hour, picture_url, setup_buffer_minutes, teardown_buffer_minutes, created_at, updated_at FROM rooms WHERE id = $1`
 	var rm entities.Room
 	var createdAt, updatedAt sql.NullTime
 
-	err := r.db.QueryRow(query, id).Scan(&rm.ID, &rm.Name, &rm.RoomType, &rm.Capacity, &rm.PricePerHour, &rm.PictureURL, &createdAt, &updatedAt)
+	err := r.db.QueryRow(query, id).Scan(&rm.ID, &rm.Name, &rm.RoomType, &rm.Capacity, &rm.PricePerHour, &rm.PictureURL,
+		&rm.SetupBufferMinutes, &rm.TeardownBufferMinutes, &createdAt, &updatedAt)
 
 	if createdAt.Valid {
 		rm.CreatedAt = createdAt.Time
@@ -126,10 +196,13 @@ func (r *roomRepository) GetByID(id int) (entities.Room, error) {
 func (r *roomRepository) Update(id int, room entities.RoomRequest) (int64, error) {
 	query := `
         UPDATE rooms 
-        SET name=$1, room_type=$2, capacity=$3, price_per_hour=$4, picture_url=$5, updated_at=NOW()
-        WHERE id=$6
+        SET name=$1, room_type=$2, capacity=$3, price_per_hour=$4, picture_url=$5,
+            setup_buffer_minutes=COALESCE($6, setup_buffer_minutes),
+            teardown_buffer_minutes=COALESCE($7, teardown_buffer_minutes), updated_at=NOW()
+        WHERE id=$8
     `
-	res, err := r.db.Exec(query, room.Name, room.Type, room.Capacity, room.PricePerHour, room.ImageURL, id)
+	res, err := r.db.Exec(query, room.Name, room.Type, room.Capacity, room.PricePerHour, room.ImageURL,
+		room.SetupBufferMinutes, room.TeardownBufferMinutes, id)
 	if err != nil {
 		return 0, err
 	}
@@ -145,3 +218,10 @@ func (r *roomRepository) Delete(id int) (int64, error) {
 	}
 	return res.RowsAffected()
 }
+
+// GetOrCreateCheckInToken mengembalikan token QR check-in room, newToken dipakai jika belum ada
+func (r *roomRepository) GetOrCreateCheckInToken(id int, newToken string) (string, error) {
+	var token string
+	err := r.db.QueryRow(`UPDATE rooms SET checkin_token = COALESCE(checkin_token, $1) WHERE id = $2 RETURNING checkin_token`, newToken, id).Scan(&token)
+	return token, err
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"BE-E-Meeting/app/entities"
+)
+
+type TaxRepository interface {
+	GetTaxComponents() ([]entities.TaxComponent, error)
+	CreateTaxComponent(component entities.TaxComponent) (entities.TaxComponent, error)
+	UpdateTaxComponent(component entities.TaxComponent) (entities.TaxComponent, error)
+	DeleteTaxComponent(id int) error
+}
+
+type taxRepository struct {
+	db *sql.DB
+}
+
+func NewTaxRepository(db *sql.DB) TaxRepository {
+	return &taxRepository{db: db}
+}
+
+const taxComponentColumns = `id, name, kind, rate, rounding, rounding_unit, sort_order, created_at, COALESCE(updated_at, created_at)`
+
+func scanTaxComponent(row interface{ Scan(...interface{}) error }) (entities.TaxComponent, error) {
+	var t entities.TaxComponent
+	err := row.Scan(&t.ID, &t.Name, &t.Kind, &t.Rate, &t.Rounding, &t.RoundingUnit, &t.SortOrder, &t.CreatedAt, &t.UpdatedAt)
+	return t, err
+}
+
+// GetTaxComponents: service charge dulu, lalu pajak, urut sort_order
+func (r *taxRepository) GetTaxComponents() ([]entities.TaxComponent, error) {
+	rows, err := r.db.Query(`SELECT ` + taxComponentColumns + ` FROM tax_components
+		ORDER BY CASE kind WHEN 'service' THEN 0 ELSE 1 END, sort_order, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	components := []entities.TaxComponent{}
+	for rows.Next() {
+		t, err := scanTaxComponent(rows)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, t)
+	}
+	return components, nil
+}
+
+func (r *taxRepository) CreateTaxComponent(component entities.TaxComponent) (entities.TaxComponent, error) {
+	return scanTaxComponent(r.db.QueryRow(`
+		INSERT INTO tax_components (name, kind, rate, rounding, rounding_unit, sort_order, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING `+taxComponentColumns,
+		component.Name, component.Kind, component.Rate, component.Rounding, component.RoundingUnit, component.SortOrder))
+}
+
+func (r *taxRepository) UpdateTaxComponent(component entities.TaxComponent) (entities.TaxComponent, error) {
+	saved, err := scanTaxComponent(r.db.QueryRow(`
+		UPDATE tax_components
+		SET name = $1, kind = $2, rate = $3, rounding = $4, rounding_unit = $5, sort_order = $6, updated_at = NOW()
+		WHERE id = $7
+		RETURNING `+taxComponentColumns,
+		component.Name, component.Kind, component.Rate, component.Rounding, component.RoundingUnit, component.SortOrder, component.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return saved, fmt.Errorf("tax component %w", entities.ErrNotFound)
+	}
+	return saved, err
+}
+
+func (r *taxRepository) DeleteTaxComponent(id int) error {
+	return deleteByID(r.db, "tax_components", id, "tax component")
+}
@@ -95,7 +95,10 @@ func (r *userRepository) GetByID(id int) (entities.GetUser, error) {
 	// atau biarkan driver sql convert ke string jika kompatibel.
 	// Di sini saya asumsikan driver pq bisa scan timestamp ke string langsung.
 
-	sqlStatement := `SELECT id, username, email, name, avatar_url, lang, role, status, created_at, updated_at FROM users WHERE id=$1`
+	sqlStatement := `SELECT id, username, email, name, avatar_url, lang, role, status, created_at, updated_at,
+		(SELECT COUNT(*) FROM reservation_details rd JOIN reservations res ON rd.reservation_id = res.id
+			WHERE res.user_id = users.id AND rd.no_show_at IS NOT NULL)
+		FROM users WHERE id=$1`
 
 	err := r.db.QueryRow(sqlStatement, id).Scan(
 		&user.Id,
@@ -108,6 +111,7 @@ func (r *userRepository) GetByID(id int) (entities.GetUser, error) {
 		&user.Status,
 		&user.Created_at,
 		&user.Updated_at,
+		&user.NoShowCount,
 	)
 
 	// Handle format tanggal jika user.Created_at kosong atau formatnya aneh (Optional logic)
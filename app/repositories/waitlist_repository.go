@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+)
+
+type WaitlistRepository interface {
+	Create(entry entities.WaitlistEntry) (int, error)
+	GetByUserID(userID int) ([]entities.WaitlistEntry, error)
+	GetByID(id int) (entities.WaitlistEntry, error)
+	Cancel(id int) error
+	GetWaiting(roomID int, start, end time.Time) ([]entities.WaitlistEntry, error)
+	SetOutcome(id int, status string, reservationID int, offerExpiresAt time.Time) error
+	ResolveOffer(reservationID int, status string) error
+}
+
+type waitlistRepository struct {
+	db *sql.DB
+}
+
+func NewWaitlistRepository(db *sql.DB) WaitlistRepository {
+	return &waitlistRepository{db: db}
+}
+
+const waitlistColumns = `
+	w.id, COALESCE(w.user_id, 0), w.room_id, w.start_at, w.end_at, w.participant, COALESCE(w.snack_id, 0),
+	COALESCE(w.contact_name, ''), COALESCE(w.contact_phone, ''), COALESCE(w.contact_company, ''), COALESCE(w.note, ''),
+	w.auto_book, w.status, COALESCE(w.reservation_id, 0), w.offer_expires_at, w.created_at, COALESCE(u.email, '')`
+
+const waitlistFrom = ` FROM reservation_waitlists w LEFT JOIN users u ON u.id = w.user_id `
+
+func scanWaitlistEntry(row interface{ Scan(...interface{}) error }) (entities.WaitlistEntry, error) {
+	var e entities.WaitlistEntry
+	var offerExpiresAt sql.NullTime
+	err := row.Scan(&e.ID, &e.UserID, &e.RoomID, &e.StartTime, &e.EndTime, &e.Participant, &e.SnackID,
+		&e.Name, &e.PhoneNumber, &e.Company, &e.Notes,
+		&e.AutoBook, &e.Status, &e.ReservationID, &offerExpiresAt, &e.CreatedAt, &e.Email)
+	if offerExpiresAt.Valid {
+		e.OfferExpiresAt = &offerExpiresAt.Time
+	}
+	return e, err
+}
+
+func (r *waitlistRepository) queryEntries(query string, args ...interface{}) ([]entities.WaitlistEntry, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []entities.WaitlistEntry{}
+	for rows.Next() {
+		e, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (r *waitlistRepository) Create(entry entities.WaitlistEntry) (int, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO reservation_waitlists (user_id, room_id, start_at, end_at, participant, snack_id,
+			contact_name, contact_phone, contact_company, note, auto_book, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 'waiting', NOW(), NOW())
+		RETURNING id`,
+		entry.UserID, entry.RoomID, entry.StartTime, entry.EndTime, entry.Participant, nullableID(entry.SnackID),
+		entry.Name, entry.PhoneNumber, entry.Company, entry.Notes, entry.AutoBook,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *waitlistRepository) GetByUserID(userID int) ([]entities.WaitlistEntry, error) {
+	return r.queryEntries(`SELECT `+waitlistColumns+waitlistFrom+` WHERE w.user_id = $1 ORDER BY w.created_at DESC`, userID)
+}
+
+func (r *waitlistRepository) GetByID(id int) (entities.WaitlistEntry, error) {
+	return scanWaitlistEntry(r.db.QueryRow(`SELECT `+waitlistColumns+waitlistFrom+` WHERE w.id = $1`, id))
+}
+
+// Cancel hanya untuk entry yang masih menunggu
+func (r *waitlistRepository) Cancel(id int) error {
+	res, err := r.db.Exec(`UPDATE reservation_waitlists SET status = 'cancelled', updated_at = NOW() WHERE id = $1 AND status = 'waiting'`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &entities.BadRequestError{Message: "waitlist entry is no longer waiting"}
+	}
+	return nil
+}
+
+// GetWaiting: entry yang menunggu room tersebut dan bersinggungan dengan slot yang kosong,
+// urut dari yang paling dulu mendaftar (FIFO)
+func (r *waitlistRepository) GetWaiting(roomID int, start, end time.Time) ([]entities.WaitlistEntry, error) {
+	return r.queryEntries(`SELECT `+waitlistColumns+waitlistFrom+`
+		WHERE w.room_id = $1 AND w.status = 'waiting' AND w.start_at > NOW()
+		AND (w.start_at, w.end_at) OVERLAPS ($2, $3)
+		ORDER BY w.created_at ASC, w.id ASC`, roomID, start, end)
+}
+
+// SetOutcome menyimpan hasil proses waitlist (offered / booked) beserta reservasinya
+func (r *waitlistRepository) SetOutcome(id int, status string, reservationID int, offerExpiresAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE reservation_waitlists SET status = $1, reservation_id = $2, offer_expires_at = $3, updated_at = NOW()
+		WHERE id = $4 AND status = 'waiting'`,
+		status, nullableID(reservationID), nullableTime(offerExpiresAt), id)
+	return err
+}
+
+// ResolveOffer menutup penawaran saat hold-nya dikonversi (booked) atau dilepas (expired)
+func (r *waitlistRepository) ResolveOffer(reservationID int, status string) error {
+	_, err := r.db.Exec(`
+		UPDATE reservation_waitlists SET status = $1, updated_at = NOW()
+		WHERE reservation_id = $2 AND status = 'offered'`, status, reservationID)
+	return err
+}
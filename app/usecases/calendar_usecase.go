@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/repositories"
+	"BE-E-Meeting/app/utils"
+)
+
+type CalendarUsecase interface {
+	GetFeed(userID int, req entities.CalendarFeedRequest, baseURL string) (entities.CalendarFeed, error)
+	ReservationICS(reservationID int) (string, error)
+	FeedICS(token string) (string, error)
+}
+
+type calendarUsecase struct {
+	calendarRepo repositories.CalendarRepository
+	roomRepo     repositories.RoomRepository
+}
+
+func NewCalendarUsecase(calendarRepo repositories.CalendarRepository, roomRepo repositories.RoomRepository) CalendarUsecase {
+	return &calendarUsecase{calendarRepo: calendarRepo, roomRepo: roomRepo}
+}
+
+// GetFeed mengembalikan link subscription (read-only) milik user. Token dibuat
+// sekali per user per feed, request berikutnya mengembalikan token yang sama.
+func (u *calendarUsecase) GetFeed(userID int, req entities.CalendarFeedRequest, baseURL string) (entities.CalendarFeed, error) {
+	ownerID := userID
+	if req.Type == "room" {
+		if _, err := u.roomRepo.GetByID(req.RoomID); err != nil {
+			return entities.CalendarFeed{}, fmt.Errorf("room %w", entities.ErrNotFound)
+		}
+		ownerID = req.RoomID
+	}
+
+	feed, err := u.calendarRepo.GetFeed(req.Type, ownerID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		token, err := generateRandomToken()
+		if err != nil {
+			return feed, err
+		}
+		feed = entities.CalendarFeed{Token: token, Type: req.Type, OwnerID: ownerID}
+		if err := u.calendarRepo.CreateFeed(feed, userID); err != nil {
+			return feed, err
+		}
+	} else if err != nil {
+		return feed, err
+	}
+
+	feed.URL = fmt.Sprintf("%s/calendar/%s.ics", baseURL, feed.Token)
+	return feed, nil
+}
+
+// ReservationICS membuat file .ics untuk satu reservasi (satu VEVENT per room)
+func (u *calendarUsecase) ReservationICS(reservationID int) (string, error) {
+	events, err := u.calendarRepo.GetEventsByReservationID(reservationID)
+	if err != nil {
+		return "", err
+	}
+	if len(events) == 0 {
+		return "", fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	return utils.BuildICS(fmt.Sprintf("Reservation #%d", reservationID), toICSEvents(events, true)), nil
+}
+
+// FeedICS membuat isi feed subscription berdasarkan token
+func (u *calendarUsecase) FeedICS(token string) (string, error) {
+	feed, err := u.calendarRepo.GetFeedByToken(token)
+	if err != nil {
+		return "", fmt.Errorf("calendar feed %w", entities.ErrNotFound)
+	}
+
+	// Event yang selesai lebih dari sehari lalu tidak perlu dikirim lagi
+	from := time.Now().AddDate(0, 0, -1)
+
+	switch feed.Type {
+	case "room":
+		room, err := u.roomRepo.GetByID(feed.OwnerID)
+		if err != nil {
+			return "", fmt.Errorf("room %w", entities.ErrNotFound)
+		}
+		events, err := u.calendarRepo.GetRoomEvents(feed.OwnerID, from)
+		if err != nil {
+			return "", err
+		}
+		// Feed room tidak menampilkan data kontak pemesan
+		return utils.BuildICS(room.Name+" Schedule", toICSEvents(events, false)), nil
+	default:
+		events, err := u.calendarRepo.GetUserEvents(feed.OwnerID, from)
+		if err != nil {
+			return "", err
+		}
+		return utils.BuildICS("My Meeting Reservations", toICSEvents(events, true)), nil
+	}
+}
+
+func toICSEvents(events []entities.CalendarEvent, withContact bool) []utils.ICSEvent {
+	icsEvents := make([]utils.ICSEvent, 0, len(events))
+	for _, e := range events {
+		ev := utils.ICSEvent{
+			UID:      fmt.Sprintf("reservation-detail-%d@e-meeting", e.DetailID),
+			Summary:  "Reserved",
+			Location: e.RoomName,
+			Status:   icsStatus(e.Status),
+			Sequence: e.Sequence,
+			Start:    e.StartAt,
+			End:      e.EndAt,
+			Stamp:    e.UpdatedAt,
+		}
+		if withContact {
+			ev.Summary = "Meeting - " + e.RoomName
+			ev.Description = fmt.Sprintf("Reservation #%d\nCompany: %s", e.ReservationID, e.Company)
+			if e.Note != "" {
+				ev.Description += "\nNotes: " + e.Note
+			}
+		}
+		icsEvents = append(icsEvents, ev)
+	}
+	return icsEvents
+}
+
+// icsStatus memetakan status_reservation ke STATUS VEVENT
+func icsStatus(status string) string {
+	switch status {
+	case "cancel", "refunded":
+		return "CANCELLED"
+	case "hold", "pending":
+		return "TENTATIVE"
+	default:
+		return "CONFIRMED"
+	}
+}
+
+func generateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,276 @@
+package usecases
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/repositories"
+)
+
+type DiscountUsecase interface {
+	GetPromoCodes() ([]entities.PromoCode, error)
+	CreatePromoCode(req entities.PromoCodeRequest) (entities.PromoCode, error)
+	UpdatePromoCode(id int, req entities.PromoCodeRequest) (entities.PromoCode, error)
+	DeletePromoCode(id int) error
+	GetCompanyAgreements() ([]entities.CompanyAgreement, error)
+	CreateCompanyAgreement(req entities.CompanyAgreementRequest) (entities.CompanyAgreement, error)
+	UpdateCompanyAgreement(id int, req entities.CompanyAgreementRequest) (entities.CompanyAgreement, error)
+	DeleteCompanyAgreement(id int) error
+}
+
+type discountUsecase struct {
+	discountRepo repositories.DiscountRepository
+}
+
+func NewDiscountUsecase(discountRepo repositories.DiscountRepository) DiscountUsecase {
+	return &discountUsecase{discountRepo: discountRepo}
+}
+
+func discountTermsFromRequest(req entities.DiscountTermsRequest) (entities.DiscountTerms, error) {
+	if req.DiscountType == "percent" && req.DiscountValue > 100 {
+		return entities.DiscountTerms{}, &entities.BadRequestError{Message: "percent discount cannot be more than 100"}
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidUntil.After(*req.ValidFrom) {
+		return entities.DiscountTerms{}, &entities.BadRequestError{Message: "validUntil must be after validFrom"}
+	}
+	terms := entities.DiscountTerms{
+		DiscountType: req.DiscountType, DiscountValue: req.DiscountValue,
+		ValidFrom: req.ValidFrom, ValidUntil: req.ValidUntil, UsageLimit: req.UsageLimit,
+		RoomTypes: req.RoomTypes, SnackIDs: req.SnackIDs,
+	}
+	if terms.RoomTypes == nil {
+		terms.RoomTypes = []string{}
+	}
+	if terms.SnackIDs == nil {
+		terms.SnackIDs = []int{}
+	}
+	return terms, nil
+}
+
+func (u *discountUsecase) GetPromoCodes() ([]entities.PromoCode, error) {
+	return u.discountRepo.GetPromoCodes()
+}
+
+func promoCodeFromRequest(req entities.PromoCodeRequest) (entities.PromoCode, error) {
+	terms, err := discountTermsFromRequest(req.DiscountTermsRequest)
+	if err != nil {
+		return entities.PromoCode{}, err
+	}
+	return entities.PromoCode{
+		Code: strings.TrimSpace(req.Code), Name: req.Name,
+		DiscountTerms: terms, PerUserLimit: req.PerUserLimit,
+	}, nil
+}
+
+func (u *discountUsecase) CreatePromoCode(req entities.PromoCodeRequest) (entities.PromoCode, error) {
+	promo, err := promoCodeFromRequest(req)
+	if err != nil {
+		return promo, err
+	}
+	return u.discountRepo.CreatePromoCode(promo)
+}
+
+func (u *discountUsecase) UpdatePromoCode(id int, req entities.PromoCodeRequest) (entities.PromoCode, error) {
+	promo, err := promoCodeFromRequest(req)
+	if err != nil {
+		return promo, err
+	}
+	promo.ID = id
+	return u.discountRepo.UpdatePromoCode(promo)
+}
+
+func (u *discountUsecase) DeletePromoCode(id int) error {
+	return u.discountRepo.DeletePromoCode(id)
+}
+
+func (u *discountUsecase) GetCompanyAgreements() ([]entities.CompanyAgreement, error) {
+	return u.discountRepo.GetCompanyAgreements()
+}
+
+func companyAgreementFromRequest(req entities.CompanyAgreementRequest) (entities.CompanyAgreement, error) {
+	terms, err := discountTermsFromRequest(req.DiscountTermsRequest)
+	if err != nil {
+		return entities.CompanyAgreement{}, err
+	}
+	return entities.CompanyAgreement{
+		Code: strings.TrimSpace(req.Code), CompanyName: strings.TrimSpace(req.CompanyName), DiscountTerms: terms,
+	}, nil
+}
+
+func (u *discountUsecase) CreateCompanyAgreement(req entities.CompanyAgreementRequest) (entities.CompanyAgreement, error) {
+	agreement, err := companyAgreementFromRequest(req)
+	if err != nil {
+		return agreement, err
+	}
+	return u.discountRepo.CreateCompanyAgreement(agreement)
+}
+
+func (u *discountUsecase) UpdateCompanyAgreement(id int, req entities.CompanyAgreementRequest) (entities.CompanyAgreement, error) {
+	agreement, err := companyAgreementFromRequest(req)
+	if err != nil {
+		return agreement, err
+	}
+	agreement.ID = id
+	return u.discountRepo.UpdateCompanyAgreement(agreement)
+}
+
+func (u *discountUsecase) DeleteCompanyAgreement(id int) error {
+	return u.discountRepo.DeleteCompanyAgreement(id)
+}
+
+// appliedDiscounts: promo code / company agreement dari request yang sudah divalidasi
+type appliedDiscounts struct {
+	promo     *entities.PromoCode
+	agreement *entities.CompanyAgreement
+}
+
+// checkValidity: diskon hanya berlaku di antara validFrom dan validUntil
+func checkValidity(label string, terms entities.DiscountTerms, now time.Time) error {
+	if terms.ValidFrom != nil && now.Before(*terms.ValidFrom) {
+		return &entities.BadRequestError{Message: fmt.Sprintf("%s is not valid until %s", label, terms.ValidFrom.Format("02 Jan 2006 15:04"))}
+	}
+	if terms.ValidUntil != nil && !now.Before(*terms.ValidUntil) {
+		return &entities.BadRequestError{Message: fmt.Sprintf("%s has expired", label)}
+	}
+	return nil
+}
+
+// resolveDiscounts memvalidasi promoCode & agreementCode di request: masa berlaku, batas pemakaian,
+// dan company agreement harus sama dengan company di reservasi.
+// Batas pemakaian dicek ulang di transaksi insert (lihat repositories.checkDiscountUsage).
+func (u *reservationUsecase) resolveDiscounts(req entities.ReservationRequest) (appliedDiscounts, error) {
+	var applied appliedDiscounts
+	now := time.Now()
+
+	if code := strings.TrimSpace(req.PromoCode); code != "" {
+		promo, err := u.discountRepo.GetPromoCodeByCode(code)
+		if errors.Is(err, sql.ErrNoRows) {
+			return applied, fmt.Errorf("promo code %w", entities.ErrNotFound)
+		}
+		if err != nil {
+			return applied, err
+		}
+		if err := checkValidity("promo code", promo.DiscountTerms, now); err != nil {
+			return applied, err
+		}
+		total, byUser, err := u.discountRepo.PromoCodeUsage(promo.ID, req.UserID)
+		if err != nil {
+			return applied, err
+		}
+		if promo.UsageLimit > 0 && total >= promo.UsageLimit {
+			return applied, &entities.BadRequestError{Message: "promo code usage limit has been reached"}
+		}
+		if promo.PerUserLimit > 0 && byUser >= promo.PerUserLimit {
+			return applied, &entities.BadRequestError{Message: "you have reached the usage limit of this promo code"}
+		}
+		applied.promo = &promo
+	}
+
+	if code := strings.TrimSpace(req.AgreementCode); code != "" {
+		agreement, err := u.discountRepo.GetCompanyAgreementByCode(code)
+		if errors.Is(err, sql.ErrNoRows) {
+			return applied, fmt.Errorf("company agreement %w", entities.ErrNotFound)
+		}
+		if err != nil {
+			return applied, err
+		}
+		if !strings.EqualFold(agreement.CompanyName, strings.TrimSpace(req.Company)) {
+			return applied, &entities.BadRequestError{Message: fmt.Sprintf("company agreement %s is only valid for %s", agreement.Code, agreement.CompanyName)}
+		}
+		if err := checkValidity("company agreement", agreement.DiscountTerms, now); err != nil {
+			return applied, err
+		}
+		total, err := u.discountRepo.CompanyAgreementUsage(agreement.ID)
+		if err != nil {
+			return applied, err
+		}
+		if agreement.UsageLimit > 0 && total >= agreement.UsageLimit {
+			return applied, &entities.BadRequestError{Message: "company agreement usage limit has been reached"}
+		}
+		applied.agreement = &agreement
+	}
+	return applied, nil
+}
+
+// discountLines menghitung potongan dari harga sebelum diskon. Agreement dan promo dihitung
+// masing-masing dari bagian yang eligible (room type & snack), total potongan tidak melebihi subtotal.
+func (u *reservationUsecase) discountLines(details []entities.ReservationDetailData, applied appliedDiscounts) ([]entities.DiscountLine, float64, error) {
+	if applied.promo == nil && applied.agreement == nil {
+		return nil, 0, nil
+	}
+
+	subtotal := 0.0
+	roomTypes := map[int]string{}
+	for _, d := range details {
+		subtotal += d.TotalRoom + d.TotalSnack
+		if _, ok := roomTypes[d.RoomID]; ok {
+			continue
+		}
+		room, err := u.roomRepo.GetByID(d.RoomID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("room %w", entities.ErrNotFound)
+		}
+		roomTypes[d.RoomID] = room.RoomType
+	}
+
+	var lines []entities.DiscountLine
+	discount := 0.0
+	apply := func(line entities.DiscountLine, terms entities.DiscountTerms) error {
+		for _, d := range details {
+			if len(terms.RoomTypes) > 0 && !containsString(terms.RoomTypes, roomTypes[d.RoomID]) {
+				continue
+			}
+			line.EligibleAmount += d.TotalRoom
+			for _, s := range detailSnackLines(d) {
+				if len(terms.SnackIDs) == 0 || containsInt(terms.SnackIDs, s.SnackID) {
+					line.EligibleAmount += s.Subtotal
+				}
+			}
+		}
+		if line.EligibleAmount <= 0 {
+			return &entities.BadRequestError{Message: fmt.Sprintf("%s %s is not valid for the selected rooms", line.Source, line.Code)}
+		}
+
+		line.DiscountType, line.DiscountValue = terms.DiscountType, terms.DiscountValue
+		if terms.DiscountType == "percent" {
+			line.Amount = roundPrice(line.EligibleAmount * terms.DiscountValue / 100)
+		} else {
+			line.Amount = roundPrice(minFloat(terms.DiscountValue, line.EligibleAmount))
+		}
+		lines = append(lines, line)
+		discount += line.Amount
+		return nil
+	}
+
+	if a := applied.agreement; a != nil {
+		if err := apply(entities.DiscountLine{Source: "agreement", Code: a.Code, Name: a.CompanyName}, a.DiscountTerms); err != nil {
+			return nil, 0, err
+		}
+	}
+	if p := applied.promo; p != nil {
+		if err := apply(entities.DiscountLine{Source: "promo", Code: p.Code, Name: p.Name}, p.DiscountTerms); err != nil {
+			return nil, 0, err
+		}
+	}
+	return lines, roundPrice(minFloat(discount, subtotal)), nil
+}
+
+// scaleDiscount: setelah booking, diskon yang tercatat disesuaikan proporsional dengan subtotal baru
+// (dipakai saat modify, reschedule series dan partial cancel)
+func scaleDiscount(discount, beforeSubtotal, afterSubtotal float64) float64 {
+	if discount <= 0 || beforeSubtotal <= 0 {
+		return 0
+	}
+	return roundPrice(discount * afterSubtotal / beforeSubtotal)
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,237 @@
+package usecases
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/repositories"
+)
+
+// Zona waktu jam buka & tanggal blackout jika APP_TIMEZONE tidak diisi
+const defaultBusinessTimezone = "Asia/Jakarta"
+
+// businessLocation: zona waktu lokasi gedung, dipakai untuk menentukan hari & jam buka
+func businessLocation() *time.Location {
+	name := os.Getenv("APP_TIMEZONE")
+	if name == "" {
+		name = defaultBusinessTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("opening hours: unknown timezone %q, using local time: %v", name, err)
+		return time.Local
+	}
+	return loc
+}
+
+// parseClock mengubah "HH:MM" menjadi menit sejak tengah malam ("24:00" = 1440)
+func parseClock(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return 0, &entities.BadRequestError{Message: fmt.Sprintf("invalid time %q, use HH:MM", value)}
+	}
+	hour, errHour := strconv.Atoi(parts[0])
+	minute, errMinute := strconv.Atoi(parts[1])
+	if errHour != nil || errMinute != nil || hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, &entities.BadRequestError{Message: fmt.Sprintf("invalid time %q, use HH:MM", value)}
+	}
+	return hour*60 + minute, nil
+}
+
+type OpeningHoursUsecase interface {
+	GetOperatingHours(roomID int) ([]entities.OperatingHour, error)
+	SaveOperatingHours(req entities.OperatingHoursRequest) ([]entities.OperatingHour, error)
+	GetBlackoutDates(roomID int, startDate, endDate string) ([]entities.BlackoutDate, error)
+	CreateBlackoutDate(req entities.BlackoutDateRequest) (entities.BlackoutDate, error)
+	DeleteBlackoutDate(id int) error
+}
+
+type openingHoursUsecase struct {
+	openingRepo repositories.OpeningHoursRepository
+	roomRepo    repositories.RoomRepository
+}
+
+func NewOpeningHoursUsecase(openingRepo repositories.OpeningHoursRepository, roomRepo repositories.RoomRepository) OpeningHoursUsecase {
+	return &openingHoursUsecase{openingRepo: openingRepo, roomRepo: roomRepo}
+}
+
+// GetOperatingHours mengembalikan jam buka efektif 7 hari untuk room (roomID 0 = default global).
+// RoomID pada tiap hari menunjukkan sumbernya: 0 = default global.
+func (u *openingHoursUsecase) GetOperatingHours(roomID int) ([]entities.OperatingHour, error) {
+	if roomID > 0 {
+		if _, err := u.roomRepo.GetByID(roomID); err != nil {
+			return nil, fmt.Errorf("room %w", entities.ErrNotFound)
+		}
+	}
+
+	hours := make([]entities.OperatingHour, 0, 7)
+	for weekday := 0; weekday < 7; weekday++ {
+		hour, err := u.openingRepo.GetEffectiveOperatingHour(roomID, weekday)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Tidak diatur = buka 24 jam
+			hour = entities.OperatingHour{Weekday: weekday, OpenTime: "00:00", CloseTime: "24:00"}
+		} else if err != nil {
+			return nil, err
+		}
+		hours = append(hours, hour)
+	}
+	return hours, nil
+}
+
+// SaveOperatingHours mengganti jam buka mingguan room / global.
+// Hari yang tidak dikirim mengikuti default global (atau buka 24 jam untuk global).
+func (u *openingHoursUsecase) SaveOperatingHours(req entities.OperatingHoursRequest) ([]entities.OperatingHour, error) {
+	if req.RoomID > 0 {
+		if _, err := u.roomRepo.GetByID(req.RoomID); err != nil {
+			return nil, fmt.Errorf("room %w", entities.ErrNotFound)
+		}
+	}
+
+	seen := make(map[int]bool, len(req.Hours))
+	hours := make([]entities.OperatingHour, 0, len(req.Hours))
+	for _, h := range req.Hours {
+		if seen[h.Weekday] {
+			return nil, &entities.BadRequestError{Message: fmt.Sprintf("weekday %d is listed more than once", h.Weekday)}
+		}
+		seen[h.Weekday] = true
+
+		hour := entities.OperatingHour{RoomID: req.RoomID, Weekday: h.Weekday, OpenTime: "00:00", CloseTime: "24:00", Closed: h.Closed}
+		if !h.Closed {
+			openMin, err := parseClock(h.OpenTime)
+			if err != nil {
+				return nil, err
+			}
+			closeMin, err := parseClock(h.CloseTime)
+			if err != nil {
+				return nil, err
+			}
+			if closeMin <= openMin {
+				return nil, &entities.BadRequestError{Message: fmt.Sprintf("close time must be after open time on %s", time.Weekday(h.Weekday))}
+			}
+			hour.OpenTime = h.OpenTime
+			hour.CloseTime = h.CloseTime
+		}
+		hours = append(hours, hour)
+	}
+
+	if err := u.openingRepo.ReplaceOperatingHours(req.RoomID, hours); err != nil {
+		return nil, err
+	}
+	return u.GetOperatingHours(req.RoomID)
+}
+
+func (u *openingHoursUsecase) GetBlackoutDates(roomID int, startDate, endDate string) ([]entities.BlackoutDate, error) {
+	for _, d := range []string{startDate, endDate} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return nil, &entities.BadRequestError{Message: "invalid date format, use YYYY-MM-DD"}
+		}
+	}
+	return u.openingRepo.GetBlackoutDates(roomID, startDate, endDate)
+}
+
+func (u *openingHoursUsecase) CreateBlackoutDate(req entities.BlackoutDateRequest) (entities.BlackoutDate, error) {
+	if req.EndDate == "" {
+		req.EndDate = req.StartDate
+	}
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return entities.BlackoutDate{}, &entities.BadRequestError{Message: "invalid startDate format, use YYYY-MM-DD"}
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return entities.BlackoutDate{}, &entities.BadRequestError{Message: "invalid endDate format, use YYYY-MM-DD"}
+	}
+	if end.Before(start) {
+		return entities.BlackoutDate{}, &entities.BadRequestError{Message: "endDate cannot be before startDate"}
+	}
+	if req.RoomID > 0 {
+		if _, err := u.roomRepo.GetByID(req.RoomID); err != nil {
+			return entities.BlackoutDate{}, fmt.Errorf("room %w", entities.ErrNotFound)
+		}
+	}
+
+	return u.openingRepo.CreateBlackoutDate(entities.BlackoutDate{
+		RoomID: req.RoomID, StartDate: req.StartDate, EndDate: req.EndDate, Name: req.Name,
+	})
+}
+
+func (u *openingHoursUsecase) DeleteBlackoutDate(id int) error {
+	return u.openingRepo.DeleteBlackoutDate(id)
+}
+
+// roomOpening menghitung jam buka efektif room pada tanggal t (zona waktu bisnis):
+// tanggal blackout menutup seharian, selain itu mengikuti jam buka mingguan.
+func (u *reservationUsecase) roomOpening(roomID int, t time.Time) (entities.RoomOpening, error) {
+	loc := businessLocation()
+	local := t.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	opening := entities.RoomOpening{Date: day.Format("2006-01-02")}
+
+	blackouts, err := u.openingRepo.GetBlackoutDates(roomID, opening.Date, opening.Date)
+	if err != nil {
+		return opening, err
+	}
+	if len(blackouts) > 0 {
+		opening.Closed = true
+		opening.Reason = fmt.Sprintf("closed on %s (%s)", opening.Date, blackouts[0].Name)
+		return opening, nil
+	}
+
+	openAt, closeAt := day, day.AddDate(0, 0, 1)
+	hour, err := u.openingRepo.GetEffectiveOperatingHour(roomID, int(day.Weekday()))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// Tidak diatur = buka 24 jam
+	case err != nil:
+		return opening, err
+	case hour.Closed:
+		opening.Closed = true
+		opening.Reason = fmt.Sprintf("closed on %s", day.Weekday())
+		return opening, nil
+	default:
+		openMin, err := parseClock(hour.OpenTime)
+		if err != nil {
+			return opening, err
+		}
+		closeMin, err := parseClock(hour.CloseTime)
+		if err != nil {
+			return opening, err
+		}
+		openAt = day.Add(time.Duration(openMin) * time.Minute)
+		closeAt = day.Add(time.Duration(closeMin) * time.Minute)
+	}
+
+	opening.OpenTime = &openAt
+	opening.CloseTime = &closeAt
+	return opening, nil
+}
+
+// checkOpeningHours menolak jadwal yang jatuh di tanggal blackout atau di luar jam buka room
+func (u *reservationUsecase) checkOpeningHours(room entities.Room, start, end time.Time) error {
+	opening, err := u.roomOpening(room.ID, start)
+	if err != nil {
+		return err
+	}
+	if opening.Closed {
+		return &entities.ClosedError{RoomID: room.ID, Message: fmt.Sprintf("room %s is %s", room.Name, opening.Reason)}
+	}
+	if start.Before(*opening.OpenTime) || end.After(*opening.CloseTime) {
+		closeLabel := opening.CloseTime.Format("15:04")
+		if closeLabel == "00:00" {
+			closeLabel = "24:00"
+		}
+		return &entities.ClosedError{RoomID: room.ID, Message: fmt.Sprintf("room %s is only open %s - %s on %s",
+			room.Name, opening.OpenTime.Format("15:04"), closeLabel, opening.Date)}
+	}
+	return nil
+}
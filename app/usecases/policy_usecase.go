@@ -0,0 +1,182 @@
+package usecases
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/repositories"
+)
+
+type PolicyUsecase interface {
+	GetCancellationPolicies() ([]entities.CancellationPolicy, error)
+	SaveCancellationPolicy(req entities.CancellationPolicyRequest) (entities.CancellationPolicy, error)
+	GetBookingRules() ([]entities.BookingRule, error)
+	SaveBookingRule(req entities.BookingRuleRequest) (entities.BookingRule, error)
+}
+
+type policyUsecase struct {
+	policyRepo repositories.PolicyRepository
+}
+
+func NewPolicyUsecase(policyRepo repositories.PolicyRepository) PolicyUsecase {
+	return &policyUsecase{policyRepo: policyRepo}
+}
+
+func (u *policyUsecase) GetCancellationPolicies() ([]entities.CancellationPolicy, error) {
+	return u.policyRepo.GetCancellationPolicies()
+}
+
+func (u *policyUsecase) SaveCancellationPolicy(req entities.CancellationPolicyRequest) (entities.CancellationPolicy, error) {
+	return u.policyRepo.UpsertCancellationPolicy(entities.CancellationPolicy{
+		RoomType:         req.RoomType,
+		FreeCancelHours:  req.FreeCancelHours,
+		LateFeePercent:   req.LateFeePercent,
+		NoShowFeePercent: req.NoShowFeePercent,
+	})
+}
+
+// cancellationSettlement menghitung biaya cancel untuk detail yang dicancel berdasarkan
+// policy room type masing-masing (fallback ke policy global). billed = bagian total reservasi
+// (setelah diskon, service charge & pajak) milik detail tersebut, dibagi ke tiap detail sesuai
+// subtotalnya. Refund hanya untuk reservasi yang sudah dibayar, yaitu billed dikurangi biaya cancel.
+func (u *reservationUsecase) cancellationSettlement(details []entities.ReservationDetailData, billed float64, paid bool, now time.Time) (entities.CancellationSettlement, error) {
+	var settlement entities.CancellationSettlement
+
+	subtotal := 0.0
+	for _, d := range details {
+		subtotal += d.TotalRoom + d.TotalSnack
+	}
+	for _, d := range details {
+		lineTotal := billed / float64(len(details))
+		if subtotal > 0 {
+			lineTotal = billed * (d.TotalRoom + d.TotalSnack) / subtotal
+		}
+
+		// Room type snapshot saat booking, bukan tipe room sekarang
+		policy, err := u.policyRepo.GetCancellationPolicy(d.RoomType)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Tidak ada policy sama sekali = cancel gratis
+			continue
+		}
+		if err != nil {
+			return settlement, err
+		}
+		settlement.Fee += lineTotal * cancellationFeePercent(policy, d.StartAt, now) / 100
+	}
+
+	settlement.Fee = math.Round(settlement.Fee*100) / 100
+	if paid {
+		settlement.Refund = math.Round((billed-settlement.Fee)*100) / 100
+	}
+	return settlement, nil
+}
+
+// cancellationFeePercent: gratis sampai FreeCancelHours sebelum mulai, setelah itu
+// LateFeePercent, dan NoShowFeePercent jika jadwal sudah dimulai.
+func cancellationFeePercent(policy entities.CancellationPolicy, startAt, now time.Time) float64 {
+	switch {
+	case !now.Before(startAt):
+		return policy.NoShowFeePercent
+	case startAt.Sub(now) >= time.Duration(policy.FreeCancelHours)*time.Hour:
+		return 0
+	default:
+		return policy.LateFeePercent
+	}
+}
+
+func (u *policyUsecase) GetBookingRules() ([]entities.BookingRule, error) {
+	return u.policyRepo.GetBookingRules()
+}
+
+func (u *policyUsecase) SaveBookingRule(req entities.BookingRuleRequest) (entities.BookingRule, error) {
+	if req.MinDurationMinutes > 0 && req.MaxDurationMinutes > 0 && req.MaxDurationMinutes < req.MinDurationMinutes {
+		return entities.BookingRule{}, &entities.BadRequestError{Message: "maxDurationMinutes cannot be less than minDurationMinutes"}
+	}
+	return u.policyRepo.UpsertBookingRule(entities.BookingRule{
+		RoomType:                req.RoomType,
+		MinLeadMinutes:          req.MinLeadMinutes,
+		MaxHorizonDays:          req.MaxHorizonDays,
+		MinDurationMinutes:      req.MinDurationMinutes,
+		MaxDurationMinutes:      req.MaxDurationMinutes,
+		SlotMinutes:             req.SlotMinutes,
+		CapacityOverflowPercent: req.CapacityOverflowPercent,
+		RequiresApproval:        req.RequiresApproval,
+		ApprovalPriceThreshold:  req.ApprovalPriceThreshold,
+	})
+}
+
+// checkBookingRules mengevaluasi aturan booking room type tersebut (fallback ke aturan global)
+// sebelum cek availability. Semua aturan yang dilanggar dikembalikan sekaligus
+// sebagai *entities.ValidationError.
+func (u *reservationUsecase) checkBookingRules(room entities.Room, start, end time.Time, participant int) error {
+	rule, err := u.policyRepo.GetBookingRule(room.RoomType)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	var violations []entities.RuleViolation
+	violate := func(name, message string) {
+		violations = append(violations, entities.RuleViolation{RoomID: room.ID, Rule: name, Message: message})
+	}
+
+	now := time.Now()
+	if !end.After(start) {
+		violate("time_range", "end time must be after start time")
+	}
+	if start.Before(now.Add(time.Duration(rule.MinLeadMinutes) * time.Minute)) {
+		if rule.MinLeadMinutes > 0 {
+			violate("min_lead_time", fmt.Sprintf("room %s must be booked at least %d minutes in advance", room.Name, rule.MinLeadMinutes))
+		} else {
+			violate("min_lead_time", "start time must be in the future")
+		}
+	}
+	if rule.MaxHorizonDays > 0 && start.After(now.AddDate(0, 0, rule.MaxHorizonDays)) {
+		violate("max_horizon", fmt.Sprintf("room %s can only be booked up to %d days in advance", room.Name, rule.MaxHorizonDays))
+	}
+
+	if limit := capacityLimit(room.Capacity, rule.CapacityOverflowPercent); participant > limit {
+		if limit > room.Capacity {
+			violate("capacity", fmt.Sprintf("room %s fits at most %d participants (capacity %d + %d%% tolerance), requested %d",
+				room.Name, limit, room.Capacity, rule.CapacityOverflowPercent, participant))
+		} else {
+			violate("capacity", fmt.Sprintf("room %s fits at most %d participants, requested %d", room.Name, limit, participant))
+		}
+	}
+
+	// Durasi & kelipatan slot hanya dicek jika rentang waktunya valid
+	if end.After(start) {
+		duration := int(end.Sub(start).Minutes())
+		if rule.MinDurationMinutes > 0 && duration < rule.MinDurationMinutes {
+			violate("min_duration", fmt.Sprintf("room %s must be booked for at least %d minutes", room.Name, rule.MinDurationMinutes))
+		}
+		if rule.MaxDurationMinutes > 0 && duration > rule.MaxDurationMinutes {
+			violate("max_duration", fmt.Sprintf("room %s can be booked for at most %d minutes", room.Name, rule.MaxDurationMinutes))
+		}
+		if rule.SlotMinutes > 0 && (!alignedToSlot(start, rule.SlotMinutes) || !alignedToSlot(end, rule.SlotMinutes)) {
+			violate("slot_alignment", fmt.Sprintf("start and end time must be aligned to %d-minute slots", rule.SlotMinutes))
+		}
+	}
+
+	if len(violations) > 0 {
+		return &entities.ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// capacityLimit: jumlah peserta maksimal = kapasitas + toleransi (dibulatkan ke bawah)
+func capacityLimit(capacity, overflowPercent int) int {
+	return capacity + capacity*overflowPercent/100
+}
+
+// alignedToSlot: jam (zona waktu bisnis) jatuh tepat di kelipatan slot menit
+func alignedToSlot(t time.Time, slotMinutes int) bool {
+	local := t.In(businessLocation())
+	if local.Second() != 0 || local.Nanosecond() != 0 {
+		return false
+	}
+	return (local.Hour()*60+local.Minute())%slotMinutes == 0
+}
@@ -0,0 +1,270 @@
+package usecases
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/repositories"
+)
+
+type PricingUsecase interface {
+	GetPricingRules() ([]entities.PricingRule, error)
+	CreatePricingRule(req entities.PricingRuleRequest) (entities.PricingRule, error)
+	UpdatePricingRule(id int, req entities.PricingRuleRequest) (entities.PricingRule, error)
+	DeletePricingRule(id int) error
+}
+
+type pricingUsecase struct {
+	pricingRepo repositories.PricingRepository
+}
+
+func NewPricingUsecase(pricingRepo repositories.PricingRepository) PricingUsecase {
+	return &pricingUsecase{pricingRepo: pricingRepo}
+}
+
+func (u *pricingUsecase) GetPricingRules() ([]entities.PricingRule, error) {
+	return u.pricingRepo.GetPricingRules()
+}
+
+func (u *pricingUsecase) CreatePricingRule(req entities.PricingRuleRequest) (entities.PricingRule, error) {
+	rule, err := pricingRuleFromRequest(req)
+	if err != nil {
+		return rule, err
+	}
+	return u.pricingRepo.CreatePricingRule(rule)
+}
+
+func (u *pricingUsecase) UpdatePricingRule(id int, req entities.PricingRuleRequest) (entities.PricingRule, error) {
+	rule, err := pricingRuleFromRequest(req)
+	if err != nil {
+		return rule, err
+	}
+	rule.ID = id
+	return u.pricingRepo.UpdatePricingRule(rule)
+}
+
+func (u *pricingUsecase) DeletePricingRule(id int) error {
+	return u.pricingRepo.DeletePricingRule(id)
+}
+
+// pricingRuleFromRequest memvalidasi field sesuai kind, field yang tidak dipakai kind tersebut dikosongkan
+func pricingRuleFromRequest(req entities.PricingRuleRequest) (entities.PricingRule, error) {
+	rule := entities.PricingRule{Name: req.Name, Kind: req.Kind, RoomType: req.RoomType, Weekdays: []int{}}
+
+	switch req.Kind {
+	case "time_band":
+		startMin, err := parseClock(req.StartTime)
+		if err != nil {
+			return rule, err
+		}
+		endMin, err := parseClock(req.EndTime)
+		if err != nil {
+			return rule, err
+		}
+		if endMin <= startMin {
+			return rule, &entities.BadRequestError{Message: "endTime must be after startTime"}
+		}
+		if req.Multiplier <= 0 {
+			return rule, &entities.BadRequestError{Message: "multiplier must be greater than 0"}
+		}
+		seen := map[int]bool{}
+		for _, w := range req.Weekdays {
+			if !seen[w] {
+				seen[w] = true
+				rule.Weekdays = append(rule.Weekdays, w)
+			}
+		}
+		sort.Ints(rule.Weekdays)
+		rule.StartTime, rule.EndTime, rule.Multiplier = req.StartTime, req.EndTime, req.Multiplier
+	case "weekend":
+		if req.Multiplier <= 0 {
+			return rule, &entities.BadRequestError{Message: "multiplier must be greater than 0"}
+		}
+		rule.Multiplier = req.Multiplier
+	case "holiday":
+		if req.EndDate == "" {
+			req.EndDate = req.StartDate
+		}
+		start, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return rule, &entities.BadRequestError{Message: "invalid startDate format, use YYYY-MM-DD"}
+		}
+		end, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return rule, &entities.BadRequestError{Message: "invalid endDate format, use YYYY-MM-DD"}
+		}
+		if end.Before(start) {
+			return rule, &entities.BadRequestError{Message: "endDate cannot be before startDate"}
+		}
+		if req.Multiplier <= 0 {
+			return rule, &entities.BadRequestError{Message: "multiplier must be greater than 0"}
+		}
+		rule.StartDate, rule.EndDate, rule.Multiplier = req.StartDate, req.EndDate, req.Multiplier
+	case "package":
+		if req.DurationMinutes <= 0 || req.PackagePrice <= 0 {
+			return rule, &entities.BadRequestError{Message: "durationMinutes and packagePrice are required for package"}
+		}
+		rule.DurationMinutes, rule.PackagePrice, rule.Multiplier = req.DurationMinutes, req.PackagePrice, 1
+	case "long_booking":
+		if req.DurationMinutes <= 0 || req.DiscountPercent <= 0 {
+			return rule, &entities.BadRequestError{Message: "durationMinutes and discountPercent are required for long_booking"}
+		}
+		rule.DurationMinutes, rule.DiscountPercent, rule.Multiplier = req.DurationMinutes, req.DiscountPercent, 1
+	default:
+		return rule, &entities.BadRequestError{Message: fmt.Sprintf("unknown pricing rule kind %q", req.Kind)}
+	}
+	return rule, nil
+}
+
+// priceRoom menghitung harga room dengan pricing rule room type tersebut.
+// Booking dipotong per segmen waktu (pergantian hari & batas jam peak) di zona waktu bisnis:
+// multiplier time_band / weekend / holiday yang berlaku di segmen dijumlahkan persentasenya
+// (1.5 = +50%, 0.8 = -20%). Setelah itu harga paket dipakai jika lebih murah, atau
+// diskon booking panjang (terbesar) jika tidak ada paket. Baris rincian selalu berjumlah total.
+func (u *reservationUsecase) priceRoom(roomType string, pricePerHour float64, start, end time.Time) (float64, []entities.PriceLine, error) {
+	rules, err := u.pricingRepo.GetPricingRulesForRoomType(roomType)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	durationMins := int(end.Sub(start).Minutes())
+	lines := []entities.PriceLine{{Name: "base price", Kind: "base", Minutes: durationMins, Amount: roundPrice(roomPrice(pricePerHour, durationMins))}}
+
+	// Penyesuaian per rule, urutan mengikuti urutan rule
+	adjustments := map[int]*entities.PriceLine{}
+	var order []int
+	loc := businessLocation()
+	for _, seg := range priceSegments(rules, start, end) {
+		local := seg[0].In(loc)
+		minutes := seg[1].Sub(seg[0]).Minutes()
+		segBase := pricePerHour * minutes / 60
+		for _, rule := range rules {
+			if !rateRuleApplies(rule, local) {
+				continue
+			}
+			line, ok := adjustments[rule.ID]
+			if !ok {
+				line = &entities.PriceLine{RuleID: rule.ID, Name: rule.Name, Kind: rule.Kind, Multiplier: rule.Multiplier}
+				adjustments[rule.ID] = line
+				order = append(order, rule.ID)
+			}
+			line.Minutes += int(minutes)
+			line.Amount += segBase * (rule.Multiplier - 1)
+		}
+	}
+	total := lines[0].Amount
+	for _, id := range order {
+		line := *adjustments[id]
+		line.Amount = roundPrice(line.Amount)
+		// Harga tidak bisa minus karena multiplier off-peak
+		if total+line.Amount < 0 {
+			line.Amount = -total
+		}
+		total += line.Amount
+		lines = append(lines, line)
+	}
+
+	// Paket (half-day / full-day): pilih paket termurah yang mencakup durasi booking
+	var pkg *entities.PricingRule
+	for i, rule := range rules {
+		if rule.Kind == "package" && durationMins <= rule.DurationMinutes && rule.PackagePrice < total &&
+			(pkg == nil || rule.PackagePrice < pkg.PackagePrice) {
+			pkg = &rules[i]
+		}
+	}
+	if pkg != nil {
+		amount := roundPrice(pkg.PackagePrice - total)
+		lines = append(lines, entities.PriceLine{RuleID: pkg.ID, Name: pkg.Name, Kind: pkg.Kind, Minutes: durationMins, Amount: amount})
+		total += amount
+		return roundPrice(total), lines, nil
+	}
+
+	// Diskon booking panjang: ambil diskon terbesar yang memenuhi durasi minimal
+	var discount *entities.PricingRule
+	for i, rule := range rules {
+		if rule.Kind == "long_booking" && durationMins >= rule.DurationMinutes &&
+			(discount == nil || rule.DiscountPercent > discount.DiscountPercent) {
+			discount = &rules[i]
+		}
+	}
+	if discount != nil {
+		amount := -roundPrice(total * discount.DiscountPercent / 100)
+		lines = append(lines, entities.PriceLine{RuleID: discount.ID, Name: discount.Name, Kind: discount.Kind, Minutes: durationMins, Amount: amount})
+		total += amount
+	}
+	return roundPrice(total), lines, nil
+}
+
+// priceSegments memotong [start, end) di setiap pergantian hari dan batas jam time_band,
+// sehingga tiap segmen hanya terkena satu kombinasi rule
+func priceSegments(rules []entities.PricingRule, start, end time.Time) [][2]time.Time {
+	loc := businessLocation()
+	local := start.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	points := []time.Time{start, end}
+	addPoint := func(t time.Time) {
+		if t.After(start) && t.Before(end) {
+			points = append(points, t)
+		}
+	}
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		addPoint(day)
+		for _, rule := range rules {
+			if rule.Kind != "time_band" {
+				continue
+			}
+			for _, clock := range []string{rule.StartTime, rule.EndTime} {
+				if minutes, err := parseClock(clock); err == nil {
+					addPoint(day.Add(time.Duration(minutes) * time.Minute))
+				}
+			}
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Before(points[j]) })
+
+	var segments [][2]time.Time
+	for i := 1; i < len(points); i++ {
+		if points[i].After(points[i-1]) {
+			segments = append(segments, [2]time.Time{points[i-1], points[i]})
+		}
+	}
+	return segments
+}
+
+// rateRuleApplies: apakah rule multiplier berlaku di awal segmen (waktu lokal bisnis)
+func rateRuleApplies(rule entities.PricingRule, local time.Time) bool {
+	switch rule.Kind {
+	case "time_band":
+		if len(rule.Weekdays) > 0 && !containsInt(rule.Weekdays, int(local.Weekday())) {
+			return false
+		}
+		startMin, errStart := parseClock(rule.StartTime)
+		endMin, errEnd := parseClock(rule.EndTime)
+		minuteOfDay := local.Hour()*60 + local.Minute()
+		return errStart == nil && errEnd == nil && minuteOfDay >= startMin && minuteOfDay < endMin
+	case "weekend":
+		return local.Weekday() == time.Saturday || local.Weekday() == time.Sunday
+	case "holiday":
+		date := local.Format("2006-01-02")
+		return date >= rule.StartDate && date <= rule.EndDate
+	default:
+		return false
+	}
+}
+
+func containsInt(list []int, value int) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func roundPrice(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/utils"
+)
+
+// tentativeStatus: reservasi yang belum dikonfirmasi (hold / pending) bisa dicancel tanpa biaya
+func tentativeStatus(status string) bool {
+	return status == "hold" || status == "pending"
+}
+
+// approvalReason mengecek apakah reservasi butuh approval admin berdasarkan booking rule
+// room type (requires_approval) dan batas total harga (approval_price_threshold).
+// String kosong = tidak butuh approval.
+func (u *reservationUsecase) approvalReason(details []entities.ReservationDetailData, total float64) (string, error) {
+	var reasons []string
+	seen := map[string]bool{}
+	add := func(reason string) {
+		if !seen[reason] {
+			seen[reason] = true
+			reasons = append(reasons, reason)
+		}
+	}
+
+	for _, d := range details {
+		room, err := u.roomRepo.GetByID(d.RoomID)
+		if err != nil {
+			return "", fmt.Errorf("room %w", entities.ErrNotFound)
+		}
+		rule, err := u.policyRepo.GetBookingRule(room.RoomType)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return "", err
+		}
+		if rule.RequiresApproval {
+			add(fmt.Sprintf("room %s (%s) requires approval", room.Name, room.RoomType))
+		}
+		if rule.ApprovalPriceThreshold > 0 && total > rule.ApprovalPriceThreshold {
+			add(fmt.Sprintf("total %.0f is above the approval threshold %.0f", total, rule.ApprovalPriceThreshold))
+		}
+	}
+	return strings.Join(reasons, "; "), nil
+}
+
+// GetPendingApprovals: antrian approval untuk admin beserta konteks bentrok tiap slot,
+// yaitu booking lain di room yang sama pada hari itu dan jumlah antrian waitlist
+func (u *reservationUsecase) GetPendingApprovals() ([]entities.PendingApproval, error) {
+	approvals, err := u.resRepo.GetPendingApprovals()
+	if err != nil {
+		return nil, err
+	}
+
+	loc := businessLocation()
+	for i := range approvals {
+		details, err := u.resRepo.GetDetails(approvals[i].ReservationID)
+		if err != nil {
+			return nil, err
+		}
+
+		approvals[i].Rooms = make([]entities.PendingApprovalRoom, 0, len(details))
+		for _, d := range details {
+			local := d.StartAt.In(loc)
+			dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+			schedules, err := u.resRepo.GetReservationsByRoomID(d.RoomID, dayStart, dayStart.AddDate(0, 0, 1), false)
+			if err != nil {
+				return nil, err
+			}
+			sameDay := []entities.RoomSchedule{}
+			for _, s := range schedules {
+				if s.ID != approvals[i].ReservationID {
+					sameDay = append(sameDay, s)
+				}
+			}
+
+			waiting, err := u.waitlistRepo.GetWaiting(d.RoomID, d.StartAt, d.EndAt)
+			if err != nil {
+				return nil, err
+			}
+
+			approvals[i].Rooms = append(approvals[i].Rooms, entities.PendingApprovalRoom{
+				DetailID: d.ID, RoomID: d.RoomID, RoomName: d.RoomName,
+				StartTime: d.StartAt, EndTime: d.EndAt, Participant: d.TotalParticipants,
+				SameDay: sameDay, Waitlisted: len(waiting),
+			})
+		}
+	}
+	return approvals, nil
+}
+
+// ApproveReservation: pending -> booked, komentar admin dicatat di status history
+func (u *reservationUsecase) ApproveReservation(id, adminID int, comment string) error {
+	if err := u.requirePending(id); err != nil {
+		return err
+	}
+	return u.UpdateStatus(id, adminID, "booked", "admin", comment)
+}
+
+// RejectReservation: pending -> cancel tanpa biaya, slot dilepas ke waitlist. Komentar wajib.
+func (u *reservationUsecase) RejectReservation(id, adminID int, comment string) error {
+	if strings.TrimSpace(comment) == "" {
+		return &entities.BadRequestError{Message: "comment is required to reject a reservation"}
+	}
+	if err := u.requirePending(id); err != nil {
+		return err
+	}
+	return u.UpdateStatus(id, adminID, "cancel", "admin", comment)
+}
+
+func (u *reservationUsecase) requirePending(id int) error {
+	currentData, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	if currentData.Status != "pending" {
+		return &entities.BadRequestError{Message: fmt.Sprintf("reservation is %s, not pending approval", currentData.Status)}
+	}
+	return nil
+}
+
+// notifyApprovalDecision mengirim hasil approval ke email pemohon
+func (u *reservationUsecase) notifyApprovalDecision(data entities.ReservationHistoryData, approved bool, comment string) {
+	email, err := u.resRepo.GetRequesterEmail(data.ID)
+	if err != nil || email == "" {
+		return
+	}
+
+	subject, decision := "Reservation Approved", "approved"
+	if !approved {
+		subject, decision = "Reservation Rejected", "rejected"
+	}
+	body := fmt.Sprintf(`
+    <h1>%s</h1>
+    <p>Hi %s,</p>
+    <p>Your reservation #%d has been %s by the admin.</p>
+    `, subject, html.EscapeString(data.Name), data.ID, decision)
+	if comment != "" {
+		body += fmt.Sprintf(`    <p>Comment: %s</p>
+    `, html.EscapeString(comment))
+	}
+
+	go func() {
+		if err := utils.SendNotificationEmail(email, subject, body); err != nil {
+			log.Printf("approval: failed to send email for reservation %d: %v", data.ID, err)
+		}
+	}()
+}
@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+)
+
+// Default jendela check-in jika env tidak diisi
+const (
+	defaultCheckInOpenMinutes = 15
+	defaultNoShowGraceMinutes = 15
+)
+
+// CHECKIN_OPEN_MINUTES: check-in dibuka N menit sebelum start_at
+func checkInOpenMinutes() int {
+	return envMinutes("CHECKIN_OPEN_MINUTES", defaultCheckInOpenMinutes)
+}
+
+// NO_SHOW_GRACE_MINUTES: batas check-in setelah start_at sebelum ditandai no-show
+func noShowGraceMinutes() int {
+	return envMinutes("NO_SHOW_GRACE_MINUTES", defaultNoShowGraceMinutes)
+}
+
+func envMinutes(key string, fallback int) int {
+	minutes, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || minutes < 0 {
+		return fallback
+	}
+	return minutes
+}
+
+// CheckIn oleh pemilik reservasi (atau admin) untuk room yang sedang dalam jendela check-in
+func (u *reservationUsecase) CheckIn(id, userID int, userRole string) (entities.CheckInResult, error) {
+	result := entities.CheckInResult{ReservationID: id}
+
+	currentData, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return result, fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	if userRole != "admin" && currentData.UserID != userID {
+		return result, &entities.ForbiddenError{Message: "you can only check in to your own reservation"}
+	}
+
+	details, err := u.resRepo.CheckIn(id, checkInOpenMinutes())
+	if err != nil {
+		return result, err
+	}
+	if len(details) == 0 {
+		return result, &entities.BadRequestError{Message: "no room is open for check-in right now"}
+	}
+	return checkInResult(details), nil
+}
+
+// CheckInByRoomToken: check-in dengan scan QR di room (token sebagai bukti hadir di lokasi)
+func (u *reservationUsecase) CheckInByRoomToken(token string) (entities.CheckInResult, error) {
+	details, err := u.resRepo.CheckInByRoomToken(token, checkInOpenMinutes())
+	if err != nil {
+		return entities.CheckInResult{}, err
+	}
+	if len(details) == 0 {
+		return entities.CheckInResult{}, &entities.BadRequestError{Message: "no reservation is open for check-in in this room right now"}
+	}
+	return checkInResult(details), nil
+}
+
+func checkInResult(details []entities.ReservationDetailData) entities.CheckInResult {
+	result := entities.CheckInResult{ReservationID: details[0].ReservationID, CheckedInAt: time.Now()}
+	for _, d := range details {
+		result.DetailIDs = append(result.DetailIDs, d.ID)
+	}
+	return result
+}
+
+// ReleaseNoShows menandai detail yang tidak check-in sebagai no-show. Sisa slot
+// (mulai sekarang sampai end_at) langsung kosong dan ditawarkan ke waitlist.
+func (u *reservationUsecase) ReleaseNoShows() (int, error) {
+	details, err := u.resRepo.MarkNoShows(noShowGraceMinutes())
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var remainder []entities.ReservationDetailData
+	for _, d := range details {
+		if d.EndAt.After(now) {
+			d.StartAt = now
+			remainder = append(remainder, d)
+		}
+	}
+	u.processWaitlist(remainder)
+
+	return len(details), nil
+}
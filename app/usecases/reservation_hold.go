@@ -0,0 +1,122 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/utils"
+)
+
+// Lama hold default jika HOLD_DURATION_HOURS tidak diisi
+const defaultHoldDuration = 24 * time.Hour
+
+func holdDuration() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("HOLD_DURATION_HOURS"))
+	if err != nil || hours <= 0 {
+		return defaultHoldDuration
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+func holdExpired(data entities.ReservationHistoryData) bool {
+	return data.Status == "hold" && data.HoldExpiresAt != nil && !data.HoldExpiresAt.After(time.Now())
+}
+
+// CreateHold membuat reservasi berstatus hold. Slot langsung diblokir seperti booking biasa
+// sampai masa hold habis, lalu dilepas oleh worker (RunHoldExpiryWorker).
+func (u *reservationUsecase) CreateHold(req entities.ReservationRequest) (time.Time, error) {
+	if req.Recurrence != nil {
+		return time.Time{}, &entities.BadRequestError{Message: "hold is not supported for recurring reservations"}
+	}
+
+	_, expiresAt, err := u.createHold(req, holdDuration())
+	var conflictErr *entities.ConflictError
+	if errors.As(err, &conflictErr) {
+		for _, r := range req.Rooms {
+			if r.ID == conflictErr.RoomID {
+				return time.Time{}, u.conflictWithSuggestions(r)
+			}
+		}
+	}
+	return expiresAt, err
+}
+
+func (u *reservationUsecase) createHold(req entities.ReservationRequest, duration time.Duration) (int, time.Time, error) {
+	resData, detData, err := u.buildReservation(req, req.Rooms)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	resData.StatusReservation = "hold"
+	resData.HoldExpiresAt = time.Now().Add(duration)
+
+	reservationID, err := u.resRepo.Create(resData, detData)
+	return reservationID, resData.HoldExpiresAt, err
+}
+
+// ReleaseExpiredHolds melepas hold yang sudah expired, mengirim email ke pemegang hold
+// lalu menawarkan slot yang kosong ke waitlist berikutnya
+func (u *reservationUsecase) ReleaseExpiredHolds() (int, error) {
+	holds, err := u.resRepo.ReleaseExpiredHolds()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, h := range holds {
+		if err := u.waitlistRepo.ResolveOffer(h.ReservationID, "expired"); err != nil {
+			log.Printf("failed to expire waitlist offer for reservation %d: %v", h.ReservationID, err)
+		}
+		if details, err := u.resRepo.GetDetails(h.ReservationID); err == nil {
+			u.processWaitlist(details)
+		}
+
+		if h.Email == "" {
+			continue
+		}
+		body := fmt.Sprintf(`
+    <h1>Reservation Hold Expired</h1>
+    <p>Hi %s,</p>
+    <p>Your hold for reservation #%d expired at %s and the room has been released.</p>
+    <p>Please create a new reservation if you still need the room.</p>
+    `, html.EscapeString(h.ContactName), h.ReservationID, h.ExpiredAt.Format("02 Jan 2006 15:04"))
+
+		// Dikirim di goroutine agar SMTP yang lambat tidak menahan worker
+		go func(h entities.ExpiredHold, body string) {
+			if err := utils.SendNotificationEmail(h.Email, "Reservation Hold Expired", body); err != nil {
+				log.Printf("failed to send hold expiry email for reservation %d: %v", h.ReservationID, err)
+			}
+		}(h, body)
+	}
+	return len(holds), nil
+}
+
+// RunHoldExpiryWorker menjalankan ReleaseExpiredHolds secara berkala (dipanggil sebagai goroutine)
+func RunHoldExpiryWorker(u ReservationUsecase, interval time.Duration) {
+	runPeriodically("hold expiry worker", interval, u.ReleaseExpiredHolds)
+}
+
+// RunNoShowWorker menjalankan ReleaseNoShows secara berkala (dipanggil sebagai goroutine)
+func RunNoShowWorker(u ReservationUsecase, interval time.Duration) {
+	runPeriodically("no-show worker", interval, u.ReleaseNoShows)
+}
+
+func runPeriodically(name string, interval time.Duration, job func() (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		processed, err := job()
+		if err != nil {
+			log.Printf("%s: %v", name, err)
+			continue
+		}
+		if processed > 0 {
+			log.Printf("%s: processed %d reservation(s)", name, processed)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package usecases
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/utils"
+)
+
+// invoiceStatuses: invoice bisa dibuat setelah booking dikonfirmasi, receipt hanya setelah dibayar
+var invoiceStatuses = map[string][]string{
+	"invoice": {"booked", "paid", "refunded"},
+	"receipt": {"paid", "refunded"},
+}
+
+// GetInvoice membuat PDF invoice / receipt reservasi (pemilik atau admin).
+// Nomor diterbitkan saat pertama kali dibuat, PDF berikutnya memakai nomor yang sama.
+func (u *reservationUsecase) GetInvoice(id, userID int, userRole, kind string) (entities.InvoiceFile, error) {
+	current, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return entities.InvoiceFile{}, fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	if userRole != "admin" && current.UserID != userID {
+		return entities.InvoiceFile{}, &entities.ForbiddenError{Message: "you can only view your own reservation"}
+	}
+	return u.buildInvoiceFile(current, kind)
+}
+
+// EmailInvoice mengirim PDF invoice / receipt ke email pemilik reservasi, mengembalikan alamat tujuan
+func (u *reservationUsecase) EmailInvoice(id, userID int, userRole, kind string) (string, error) {
+	file, err := u.GetInvoice(id, userID, userRole, kind)
+	if err != nil {
+		return "", err
+	}
+	email, err := u.resRepo.GetRequesterEmail(id)
+	if err != nil || email == "" {
+		return "", &entities.BadRequestError{Message: "reservation owner has no email address"}
+	}
+	if err := sendInvoiceEmail(id, email, file); err != nil {
+		return "", fmt.Errorf("failed to send email: %w", err)
+	}
+	return email, nil
+}
+
+// sendReceipt: receipt dikirim otomatis ke pemilik reservasi setelah status paid
+func (u *reservationUsecase) sendReceipt(id int) {
+	email, err := u.resRepo.GetRequesterEmail(id)
+	if err != nil || email == "" {
+		return
+	}
+	go func() {
+		current, err := u.resRepo.GetByID(id)
+		if err != nil {
+			log.Printf("invoice: failed to load reservation %d: %v", id, err)
+			return
+		}
+		file, err := u.buildInvoiceFile(current, "receipt")
+		if err != nil {
+			log.Printf("invoice: failed to generate receipt for reservation %d: %v", id, err)
+			return
+		}
+		if err := sendInvoiceEmail(id, email, file); err != nil {
+			log.Printf("invoice: failed to send receipt for reservation %d: %v", id, err)
+		}
+	}()
+}
+
+func sendInvoiceEmail(id int, email string, file entities.InvoiceFile) error {
+	title := "Invoice"
+	if file.Kind == "receipt" {
+		title = "Receipt"
+	}
+	subject := fmt.Sprintf("%s %s - Reservation #%d", title, file.Number, id)
+	body := fmt.Sprintf(`
+    <h1>%s %s</h1>
+    <p>Please find attached the %s for your reservation #%d.</p>
+    `, title, file.Number, strings.ToLower(title), id)
+	return utils.SendEmailWithAttachment(email, subject, body, file.FileName, "application/pdf", file.Content)
+}
+
+func (u *reservationUsecase) buildInvoiceFile(current entities.ReservationHistoryData, kind string) (entities.InvoiceFile, error) {
+	file := entities.InvoiceFile{Kind: kind}
+	statuses, ok := invoiceStatuses[kind]
+	if !ok {
+		return file, &entities.BadRequestError{Message: "type must be invoice or receipt"}
+	}
+	if !containsString(statuses, current.Status) {
+		return file, &entities.BadRequestError{Message: fmt.Sprintf("%s is only available for %s reservation, current status is %s", kind, strings.Join(statuses, " / "), current.Status)}
+	}
+
+	lines, err := u.invoiceDocumentLines(current)
+	if err != nil {
+		return file, err
+	}
+	// Receipt selalu merujuk ke nomor invoice
+	invoice, err := u.invoiceRepo.IssueNumber(current.ID, "invoice")
+	if err != nil {
+		return file, err
+	}
+	number := invoice
+	if kind == "receipt" {
+		if number, err = u.invoiceRepo.IssueNumber(current.ID, "receipt"); err != nil {
+			return file, err
+		}
+	}
+
+	loc := businessLocation()
+	doc := utils.InvoiceDocument{
+		Title:  strings.ToUpper(kind),
+		Issuer: invoiceIssuer(),
+		BillTo: nonEmpty(current.Company, current.Name, current.PhoneNumber),
+		Lines:  lines,
+		Notes: []string{
+			fmt.Sprintf("Reservation #%d. Amounts in IDR.", current.ID),
+			"This document is generated by the system and is valid without signature.",
+		},
+	}
+	if kind == "receipt" {
+		doc.Meta = append(doc.Meta, [2]string{"Receipt No", number.Number})
+	}
+	doc.Meta = append(doc.Meta,
+		[2]string{"Invoice No", invoice.Number},
+		[2]string{"Date", number.IssuedAt.In(loc).Format("02 Jan 2006")},
+		[2]string{"Reservation", fmt.Sprintf("#%d", current.ID)},
+		[2]string{"Status", current.Status},
+	)
+
+	doc.Totals = []utils.InvoiceDocumentTotal{{Label: "Subtotal", Amount: current.SubTotalRoom + current.SubTotalSnack}}
+	if current.Discount > 0 {
+		doc.Totals = append(doc.Totals, utils.InvoiceDocumentTotal{Label: "Discount", Amount: -current.Discount})
+	}
+	if current.ServiceCharge > 0 {
+		doc.Totals = append(doc.Totals, utils.InvoiceDocumentTotal{Label: "Service Charge", Amount: current.ServiceCharge})
+	}
+	if current.Tax > 0 {
+		doc.Totals = append(doc.Totals, utils.InvoiceDocumentTotal{Label: "Tax", Amount: current.Tax})
+	}
+	doc.Totals = append(doc.Totals, utils.InvoiceDocumentTotal{Label: "Total", Amount: current.Total, Bold: true})
+	if current.CancellationFee > 0 {
+		doc.Totals = append(doc.Totals, utils.InvoiceDocumentTotal{Label: "Cancellation Fee", Amount: current.CancellationFee})
+	}
+	if current.RefundAmount > 0 {
+		doc.Totals = append(doc.Totals, utils.InvoiceDocumentTotal{Label: "Refunded", Amount: -current.RefundAmount})
+	}
+
+	if kind == "receipt" {
+		doc.Stamp = "PAID"
+		if paidAt, ok := u.paidAt(current.ID); ok {
+			doc.Stamp += " - " + paidAt.In(loc).Format("02 Jan 2006 15:04")
+		}
+		if current.Status == "refunded" {
+			doc.Stamp += " (REFUNDED)"
+		}
+	}
+
+	file.Number = number.Number
+	file.FileName = strings.ReplaceAll(number.Number, "/", "-") + ".pdf"
+	file.Content = utils.BuildInvoicePDF(doc)
+	return file, nil
+}
+
+// invoiceDocumentLines: baris invoice yang tersimpan, atau dari reservation_details untuk reservasi lama
+func (u *reservationUsecase) invoiceDocumentLines(current entities.ReservationHistoryData) ([]utils.InvoiceDocumentLine, error) {
+	lines := current.InvoiceLines
+	if len(lines) == 0 {
+		details, err := u.resRepo.GetDetails(current.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range details {
+			lines = append(lines, detailInvoiceLines(d, nil)...)
+		}
+		if current.Discount > 0 {
+			lines = append(lines, entities.InvoiceLine{Type: "discount", Description: "Discount", Quantity: 1, UnitPrice: -current.Discount, Amount: -current.Discount})
+		}
+	}
+
+	var result []utils.InvoiceDocumentLine
+	for _, l := range lines {
+		quantity := strconv.FormatFloat(l.Quantity, 'f', -1, 64)
+		if l.Unit != "" {
+			quantity += " " + l.Unit
+		}
+		// Service / pajak: harga satuan = dasar pengenaan, qty = tarif
+		if l.Type == "service" || l.Type == "tax" {
+			quantity = strconv.FormatFloat(l.Rate, 'f', -1, 64) + "%"
+		}
+		result = append(result, utils.InvoiceDocumentLine{Description: l.Description, Quantity: quantity, UnitPrice: l.UnitPrice, Amount: l.Amount})
+	}
+	return result, nil
+}
+
+// paidAt: waktu terakhir status berubah ke paid
+func (u *reservationUsecase) paidAt(id int) (time.Time, bool) {
+	histories, err := u.resRepo.GetStatusHistories(id)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var paidAt time.Time
+	for _, h := range histories {
+		if h.ToStatus == "paid" && h.CreatedAt.After(paidAt) {
+			paidAt = h.CreatedAt
+		}
+	}
+	return paidAt, !paidAt.IsZero()
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// invoiceIssuer: data penerbit dari env, INVOICE_ISSUER_ADDRESS dipisah "|" per baris
+func invoiceIssuer() []string {
+	name := os.Getenv("INVOICE_ISSUER_NAME")
+	if name == "" {
+		name = "E-Meeting"
+	}
+	issuer := []string{name}
+	if address := os.Getenv("INVOICE_ISSUER_ADDRESS"); address != "" {
+		for _, line := range strings.Split(address, "|") {
+			issuer = append(issuer, strings.TrimSpace(line))
+		}
+	}
+	if taxID := os.Getenv("INVOICE_TAX_ID"); taxID != "" {
+		issuer = append(issuer, "NPWP: "+taxID)
+	}
+	return issuer
+}
@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+
+	"BE-E-Meeting/app/entities"
+)
+
+// Modify mengubah jadwal/room/participant/snack per baris reservation_details.
+// Harga baris yang diubah dihitung ulang lewat buildRoomLine (sama dengan Calculate),
+// slot lama milik reservasi ini diabaikan saat cek bentrok. Preview hanya
+// mengembalikan selisih harga tanpa menyimpan perubahan.
+func (u *reservationUsecase) Modify(id, userID int, userRole string, req entities.ModifyReservationRequest) (entities.ModifyReservationResult, error) {
+	result := entities.ModifyReservationResult{ReservationID: id}
+
+	current, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return result, fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	if userRole != "admin" && current.UserID != userID {
+		return result, &entities.ForbiddenError{Message: "you can only modify your own reservation"}
+	}
+	if current.Status != "booked" {
+		return result, &entities.BadRequestError{Message: fmt.Sprintf("cannot modify %s reservation", current.Status)}
+	}
+
+	details, err := u.resRepo.GetDetails(id)
+	if err != nil {
+		return result, err
+	}
+	before := append([]entities.ReservationDetailData(nil), details...)
+
+	// Index baris detail berdasarkan ID agar request bisa dicocokkan
+	index := make(map[int]int, len(details))
+	for i, d := range details {
+		index[d.ID] = i
+	}
+
+	changed := make(map[int]entities.RoomReservationRequest, len(req.Rooms))
+	for _, item := range req.Rooms {
+		i, ok := index[item.DetailID]
+		if !ok {
+			return result, &entities.BadRequestError{Message: fmt.Sprintf("detail %d does not belong to this reservation", item.DetailID)}
+		}
+		if _, dup := changed[item.DetailID]; dup {
+			return result, &entities.BadRequestError{Message: fmt.Sprintf("detail %d is listed more than once", item.DetailID)}
+		}
+		_, detail, err := u.buildRoomLine(item.RoomReservationRequest)
+		if err != nil {
+			return result, err
+		}
+		detail.ID = item.DetailID
+		detail.ReservationID = id
+		details[i] = detail
+		changed[item.DetailID] = item.RoomReservationRequest
+	}
+
+	// Diskon & tarif pajak mengikuti saat booking
+	snapshot, err := u.invoiceSnapshot(current)
+	if err != nil {
+		return result, err
+	}
+	afterData := entities.ReservationData{ID: id, Note: current.Notes}
+	snapshot.apply(&afterData, details)
+
+	// Sama seperti create: perubahan yang butuh approval memindahkan reservasi ke pending
+	reason, err := u.approvalReason(details, afterData.Total)
+	if err != nil {
+		return result, err
+	}
+	result.Status = "booked"
+	if reason != "" {
+		afterData.StatusReservation = "pending"
+		afterData.ApprovalReason = reason
+		result.Status = "pending"
+		result.ApprovalReason = reason
+	}
+
+	result.Before = historySummary(current)
+	result.After = priceSummary(afterData)
+	result.Diff = entities.PriceSummary{
+		SubTotalRoom:  result.After.SubTotalRoom - result.Before.SubTotalRoom,
+		SubTotalSnack: result.After.SubTotalSnack - result.Before.SubTotalSnack,
+		Discount:      result.After.Discount - result.Before.Discount,
+		ServiceCharge: result.After.ServiceCharge - result.Before.ServiceCharge,
+		Tax:           result.After.Tax - result.Before.Tax,
+		Total:         result.After.Total - result.Before.Total,
+	}
+	for _, d := range details {
+		result.Rooms = append(result.Rooms, calculationLine(d))
+	}
+
+	// Cek bentrok untuk baris yang diubah (slot milik reservasi ini diabaikan)
+	for _, item := range req.Rooms {
+		r := changed[item.DetailID]
+		available, err := u.resRepo.CheckAvailabilityExcept(r.ID, r.StartTime, r.EndTime, id)
+		if err != nil {
+			return result, err
+		}
+		if !available {
+			return result, u.conflictWithSuggestions(r)
+		}
+	}
+
+	if req.Preview {
+		return result, nil
+	}
+
+	change := entities.ReservationChangeData{
+		ReservationID: id, ChangedBy: userID, Reason: req.Reason,
+		BeforeTotal: current.Total, AfterTotal: afterData.Total,
+		Before: before, After: details,
+	}
+	occurrence := entities.ReservationOccurrenceData{Reservation: afterData, Details: details}
+
+	// Pengecekan final di dalam transaksi repository
+	err = u.resRepo.Reschedule([]entities.ReservationOccurrenceData{occurrence}, []entities.ReservationChangeData{change})
+	var conflictErr *entities.ConflictError
+	if errors.As(err, &conflictErr) {
+		for _, r := range changed {
+			if r.ID == conflictErr.RoomID {
+				return result, u.conflictWithSuggestions(r)
+			}
+		}
+	}
+	if err != nil {
+		return result, err
+	}
+
+	result.Applied = true
+	return result, nil
+}
+
+func (u *reservationUsecase) GetChangeHistories(id, userID int, userRole string) ([]entities.ReservationChangeHistory, error) {
+	currentData, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	if userRole != "admin" && currentData.UserID != userID {
+		return nil, &entities.ForbiddenError{Message: "you can only view your own reservation"}
+	}
+	return u.resRepo.GetChangeHistories(id)
+}
+
+func priceSummary(res entities.ReservationData) entities.PriceSummary {
+	return entities.PriceSummary{
+		SubTotalRoom: res.SubTotalRoom, SubTotalSnack: res.SubTotalSnack, Discount: res.DiscountAmount,
+		ServiceCharge: res.ServiceCharge, Tax: res.TaxAmount, Total: res.Total,
+	}
+}
+
+// calculationLine mengubah snapshot detail menjadi baris kalkulasi untuk response
+func calculationLine(d entities.ReservationDetailData) entities.RoomCalculationDetail {
+	line := entities.RoomCalculationDetail{
+		Name: d.RoomName, PricePerHour: d.RoomPrice,
+		SubTotalRoom: d.TotalRoom, SubTotalSnack: d.TotalSnack,
+		StartTime: d.StartAt, EndTime: d.EndAt,
+		Duration: d.DurationMinute, Participant: d.TotalParticipants,
+	}
+	line.Snacks = detailSnackLines(d)
+	line.Snack = firstSnack(line.Snacks)
+	return line
+}
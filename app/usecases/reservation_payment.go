@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+)
+
+// Lama intent pembayaran default jika PAYMENT_EXPIRY_MINUTES tidak diisi
+const defaultPaymentExpiry = time.Hour
+
+func paymentExpiry() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("PAYMENT_EXPIRY_MINUTES"))
+	if err != nil || minutes <= 0 {
+		return defaultPaymentExpiry
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// CreatePayment membuat payment intent sebesar total reservasi booked (pemilik atau admin).
+// Intent pending yang masih berlaku dengan nominal yang sama dipakai ulang, jadi aman dipanggil berulang.
+func (u *reservationUsecase) CreatePayment(id, userID int, userRole string) (entities.Payment, error) {
+	current, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return entities.Payment{}, fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	if userRole != "admin" && current.UserID != userID {
+		return entities.Payment{}, &entities.ForbiddenError{Message: "you can only pay your own reservation"}
+	}
+	if current.Status != "booked" {
+		return entities.Payment{}, &entities.BadRequestError{Message: fmt.Sprintf("only booked reservation can be paid, current status is %s", current.Status)}
+	}
+	if current.Total <= 0 {
+		return entities.Payment{}, &entities.BadRequestError{Message: "reservation has nothing to pay"}
+	}
+
+	pending, err := u.paymentRepo.GetPendingPayment(id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return entities.Payment{}, err
+	case pending.ExpiresAt != nil && !pending.ExpiresAt.After(time.Now()):
+		if err := u.paymentRepo.ExpirePayment(pending.ID, "payment has expired"); err != nil {
+			return entities.Payment{}, err
+		}
+	case math.Abs(pending.Amount-current.Total) >= 0.01:
+		// Total berubah (modify / partial cancel) setelah intent dibuat
+		if err := u.paymentRepo.ExpirePayment(pending.ID, "reservation total has changed"); err != nil {
+			return entities.Payment{}, err
+		}
+	default:
+		return pending, nil
+	}
+
+	reference, err := paymentReference(id)
+	if err != nil {
+		return entities.Payment{}, err
+	}
+	email, _ := u.resRepo.GetRequesterEmail(id)
+	intent, err := u.paymentProvider.CreateIntent(entities.PaymentIntentRequest{
+		Reference:     reference,
+		Amount:        current.Total,
+		Description:   fmt.Sprintf("Reservation #%d", id),
+		CustomerName:  current.Name,
+		CustomerEmail: email,
+		ExpiresAt:     time.Now().Add(paymentExpiry()),
+	})
+	if err != nil {
+		return entities.Payment{}, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	return u.paymentRepo.CreatePayment(entities.Payment{
+		ReservationID:     id,
+		Provider:          u.paymentProvider.Name(),
+		Reference:         reference,
+		ProviderReference: intent.ProviderReference,
+		Amount:            current.Total,
+		PaymentURL:        intent.PaymentURL,
+		ExpiresAt:         &intent.ExpiresAt,
+	})
+}
+
+func (u *reservationUsecase) GetPayments(id, userID int, userRole string) ([]entities.Payment, error) {
+	current, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	if userRole != "admin" && current.UserID != userID {
+		return nil, &entities.ForbiddenError{Message: "you can only view your own reservation"}
+	}
+	return u.paymentRepo.GetPayments(id)
+}
+
+// HandlePaymentWebhook memverifikasi & memproses callback provider. Event yang dikirim ulang
+// tidak diproses dua kali; receipt dikirim saat reservasi menjadi paid.
+func (u *reservationUsecase) HandlePaymentWebhook(header http.Header, body []byte) (entities.PaymentWebhookResult, error) {
+	event, err := u.paymentProvider.ParseWebhook(header, body)
+	if err != nil {
+		return entities.PaymentWebhookResult{}, err
+	}
+
+	result, err := u.paymentRepo.ApplyWebhook(event)
+	if err != nil || result.Duplicate {
+		return result, err
+	}
+	if result.ReservationPaid {
+		u.sendReceipt(result.ReservationID)
+	} else if result.PaymentStatus == "paid" && event.Status == "paid" {
+		// Uang masuk tapi reservasi sudah cancel / totalnya berubah: perlu dicek admin (refund)
+		log.Printf("payment: %s paid but reservation %d was not updated, please review", event.Reference, result.ReservationID)
+	}
+	return result, nil
+}
+
+// paymentReference: PAY-<reservation id>-<random>, unik per intent
+func paymentReference(reservationID int) (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("PAY-%d-%s", reservationID, hex.EncodeToString(b)), nil
+}
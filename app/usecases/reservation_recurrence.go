@@ -0,0 +1,579 @@
+package usecases
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+)
+
+// Batas jumlah occurrence dalam satu series agar request tidak meledak
+const maxSeriesOccurrences = 100
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// expandRecurrence menghasilkan waktu mulai setiap occurrence (termasuk yang pertama).
+// Perhitungan pakai AddDate agar jam lokal tetap sama walau melewati pergantian DST.
+func expandRecurrence(rule entities.RecurrenceRule, first time.Time) ([]time.Time, error) {
+	interval := rule.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+	if rule.Count <= 0 && rule.Until == nil {
+		return nil, &entities.BadRequestError{Message: "recurrence requires count or until"}
+	}
+	if rule.Count > maxSeriesOccurrences {
+		return nil, &entities.BadRequestError{Message: fmt.Sprintf("recurrence count cannot exceed %d", maxSeriesOccurrences)}
+	}
+	if rule.Until != nil && rule.Until.Before(first) {
+		return nil, &entities.BadRequestError{Message: "recurrence until must be after the first occurrence"}
+	}
+
+	// done mengecek apakah occurrence berikutnya sudah melewati batas
+	var starts []time.Time
+	done := func(t time.Time) bool {
+		if rule.Count > 0 && len(starts) >= rule.Count {
+			return true
+		}
+		if rule.Until != nil && t.After(*rule.Until) {
+			return true
+		}
+		return len(starts) >= maxSeriesOccurrences
+	}
+
+	switch rule.Frequency {
+	case "daily":
+		for i := 0; ; i++ {
+			t := first.AddDate(0, 0, i*interval)
+			if done(t) {
+				break
+			}
+			starts = append(starts, t)
+		}
+
+	case "weekly":
+		weekdays := []time.Weekday{first.Weekday()}
+		if len(rule.ByWeekday) > 0 {
+			var err error
+			if weekdays, err = parseWeekdays(rule.ByWeekday); err != nil {
+				return nil, err
+			}
+		}
+
+		// Minggu dihitung mulai hari Minggu dari minggu occurrence pertama
+		weekStart := first.AddDate(0, 0, -int(first.Weekday()))
+	weeks:
+		for w := 0; ; w++ {
+			for _, wd := range weekdays {
+				t := weekStart.AddDate(0, 0, w*7*interval+int(wd))
+				if t.Before(first) {
+					continue
+				}
+				if done(t) {
+					break weeks
+				}
+				starts = append(starts, t)
+			}
+		}
+
+	case "monthly":
+		for i := 0; ; i++ {
+			t := first.AddDate(0, i*interval, 0)
+			// Bulan yang tidak punya tanggal tersebut (mis. 31) dilewati
+			if t.Day() != first.Day() {
+				if rule.Until != nil && t.After(*rule.Until) {
+					break
+				}
+				if i > maxSeriesOccurrences*2 {
+					break
+				}
+				continue
+			}
+			if done(t) {
+				break
+			}
+			starts = append(starts, t)
+		}
+
+	default:
+		return nil, &entities.BadRequestError{Message: "recurrence frequency must be daily, weekly or monthly"}
+	}
+
+	if len(starts) == 0 {
+		return nil, &entities.BadRequestError{Message: "recurrence produces no occurrence"}
+	}
+	return starts, nil
+}
+
+// parseWeekdays: kode hari (MO,TU,...) ke time.Weekday, urut dan tanpa duplikat.
+// Hari yang ditulis dua kali (mis. MO,mo) hanya menghasilkan satu occurrence.
+func parseWeekdays(codes []string) ([]time.Weekday, error) {
+	var weekdays []time.Weekday
+	seen := make(map[time.Weekday]bool)
+	for _, code := range codes {
+		wd, ok := weekdayCodes[strings.ToUpper(strings.TrimSpace(code))]
+		if !ok {
+			return nil, &entities.BadRequestError{Message: fmt.Sprintf("invalid weekday %s, use MO,TU,WE,TH,FR,SA,SU", code)}
+		}
+		if !seen[wd] {
+			seen[wd] = true
+			weekdays = append(weekdays, wd)
+		}
+	}
+	sort.Slice(weekdays, func(i, j int) bool { return weekdays[i] < weekdays[j] })
+	return weekdays, nil
+}
+
+// weekdayString: kebalikan parseWeekdays, untuk disimpan di reservation_series.by_weekday
+func weekdayString(weekdays []time.Weekday) string {
+	codes := make([]string, 0, len(weekdays))
+	for _, wd := range weekdays {
+		for code, day := range weekdayCodes {
+			if day == wd {
+				codes = append(codes, code)
+			}
+		}
+	}
+	return strings.Join(codes, ",")
+}
+
+// seriesAnchor: waktu mulai paling awal dari semua room di request
+func seriesAnchor(rooms []entities.RoomReservationRequest) time.Time {
+	anchor := rooms[0].StartTime
+	for _, r := range rooms[1:] {
+		if r.StartTime.Before(anchor) {
+			anchor = r.StartTime
+		}
+	}
+	return anchor
+}
+
+// shiftRooms menggeser jadwal semua room ke occurrence yang dimulai pada start
+func shiftRooms(rooms []entities.RoomReservationRequest, anchor, start time.Time) []entities.RoomReservationRequest {
+	shifted := make([]entities.RoomReservationRequest, len(rooms))
+	for i, r := range rooms {
+		offset := r.StartTime.Sub(anchor)
+		duration := r.EndTime.Sub(r.StartTime)
+		shift := start.Add(offset).Sub(r.StartTime)
+		r.StartTime = start.Add(offset)
+		r.EndTime = r.StartTime.Add(duration)
+		// Jam penyajian snack ikut bergeser
+		if len(r.Snacks) > 0 {
+			snacks := make([]entities.SnackLineRequest, len(r.Snacks))
+			for j, s := range r.Snacks {
+				if s.ServeAt != nil {
+					serveAt := s.ServeAt.Add(shift)
+					s.ServeAt = &serveAt
+				}
+				snacks[j] = s
+			}
+			r.Snacks = snacks
+		}
+		shifted[i] = r
+	}
+	return shifted
+}
+
+// calculateSeries menghitung harga setiap occurrence dan melaporkan bentrok per occurrence
+func (u *reservationUsecase) calculateSeries(req entities.ReservationRequest) (entities.CalculateReservationData, error) {
+	var result entities.CalculateReservationData
+
+	anchor := seriesAnchor(req.Rooms)
+	starts, err := expandRecurrence(*req.Recurrence, anchor)
+	if err != nil {
+		return result, err
+	}
+
+	applied, err := u.resolveDiscounts(req)
+	if err != nil {
+		return result, err
+	}
+	charges, err := u.taxRepo.GetTaxComponents()
+	if err != nil {
+		return result, err
+	}
+
+	for i, start := range starts {
+		rooms := shiftRooms(req.Rooms, anchor, start)
+		occ := entities.OccurrenceCalculation{Index: i + 1, StartTime: start, Available: true}
+
+		var details []entities.ReservationDetailData
+		for _, r := range rooms {
+			line, detail, err := u.buildRoomLine(r)
+			if reason, ok := unbookableReason(err); ok {
+				occ.Available = false
+				occ.UnavailableReason = reason
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+			available, err := u.resRepo.CheckAvailability(r.ID, r.StartTime, r.EndTime)
+			if err != nil {
+				return result, err
+			}
+			if !available {
+				occ.Available = false
+				occ.ConflictRoomIDs = append(occ.ConflictRoomIDs, r.ID)
+			}
+			if r.EndTime.After(occ.EndTime) {
+				occ.EndTime = r.EndTime
+			}
+
+			occ.Total += line.SubTotalRoom + line.SubTotalSnack
+			result.SubTotalRoom += line.SubTotalRoom
+			result.SubTotalSnack += line.SubTotalSnack
+			details = append(details, detail)
+			// Detail room ditampilkan untuk occurrence pertama saja
+			if i == 0 {
+				result.Rooms = append(result.Rooms, line)
+			}
+		}
+
+		// Diskon, service charge & pajak dihitung per occurrence (tiap occurrence = satu reservasi), rinciannya dijumlahkan
+		if len(details) == 0 {
+			result.Occurrences = append(result.Occurrences, occ)
+			continue
+		}
+		lines, discount, err := u.discountLines(details, applied)
+		if err != nil {
+			return result, err
+		}
+		var occData entities.ReservationData
+		applyDetailTotals(&occData, details)
+		applyInvoice(&occData, details, discountInvoiceLines(lines, discount), charges)
+		occ.Total = occData.Total
+		result.Discount += discount
+		result.ServiceCharge += occData.ServiceCharge
+		result.Tax += occData.TaxAmount
+		for j, line := range lines {
+			if j < len(result.Discounts) {
+				result.Discounts[j].EligibleAmount += line.EligibleAmount
+				result.Discounts[j].Amount += line.Amount
+			} else {
+				result.Discounts = append(result.Discounts, line)
+			}
+		}
+		result.Occurrences = append(result.Occurrences, occ)
+	}
+	result.ServiceCharge = roundPrice(result.ServiceCharge)
+	result.Tax = roundPrice(result.Tax)
+	result.Total = roundPrice(result.SubTotalRoom + result.SubTotalSnack - result.Discount + result.ServiceCharge + result.Tax)
+
+	return result, nil
+}
+
+// CreateSeries membuat satu reservasi per occurrence yang terhubung lewat reservation_series
+func (u *reservationUsecase) CreateSeries(req entities.ReservationRequest) (entities.SeriesCreateResult, error) {
+	result := entities.SeriesCreateResult{Skipped: []time.Time{}}
+	if len(req.Rooms) == 0 {
+		return result, &entities.BadRequestError{Message: "rooms cannot be empty"}
+	}
+	if req.Recurrence == nil {
+		return result, &entities.BadRequestError{Message: "recurrence is required"}
+	}
+	if req.Hold {
+		return result, &entities.BadRequestError{Message: "hold is not supported for recurring reservations"}
+	}
+	rule := *req.Recurrence
+
+	anchor := seriesAnchor(req.Rooms)
+	starts, err := expandRecurrence(rule, anchor)
+	if err != nil {
+		return result, err
+	}
+
+	var occurrences []entities.ReservationOccurrenceData
+	for _, start := range starts {
+		rooms := shiftRooms(req.Rooms, anchor, start)
+
+		// Pre-check agar occurrence yang bentrok bisa dilewati (skipConflicts).
+		// Pengecekan final tetap dilakukan di transaksi repository.
+		conflict := false
+		for _, r := range rooms {
+			available, err := u.resRepo.CheckAvailability(r.ID, r.StartTime, r.EndTime)
+			if err != nil {
+				return result, err
+			}
+			if !available {
+				if !rule.SkipConflicts {
+					return result, &entities.ConflictError{RoomID: r.ID}
+				}
+				conflict = true
+				break
+			}
+		}
+		if conflict {
+			result.Skipped = append(result.Skipped, start)
+			continue
+		}
+
+		resData, detData, err := u.buildReservation(req, rooms)
+		// Occurrence di hari libur / di luar jam buka / melanggar aturan booking ikut dilewati jika skipConflicts
+		if _, ok := unbookableReason(err); ok && rule.SkipConflicts {
+			result.Skipped = append(result.Skipped, start)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		resData.OccurrenceStart = start
+		occurrences = append(occurrences, entities.ReservationOccurrenceData{Reservation: resData, Details: detData})
+	}
+
+	if len(occurrences) == 0 {
+		return result, &entities.BadRequestError{Message: "all occurrences conflict with existing bookings"}
+	}
+
+	interval := rule.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+	// Sudah divalidasi di expandRecurrence
+	weekdays, _ := parseWeekdays(rule.ByWeekday)
+	series := entities.ReservationSeriesData{
+		UserID:    req.UserID,
+		Frequency: rule.Frequency,
+		Interval:  interval,
+		ByWeekday: weekdayString(weekdays),
+		Until:     rule.Until,
+		Count:     rule.Count,
+	}
+
+	seriesID, err := u.resRepo.CreateSeries(series, occurrences)
+	if err != nil {
+		return result, err
+	}
+
+	result.SeriesID = seriesID
+	result.Created = len(occurrences)
+	return result, nil
+}
+
+// seriesTargets memilih occurrence yang terkena aksi sesuai scope:
+// this = occurrence ini saja, following = ini dan setelahnya, all = seluruh series
+func (u *reservationUsecase) seriesTargets(reservationID, userID int, userRole, scope string) ([]entities.SeriesOccurrence, error) {
+	current, err := u.resRepo.GetByID(reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	if userRole != "admin" && current.UserID != userID {
+		return nil, &entities.ForbiddenError{Message: "you can only update your own reservation"}
+	}
+
+	if current.SeriesID == 0 || scope == "this" {
+		return []entities.SeriesOccurrence{{ReservationID: current.ID, UserID: current.UserID, Status: current.Status}}, nil
+	}
+
+	occurrences, err := u.resRepo.GetSeriesOccurrences(current.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	if scope == "all" {
+		return occurrences, nil
+	}
+
+	var targets []entities.SeriesOccurrence
+	found := false
+	for _, o := range occurrences {
+		if o.ReservationID == reservationID {
+			found = true
+		}
+		if found {
+			targets = append(targets, o)
+		}
+	}
+	return targets, nil
+}
+
+// CancelSeries membatalkan occurrence sesuai scope dalam satu transaksi, jika satu gagal
+// tidak ada yang dibatalkan. Occurrence yang tidak bisa di-cancel (sudah cancel, refunded,
+// hold expired, dll) dilewati. Return jumlah yang dibatalkan.
+func (u *reservationUsecase) CancelSeries(reservationID, userID int, userRole string, req entities.CancelSeriesRequest) (int, error) {
+	targets, err := u.seriesTargets(reservationID, userID, userRole, req.Scope)
+	if err != nil {
+		return 0, err
+	}
+
+	var changes []entities.StatusChangeData
+	var cancelled []entities.ReservationHistoryData
+	var freed []entities.ReservationDetailData
+	for _, t := range targets {
+		allowedRoles, ok := reservationTransitions[t.Status]["cancel"]
+		if !ok || !containsString(allowedRoles, userRole) {
+			continue
+		}
+		current, err := u.resRepo.GetByID(t.ReservationID)
+		if err != nil {
+			return 0, err
+		}
+		if holdExpired(current) {
+			continue
+		}
+		details, err := u.resRepo.GetDetails(t.ReservationID)
+		if err != nil {
+			return 0, err
+		}
+		settlement, err := u.statusSettlement(current, "cancel", details)
+		if err != nil {
+			return 0, err
+		}
+		changes = append(changes, entities.StatusChangeData{ReservationID: current.ID, FromStatus: current.Status, Settlement: settlement})
+		cancelled = append(cancelled, current)
+		freed = append(freed, details...)
+	}
+
+	if len(changes) == 0 {
+		return 0, &entities.BadRequestError{Message: "no occurrence can be cancelled"}
+	}
+	if err := u.resRepo.UpdateStatuses(changes, "cancel", userID, req.Reason); err != nil {
+		return 0, err
+	}
+
+	for _, current := range cancelled {
+		// Hold hasil penawaran waitlist yang dicancel = penawaran ditolak
+		if current.Status == "hold" {
+			if err := u.waitlistRepo.ResolveOffer(current.ID, "expired"); err != nil {
+				return len(changes), err
+			}
+		}
+		if current.Status == "pending" && userRole == "admin" {
+			u.notifyApprovalDecision(current, false, req.Reason)
+		}
+	}
+	u.processWaitlist(freed)
+	return len(changes), nil
+}
+
+// UpdateSeries menggeser jadwal occurrence sesuai scope. Pergeseran dihitung dari
+// occurrence yang dipilih (jadwal lama -> StartTime/EndTime baru), lalu harga room
+// dihitung ulang dari harga snapshot. Sama seperti Modify, hanya occurrence booked yang
+// diubah; yang sudah paid / pending / hold / cancel / refunded dilewati.
+func (u *reservationUsecase) UpdateSeries(reservationID, userID int, userRole string, req entities.UpdateSeriesRequest) (int, error) {
+	if !req.EndTime.After(req.StartTime) {
+		return 0, &entities.BadRequestError{Message: "end time must be after start time"}
+	}
+
+	targets, err := u.seriesTargets(reservationID, userID, userRole, req.Scope)
+	if err != nil {
+		return 0, err
+	}
+
+	// Hitung pergeseran dari occurrence yang dipilih
+	selected, err := u.resRepo.GetDetails(reservationID)
+	if err != nil {
+		return 0, err
+	}
+	if len(selected) == 0 {
+		return 0, &entities.BadRequestError{Message: "reservation has no room detail"}
+	}
+	oldStart, oldEnd := detailSpan(selected)
+	startShift := req.StartTime.Sub(oldStart)
+	endShift := req.EndTime.Sub(oldEnd)
+
+	var occurrences []entities.ReservationOccurrenceData
+	var changes []entities.ReservationChangeData
+	for _, t := range targets {
+		if t.Status != "booked" {
+			continue
+		}
+
+		current, err := u.resRepo.GetByID(t.ReservationID)
+		if err != nil {
+			return 0, err
+		}
+		details, err := u.resRepo.GetDetails(t.ReservationID)
+		if err != nil {
+			return 0, err
+		}
+		snapshot, err := u.invoiceSnapshot(current)
+		if err != nil {
+			return 0, err
+		}
+
+		resData := entities.ReservationData{ID: t.ReservationID, Note: current.Notes}
+		if req.Notes != nil {
+			resData.Note = *req.Notes
+		}
+		if !t.OccurrenceStart.IsZero() {
+			resData.OccurrenceStart = t.OccurrenceStart.Add(startShift)
+		}
+
+		before := append([]entities.ReservationDetailData(nil), details...)
+		for i := range details {
+			d := &details[i]
+			d.StartAt = d.StartAt.Add(startShift)
+			d.EndAt = d.EndAt.Add(endShift)
+			if !d.EndAt.After(d.StartAt) {
+				return 0, &entities.BadRequestError{Message: "end time must be after start time"}
+			}
+			room, err := u.roomRepo.GetByID(d.RoomID)
+			if err != nil {
+				return 0, fmt.Errorf("room %w", entities.ErrNotFound)
+			}
+			if err := u.checkSchedule(room, d.StartAt, d.EndAt, d.TotalParticipants); err != nil {
+				return 0, err
+			}
+			d.DurationMinute = int(d.EndAt.Sub(d.StartAt).Minutes())
+			d.SnackLines = shiftSnackLines(d.SnackLines, startShift, d.StartAt, d.EndAt)
+			// Harga per jam tetap dari snapshot, pricing rule mengikuti jadwal baru
+			var breakdown []entities.PriceLine
+			d.TotalRoom, breakdown, err = u.priceRoom(room.RoomType, d.RoomPrice, d.StartAt, d.EndAt)
+			if err != nil {
+				return 0, err
+			}
+			d.InvoiceLines = detailInvoiceLines(*d, breakdown)
+		}
+		// Diskon & tarif pajak mengikuti saat booking
+		snapshot.apply(&resData, details)
+
+		// Occurrence yang butuh approval setelah digeser menjadi pending
+		reason, err := u.approvalReason(details, resData.Total)
+		if err != nil {
+			return 0, err
+		}
+		if reason != "" {
+			resData.StatusReservation = "pending"
+			resData.ApprovalReason = reason
+		}
+
+		occurrences = append(occurrences, entities.ReservationOccurrenceData{Reservation: resData, Details: details})
+		changes = append(changes, entities.ReservationChangeData{
+			ReservationID: t.ReservationID, ChangedBy: userID, Reason: "series reschedule (" + req.Scope + ")",
+			BeforeTotal: current.Total, AfterTotal: resData.Total,
+			Before: before, After: details,
+		})
+	}
+
+	if len(occurrences) == 0 {
+		return 0, &entities.BadRequestError{Message: "no booked occurrence can be updated"}
+	}
+	if err := u.resRepo.Reschedule(occurrences, changes); err != nil {
+		return 0, err
+	}
+	return len(occurrences), nil
+}
+
+// detailSpan: jam mulai paling awal dan jam selesai paling akhir dari detail reservasi
+func detailSpan(details []entities.ReservationDetailData) (time.Time, time.Time) {
+	start, end := details[0].StartAt, details[0].EndAt
+	for _, d := range details[1:] {
+		if d.StartAt.Before(start) {
+			start = d.StartAt
+		}
+		if d.EndAt.After(end) {
+			end = d.EndAt
+		}
+	}
+	return start, end
+}
@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+)
+
+// snackLines menghitung item snack satu room. Request lama (snackID + addSnack) = satu item default.
+// Harga per unit snack: person x quantity (default jumlah peserta), box x quantity (default 1).
+// Jam penyajian harus di dalam jadwal room, item diurutkan per jam penyajian.
+func (u *reservationUsecase) snackLines(r entities.RoomReservationRequest) ([]entities.SnackLine, float64, error) {
+	items := r.Snacks
+	if len(items) == 0 && r.AddSnack && r.SnackID > 0 {
+		items = []entities.SnackLineRequest{{SnackID: r.SnackID}}
+	}
+
+	var lines []entities.SnackLine
+	total := 0.0
+	for _, item := range items {
+		if item.Quantity < 0 {
+			return nil, 0, &entities.BadRequestError{Message: "snack quantity cannot be negative"}
+		}
+		snack, err := u.snackRepo.GetByID(item.SnackID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("snack %w", entities.ErrNotFound)
+		}
+
+		quantity := item.Quantity
+		if quantity == 0 {
+			quantity = r.Participant
+			if snack.Unit == "box" {
+				quantity = 1
+			}
+		}
+		if quantity <= 0 {
+			return nil, 0, &entities.BadRequestError{Message: fmt.Sprintf("quantity of %s must be at least 1", snack.Name)}
+		}
+		serveAt := r.StartTime
+		if item.ServeAt != nil {
+			serveAt = *item.ServeAt
+		}
+		if serveAt.Before(r.StartTime) || serveAt.After(r.EndTime) {
+			return nil, 0, &entities.BadRequestError{Message: fmt.Sprintf("serving time of %s must be between room start and end time", snack.Name)}
+		}
+
+		line := entities.SnackLine{
+			SnackID: snack.ID, Name: snack.Name, Unit: snack.Unit, Price: snack.Price,
+			Quantity: quantity, ServeAt: serveAt, Subtotal: roundPrice(snack.Price * float64(quantity)),
+		}
+		lines = append(lines, line)
+		total += line.Subtotal
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].ServeAt.Before(lines[j].ServeAt) })
+	return lines, total, nil
+}
+
+// detailSnackLines: item snack detail, snapshot tanpa item (data lama) dianggap satu item per peserta
+func detailSnackLines(d entities.ReservationDetailData) []entities.SnackLine {
+	if len(d.SnackLines) > 0 || d.SnackName == "" || d.TotalSnack == 0 {
+		return d.SnackLines
+	}
+	return []entities.SnackLine{{
+		SnackID: d.SnackID, Name: d.SnackName, Unit: "person", Price: d.SnackPrice,
+		Quantity: d.TotalParticipants, ServeAt: d.StartAt, Subtotal: d.TotalSnack,
+	}}
+}
+
+// firstSnack: snack pertama untuk field snack (kompatibilitas response lama)
+func firstSnack(lines []entities.SnackLine) *entities.Snack {
+	if len(lines) == 0 {
+		return nil
+	}
+	return &entities.Snack{ID: lines[0].SnackID, Name: lines[0].Name, Unit: lines[0].Unit, Price: lines[0].Price}
+}
+
+// shiftSnackLines: jam penyajian ikut bergeser saat reschedule, tetap di dalam jadwal room yang baru
+func shiftSnackLines(lines []entities.SnackLine, shift time.Duration, start, end time.Time) []entities.SnackLine {
+	shifted := make([]entities.SnackLine, len(lines))
+	for i, l := range lines {
+		l.ServeAt = l.ServeAt.Add(shift)
+		if l.ServeAt.Before(start) {
+			l.ServeAt = start
+		}
+		if l.ServeAt.After(end) {
+			l.ServeAt = end
+		}
+		shifted[i] = l
+	}
+	return shifted
+}
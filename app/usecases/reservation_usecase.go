@@ -3,34 +3,107 @@ package usecases
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/payments"
 	"BE-E-Meeting/app/repositories"
 )
 
 type ReservationUsecase interface {
 	Calculate(req entities.ReservationRequest) (entities.CalculateReservationData, error)
-	Create(req entities.ReservationRequest) error
+	Create(req entities.ReservationRequest) (string, error)
+	CreateHold(req entities.ReservationRequest) (time.Time, error)
+	ReleaseExpiredHolds() (int, error)
+	CheckIn(id, userID int, userRole string) (entities.CheckInResult, error)
+	CheckInByRoomToken(token string) (entities.CheckInResult, error)
+	ReleaseNoShows() (int, error)
+	CreateSeries(req entities.ReservationRequest) (entities.SeriesCreateResult, error)
+	UpdateSeries(reservationID, userID int, userRole string, req entities.UpdateSeriesRequest) (int, error)
+	CancelSeries(reservationID, userID int, userRole string, req entities.CancelSeriesRequest) (int, error)
 	GetHistory(userID int, startDate, endDate, roomType, status string, page, pageSize int) (entities.ReservationHistoryResponse, error)
-	GetByID(id int) (entities.ReservationDetailResponse, error)
-	UpdateStatus(id, userID int, status string, userRole string) error
+	GetByID(id, userID int, userRole string) (entities.ReservationDetailResponse, error)
+	UpdateStatus(id, userID int, status, userRole, reason string) error
+	GetStatusHistories(id, userID int, userRole string) ([]entities.ReservationStatusHistory, error)
+	Modify(id, userID int, userRole string, req entities.ModifyReservationRequest) (entities.ModifyReservationResult, error)
+	GetChangeHistories(id, userID int, userRole string) ([]entities.ReservationChangeHistory, error)
+	CancelDetails(id, userID int, userRole string, req entities.CancelDetailsRequest) (entities.CancelDetailsResult, error)
 	GetUserIDByUsername(username string) (int, error)
 	GetSchedules(startDate, endDate string, page, pageSize int) (entities.ScheduleResponse, error)
-	GetRoomSchedule(roomID int, start, end time.Time) (map[string]interface{}, error)
+	GetRoomSchedule(roomID int, start, end time.Time, includeCancelled bool) (map[string]interface{}, error)
+	SearchAvailableRooms(name, roomType string, participant, snackID int, start, end time.Time) ([]entities.AvailableRoom, error)
+	GetPendingApprovals() ([]entities.PendingApproval, error)
+	ApproveReservation(id, adminID int, comment string) error
+	RejectReservation(id, adminID int, comment string) error
+	JoinWaitlist(req entities.WaitlistRequest) (entities.WaitlistEntry, error)
+	GetWaitlist(userID int) ([]entities.WaitlistEntry, error)
+	LeaveWaitlist(id, userID int, userRole string) error
+	GetInvoice(id, userID int, userRole, kind string) (entities.InvoiceFile, error)
+	EmailInvoice(id, userID int, userRole, kind string) (string, error)
+	CreatePayment(id, userID int, userRole string) (entities.Payment, error)
+	GetPayments(id, userID int, userRole string) ([]entities.Payment, error)
+	HandlePaymentWebhook(header http.Header, body []byte) (entities.PaymentWebhookResult, error)
+}
+
+// reservationTransitions: status asal -> status tujuan -> role yang diizinkan.
+// Transisi yang tidak terdaftar di sini dianggap tidak valid.
+var reservationTransitions = map[string]map[string][]string{
+	// Hold dikonversi jadi booking tanpa cek bentrok ulang karena slotnya sudah diblokir.
+	// Hold yang butuh approval masuk ke pending saat dikonfirmasi user.
+	"hold": {
+		"booked":  {"admin", "user"},
+		"pending": {"admin", "user"},
+		"cancel":  {"admin", "user"},
+	},
+	// Pending: menunggu approval admin, slot tetap diblokir. Cancel oleh admin = reject.
+	"pending": {
+		"booked": {"admin"},
+		"cancel": {"admin", "user"},
+	},
+	"booked": {
+		"paid":   {"admin"},
+		"cancel": {"admin", "user"},
+	},
+	"paid": {
+		"refunded": {"admin"},
+		"cancel":   {"admin"},
+	},
 }
 
 type reservationUsecase struct {
-	resRepo   repositories.ReservationRepository
-	roomRepo  repositories.RoomRepository
-	snackRepo repositories.SnackRepository
+	resRepo      repositories.ReservationRepository
+	roomRepo     repositories.RoomRepository
+	snackRepo    repositories.SnackRepository
+	policyRepo   repositories.PolicyRepository
+	waitlistRepo repositories.WaitlistRepository
+	openingRepo  repositories.OpeningHoursRepository
+	pricingRepo  repositories.PricingRepository
+	discountRepo repositories.DiscountRepository
+	taxRepo      repositories.TaxRepository
+	invoiceRepo  repositories.InvoiceRepository
+	paymentRepo  repositories.PaymentRepository
+	// Payment gateway untuk intent & webhook (lihat config.LoadPaymentProvider)
+	paymentProvider payments.Provider
 }
 
-func NewReservationUsecase(resRepo repositories.ReservationRepository, roomRepo repositories.RoomRepository, snackRepo repositories.SnackRepository) ReservationUsecase {
+func NewReservationUsecase(resRepo repositories.ReservationRepository, roomRepo repositories.RoomRepository, snackRepo repositories.SnackRepository, policyRepo repositories.PolicyRepository, waitlistRepo repositories.WaitlistRepository, openingRepo repositories.OpeningHoursRepository, pricingRepo repositories.PricingRepository, discountRepo repositories.DiscountRepository, taxRepo repositories.TaxRepository, invoiceRepo repositories.InvoiceRepository, paymentRepo repositories.PaymentRepository, paymentProvider payments.Provider) ReservationUsecase {
 	return &reservationUsecase{
-		resRepo:   resRepo,
-		roomRepo:  roomRepo,
-		snackRepo: snackRepo,
+		resRepo:      resRepo,
+		roomRepo:     roomRepo,
+		snackRepo:    snackRepo,
+		policyRepo:   policyRepo,
+		waitlistRepo: waitlistRepo,
+		openingRepo:  openingRepo,
+		pricingRepo:  pricingRepo,
+		discountRepo: discountRepo,
+		taxRepo:      taxRepo,
+		invoiceRepo:  invoiceRepo,
+		paymentRepo:  paymentRepo,
+
+		paymentProvider: paymentProvider,
 	}
 }
 
@@ -38,25 +111,27 @@ func NewReservationUsecase(resRepo repositories.ReservationRepository, roomRepo
 func (u *reservationUsecase) Calculate(req entities.ReservationRequest) (entities.CalculateReservationData, error) {
 	var result entities.CalculateReservationData
 	if len(req.Rooms) == 0 {
-		return result, errors.New("rooms cannot be empty")
+		return result, &entities.BadRequestError{Message: "rooms cannot be empty"}
+	}
+	if req.Recurrence != nil {
+		return u.calculateSeries(req)
 	}
 
+	var violations []entities.RuleViolation
+	var details []entities.ReservationDetailData
 	for _, reqRoom := range req.Rooms {
-		// [PENTING] Akses field ID (sesuai entities/room.go)
-		room, err := u.roomRepo.GetByID(reqRoom.ID)
+		line, detail, err := u.buildRoomLine(reqRoom)
+		var ruleErr *entities.ValidationError
+		if errors.As(err, &ruleErr) {
+			// Kumpulkan pelanggaran semua room sebelum dikembalikan
+			violations = append(violations, ruleErr.Violations...)
+			continue
+		}
 		if err != nil {
-			return result, errors.New("room not found")
+			return result, err
 		}
-
-		snackPrice := 0.0
-		var snackData *entities.Snack
-		if reqRoom.AddSnack && reqRoom.SnackID > 0 {
-			snack, err := u.snackRepo.GetByID(reqRoom.SnackID)
-			if err != nil {
-				return result, errors.New("snack not found")
-			}
-			snackPrice = snack.Price
-			snackData = &entities.Snack{ID: snack.ID, Name: snack.Name, Price: snack.Price}
+		if len(violations) > 0 {
+			continue
 		}
 
 		available, err := u.resRepo.CheckAvailability(reqRoom.ID, reqRoom.StartTime, reqRoom.EndTime)
@@ -64,43 +139,126 @@ func (u *reservationUsecase) Calculate(req entities.ReservationRequest) (entitie
 			return result, err
 		}
 		if !available {
-			return result, errors.New("booking schedule conflict")
-		}
-
-		durationMins := int(reqRoom.EndTime.Sub(reqRoom.StartTime).Minutes())
-		durationHours := float64(durationMins) / 60.0
-		subTotalRoom := room.PricePerHour * durationHours
-		subTotalSnack := snackPrice * float64(reqRoom.Participant)
-
-		result.SubTotalRoom += subTotalRoom
-		result.SubTotalSnack += subTotalSnack
-		result.Rooms = append(result.Rooms, entities.RoomCalculationDetail{
-			Name: room.Name, PricePerHour: room.PricePerHour, ImageURL: room.PictureURL,
-			SubTotalRoom: subTotalRoom, SubTotalSnack: subTotalSnack,
-			StartTime: reqRoom.StartTime, EndTime: reqRoom.EndTime,
-			Duration: durationMins, Participant: reqRoom.Participant,
-			Snack: snackData,
-		})
+			return result, u.conflictWithSuggestions(reqRoom)
+		}
+
+		result.SubTotalRoom += line.SubTotalRoom
+		result.SubTotalSnack += line.SubTotalSnack
+		result.Rooms = append(result.Rooms, line)
+		details = append(details, detail)
+	}
+	if len(violations) > 0 {
+		return result, &entities.ValidationError{Violations: violations}
 	}
-	result.Total = result.SubTotalRoom + result.SubTotalSnack
+
+	applied, err := u.resolveDiscounts(req)
+	if err != nil {
+		return result, err
+	}
+	result.Discounts, result.Discount, err = u.discountLines(details, applied)
+	if err != nil {
+		return result, err
+	}
+
+	// Service charge & pajak dihitung dari total setelah diskon
+	var resData entities.ReservationData
+	applyDetailTotals(&resData, details)
+	if err := u.applyCurrentCharges(&resData, details, discountInvoiceLines(result.Discounts, result.Discount)); err != nil {
+		return result, err
+	}
+	result.ServiceCharge, result.Tax, result.Total = resData.ServiceCharge, resData.TaxAmount, resData.Total
+	result.InvoiceLines = allInvoiceLines(resData, details)
 
 	return result, nil
 }
 
 // 2. Create
-func (u *reservationUsecase) Create(req entities.ReservationRequest) error {
-	// Cek Availability Semua Room
-	for _, r := range req.Rooms {
-		// [PENTING] Akses r.ID
-		avail, err := u.resRepo.CheckAvailability(r.ID, r.StartTime, r.EndTime)
-		if err != nil {
-			return err
-		}
-		if !avail {
-			return fmt.Errorf("room %d is already booked", r.ID)
+// Availability dicek oleh repository di dalam transaksi insert (lihat resRepo.Create),
+// bentrok jadwal dikembalikan sebagai *entities.ConflictError.
+// Mengembalikan status reservasi: booked, atau pending jika butuh approval admin.
+func (u *reservationUsecase) Create(req entities.ReservationRequest) (string, error) {
+	resData, detData, err := u.buildReservation(req, req.Rooms)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = u.resRepo.Create(resData, detData)
+	var conflictErr *entities.ConflictError
+	if errors.As(err, &conflictErr) {
+		for _, r := range req.Rooms {
+			if r.ID == conflictErr.RoomID {
+				return "", u.conflictWithSuggestions(r)
+			}
 		}
 	}
+	if err != nil {
+		return "", err
+	}
+	return resData.StatusReservation, nil
+}
+
+// buildRoomLine menghitung harga satu room (tanpa cek availability).
+// Aturan booking dievaluasi dulu (*entities.ValidationError), lalu jadwal di luar
+// jam buka / tanggal blackout ditolak dengan *entities.ClosedError.
+// Hasilnya dipakai untuk response kalkulasi dan snapshot reservation_details.
+func (u *reservationUsecase) buildRoomLine(r entities.RoomReservationRequest) (entities.RoomCalculationDetail, entities.ReservationDetailData, error) {
+	var line entities.RoomCalculationDetail
+	var detail entities.ReservationDetailData
+
+	// [PENTING] Akses field ID (sesuai entities/room.go)
+	room, err := u.roomRepo.GetByID(r.ID)
+	if err != nil {
+		return line, detail, fmt.Errorf("room %w", entities.ErrNotFound)
+	}
+	if err := u.checkSchedule(room, r.StartTime, r.EndTime, r.Participant); err != nil {
+		return line, detail, err
+	}
+
+	snacks, subTotalSnack, err := u.snackLines(r)
+	if err != nil {
+		return line, detail, err
+	}
+
+	durationMins := int(r.EndTime.Sub(r.StartTime).Minutes())
+	subTotalRoom, breakdown, err := u.priceRoom(room.RoomType, room.PricePerHour, r.StartTime, r.EndTime)
+	if err != nil {
+		return line, detail, err
+	}
+
+	line = entities.RoomCalculationDetail{
+		Name: room.Name, PricePerHour: room.PricePerHour, ImageURL: room.PictureURL,
+		SubTotalRoom: subTotalRoom, SubTotalSnack: subTotalSnack,
+		StartTime: r.StartTime, EndTime: r.EndTime,
+		Duration: durationMins, Participant: r.Participant,
+		Snack: firstSnack(snacks), Snacks: snacks, PriceBreakdown: breakdown,
+	}
 
+	detail = entities.ReservationDetailData{
+		RoomID: room.ID, RoomName: room.Name, RoomType: room.RoomType, RoomPrice: room.PricePerHour,
+		DurationMinute: durationMins, TotalParticipants: r.Participant,
+		TotalRoom: subTotalRoom, TotalSnack: subTotalSnack,
+		StartAt: r.StartTime, EndAt: r.EndTime, SnackLines: snacks,
+	}
+	if len(snacks) > 0 {
+		detail.SnackID = snacks[0].SnackID
+		detail.SnackName = snacks[0].Name
+		detail.SnackPrice = snacks[0].Price
+	}
+	detail.InvoiceLines = detailInvoiceLines(detail, breakdown)
+
+	return line, detail, nil
+}
+
+// checkSchedule: aturan booking (termasuk kapasitas) dulu, lalu jam buka / tanggal blackout room
+func (u *reservationUsecase) checkSchedule(room entities.Room, start, end time.Time, participant int) error {
+	if err := u.checkBookingRules(room, start, end, participant); err != nil {
+		return err
+	}
+	return u.checkOpeningHours(room, start, end)
+}
+
+// buildReservation menyusun header + detail reservasi dari request untuk disimpan
+func (u *reservationUsecase) buildReservation(req entities.ReservationRequest, rooms []entities.RoomReservationRequest) (entities.ReservationData, []entities.ReservationDetailData, error) {
 	var resData entities.ReservationData
 	var detData []entities.ReservationDetailData
 
@@ -110,50 +268,79 @@ func (u *reservationUsecase) Create(req entities.ReservationRequest) error {
 	resData.ContactCompany = req.Company
 	resData.Note = req.Notes
 
-	// hitung total participants dari penjumlahan room participants
-	calculatedTotalParticipants := 0
-	totalGlobal := 0.0
-
-	for _, r := range req.Rooms {
-		roomDB, err := u.roomRepo.GetByID(r.ID)
+	var violations []entities.RuleViolation
+	for _, r := range rooms {
+		_, detail, err := u.buildRoomLine(r)
+		var ruleErr *entities.ValidationError
+		if errors.As(err, &ruleErr) {
+			violations = append(violations, ruleErr.Violations...)
+			continue
+		}
 		if err != nil {
-			return err
+			return resData, nil, err
 		}
+		detData = append(detData, detail)
+	}
+	if len(violations) > 0 {
+		return resData, nil, &entities.ValidationError{Violations: violations}
+	}
+	applyDetailTotals(&resData, detData)
 
-		snackPrice := 0.0
-		snackName := ""
-		snackID := 0
-		if r.AddSnack && r.SnackID > 0 {
-			snackDB, err := u.snackRepo.GetByID(r.SnackID)
-			if err != nil {
-				return errors.New("snack not found")
-			}
-			snackPrice = snackDB.Price
-			snackName = snackDB.Name
-			snackID = snackDB.ID
-		}
-
-		durMins := int(r.EndTime.Sub(r.StartTime).Minutes())
-		priceRoom := (float64(durMins) / 60.0) * roomDB.PricePerHour
-		priceSnack := float64(r.Participant) * snackPrice
-
-		totalGlobal += (priceRoom + priceSnack)
-		resData.SubTotalRoom += priceRoom
-		resData.SubTotalSnack += priceSnack
-		calculatedTotalParticipants += r.Participant
-
-		detData = append(detData, entities.ReservationDetailData{
-			RoomID: roomDB.ID, RoomName: roomDB.Name, RoomPrice: roomDB.PricePerHour,
-			SnackID: snackID, SnackName: snackName, SnackPrice: snackPrice,
-			DurationMinute: durMins, TotalParticipants: r.Participant,
-			TotalRoom: priceRoom, TotalSnack: priceSnack,
-			StartAt: r.StartTime, EndAt: r.EndTime,
-		})
+	// Promo code / company agreement mengurangi total, lalu service charge & pajak, sebelum cek approval
+	applied, err := u.resolveDiscounts(req)
+	if err != nil {
+		return resData, nil, err
+	}
+	discounts, discount, err := u.discountLines(detData, applied)
+	if err != nil {
+		return resData, nil, err
+	}
+	if applied.promo != nil {
+		resData.PromoCodeID = applied.promo.ID
+	}
+	if applied.agreement != nil {
+		resData.CompanyAgreementID = applied.agreement.ID
+	}
+	if err := u.applyCurrentCharges(&resData, detData, discountInvoiceLines(discounts, discount)); err != nil {
+		return resData, nil, err
+	}
+
+	// Room type tertentu / total di atas threshold menunggu approval admin
+	reason, err := u.approvalReason(detData, resData.Total)
+	if err != nil {
+		return resData, nil, err
+	}
+	resData.StatusReservation = "booked"
+	if reason != "" {
+		resData.StatusReservation = "pending"
+		resData.ApprovalReason = reason
+	}
+
+	return resData, detData, nil
+}
+
+// applyDetailTotals menghitung ulang subtotal, total (sebelum diskon & pajak) dan total participants header dari detail
+func applyDetailTotals(res *entities.ReservationData, details []entities.ReservationDetailData) {
+	res.SubTotalRoom = 0
+	res.SubTotalSnack = 0
+	res.AddSnack = false
+	// hitung total participants dari penjumlahan room participants
+	res.TotalParticipants = 0
+
+	for _, d := range details {
+		res.SubTotalRoom += d.TotalRoom
+		res.SubTotalSnack += d.TotalSnack
+		res.TotalParticipants += d.TotalParticipants
+		if len(detailSnackLines(d)) > 0 {
+			res.AddSnack = true
+		}
 	}
-	resData.Total = totalGlobal
-	resData.TotalParticipants = calculatedTotalParticipants
+	res.Total = res.SubTotalRoom + res.SubTotalSnack
+}
 
-	return u.resRepo.Create(resData, detData)
+// roomPrice: harga dasar room = harga per jam x durasi (menit / 60), sebelum pricing rule
+func roomPrice(pricePerHour float64, durationMinute int) float64 {
+	return pricePerHour * (float64(durationMinute) / 60.0)
 }
 
 // 3. Get History
@@ -168,47 +355,236 @@ func (u *reservationUsecase) GetHistory(userID int, startDate, endDate, roomType
 	}, err
 }
 
-// 4. Get By ID
-func (u *reservationUsecase) GetByID(id int) (entities.ReservationDetailResponse, error) {
+// 4. Get By ID (pemilik atau admin)
+func (u *reservationUsecase) GetByID(id, userID int, userRole string) (entities.ReservationDetailResponse, error) {
 	data, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return entities.ReservationDetailResponse{}, fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	if userRole != "admin" && data.UserID != userID {
+		return entities.ReservationDetailResponse{}, &entities.ForbiddenError{Message: "you can only view your own reservation"}
+	}
 	return entities.ReservationDetailResponse{
 		Message: "success",
 		Data:    data,
-	}, err
+	}, nil
 }
 
 func (u *reservationUsecase) GetUserIDByUsername(username string) (int, error) {
 	return u.resRepo.GetUserIDByUsername(username)
 }
 
-func (u *reservationUsecase) UpdateStatus(id, userID int, status string, userRole string) error {
+func (u *reservationUsecase) UpdateStatus(id, userID int, status, userRole, reason string) error {
 	currentData, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+
+	// User biasa hanya boleh mengubah reservasi miliknya sendiri
+	if userRole != "admin" && currentData.UserID != userID {
+		return &entities.ForbiddenError{Message: "you can only update your own reservation"}
+	}
+	if holdExpired(currentData) {
+		return &entities.BadRequestError{Message: "hold has expired"}
+	}
+	// Hold yang butuh approval tidak langsung booked kecuali dikonfirmasi admin
+	if currentData.Status == "hold" && status == "booked" && currentData.ApprovalReason != "" && userRole != "admin" {
+		status = "pending"
+	}
+
+	nextStatuses, ok := reservationTransitions[currentData.Status]
+	if !ok {
+		return &entities.BadRequestError{Message: fmt.Sprintf("cannot update %s reservation", currentData.Status)}
+	}
+	allowedRoles, ok := nextStatuses[status]
+	if !ok {
+		return &entities.BadRequestError{Message: fmt.Sprintf("cannot change status from %s to %s", currentData.Status, status)}
+	}
+	if !containsString(allowedRoles, userRole) {
+		return &entities.ForbiddenError{Message: fmt.Sprintf("role %s cannot change status from %s to %s", userRole, currentData.Status, status)}
+	}
+
+	// Slot yang dilepas (cancel/refunded) ditawarkan ke waitlist setelah status tersimpan
+	var freed []entities.ReservationDetailData
+	if status == "cancel" || status == "refunded" {
+		freed, err = u.resRepo.GetDetails(id)
+		if err != nil {
+			return err
+		}
+	}
+
+	settlement, err := u.statusSettlement(currentData, status, freed)
 	if err != nil {
 		return err
 	}
-	if currentData.Status == "cancel" {
-		return errors.New("cannot update canceled reservation")
+	if err := u.resRepo.UpdateStatus(id, currentData.Status, status, userID, reason, settlement); err != nil {
+		return err
+	}
+
+	// Hold hasil penawaran waitlist: tandai booked jika dikonfirmasi, expired jika ditolak
+	if currentData.Status == "hold" {
+		outcome := "expired"
+		if status == "booked" {
+			outcome = "booked"
+		}
+		if err := u.waitlistRepo.ResolveOffer(id, outcome); err != nil {
+			return err
+		}
+	}
+	// Keputusan admin atas reservasi pending dikirim ke pemohon
+	if currentData.Status == "pending" && userRole == "admin" {
+		u.notifyApprovalDecision(currentData, status == "booked", reason)
+	}
+	// Receipt PDF dikirim ke pemilik reservasi setelah dibayar
+	if status == "paid" {
+		u.sendReceipt(id)
+	}
+	u.processWaitlist(freed)
+	return nil
+}
+
+// statusSettlement: cancel mengikuti cancellation policy (hold / pending gratis),
+// refunded = dikembalikan penuh, status lain tanpa settlement
+func (u *reservationUsecase) statusSettlement(current entities.ReservationHistoryData, status string, freed []entities.ReservationDetailData) (*entities.CancellationSettlement, error) {
+	switch {
+	case status == "cancel" && !tentativeStatus(current.Status):
+		s, err := u.cancellationSettlement(freed, current.Total, current.Status == "paid", time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return &s, nil
+	case status == "refunded":
+		return &entities.CancellationSettlement{Refund: current.Total}, nil
+	}
+	return nil, nil
+}
+
+func (u *reservationUsecase) GetStatusHistories(id, userID int, userRole string) ([]entities.ReservationStatusHistory, error) {
+	currentData, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("reservation %w", entities.ErrNotFound)
 	}
-	return u.resRepo.UpdateStatus(id, status)
+	if userRole != "admin" && currentData.UserID != userID {
+		return nil, &entities.ForbiddenError{Message: "you can only view your own reservation"}
+	}
+	return u.resRepo.GetStatusHistories(id)
+}
+
+// CancelDetails mencancel sebagian room dari reservasi multi-room. Aturan role sama
+// dengan transisi ke cancel; slot room yang dicancel langsung bisa dibooking lagi.
+func (u *reservationUsecase) CancelDetails(id, userID int, userRole string, req entities.CancelDetailsRequest) (entities.CancelDetailsResult, error) {
+	result := entities.CancelDetailsResult{ReservationID: id}
+
+	currentData, err := u.resRepo.GetByID(id)
+	if err != nil {
+		return result, fmt.Errorf("reservation %w", entities.ErrNotFound)
+	}
+	if userRole != "admin" && currentData.UserID != userID {
+		return result, &entities.ForbiddenError{Message: "you can only cancel your own reservation"}
+	}
+
+	allowedRoles, ok := reservationTransitions[currentData.Status]["cancel"]
+	if !ok {
+		return result, &entities.BadRequestError{Message: fmt.Sprintf("cannot cancel %s reservation", currentData.Status)}
+	}
+	if !containsString(allowedRoles, userRole) {
+		return result, &entities.ForbiddenError{Message: fmt.Sprintf("role %s cannot cancel %s reservation", userRole, currentData.Status)}
+	}
+
+	details, err := u.resRepo.GetDetails(id)
+	if err != nil {
+		return result, err
+	}
+
+	cancelIDs := make(map[int]bool, len(req.DetailIDs))
+	for _, detailID := range req.DetailIDs {
+		if !cancelIDs[detailID] {
+			cancelIDs[detailID] = true
+			result.Cancelled = append(result.Cancelled, detailID)
+		}
+	}
+	var remaining []entities.ReservationDetailData
+	for _, d := range details {
+		if !cancelIDs[d.ID] {
+			remaining = append(remaining, d)
+		}
+	}
+	if len(details)-len(remaining) != len(cancelIDs) {
+		return result, &entities.BadRequestError{Message: "some details are not found or already cancelled"}
+	}
+
+	// Total sisa dihitung dengan diskon & tarif pajak saat booking
+	snapshot, err := u.invoiceSnapshot(currentData)
+	if err != nil {
+		return result, err
+	}
+	afterData := entities.ReservationData{ID: id}
+	snapshot.apply(&afterData, remaining)
+	result.Before = historySummary(currentData)
+
+	change := entities.ReservationChangeData{
+		ReservationID: id, ChangedBy: userID, Reason: req.Reason,
+		BeforeTotal: currentData.Total,
+		Before:      details, After: remaining,
+	}
+	var cancelled []entities.ReservationDetailData
+	for _, d := range details {
+		if cancelIDs[d.ID] {
+			cancelled = append(cancelled, d)
+		}
+	}
+	var settlement entities.CancellationSettlement
+	if !tentativeStatus(currentData.Status) {
+		// Bagian total milik detail yang dicancel = total sekarang - total sisa (snapshot invoice)
+		billed := roundPrice(currentData.Total - afterData.Total)
+		if billed < 0 {
+			billed = 0
+		}
+		settlement, err = u.cancellationSettlement(cancelled, billed, currentData.Status == "paid", time.Now())
+		if err != nil {
+			return result, err
+		}
+	}
+	result.Settlement = settlement
+
+	after := entities.ReservationOccurrenceData{Reservation: afterData, Details: remaining}
+	resData, err := u.resRepo.CancelDetails(id, currentData.Status, result.Cancelled, after, change, settlement)
+	if err != nil {
+		return result, err
+	}
+	u.processWaitlist(cancelled)
+
+	result.Status = resData.StatusReservation
+	result.After = priceSummary(resData)
+	return result, nil
 }
 
 func (u *reservationUsecase) GetSchedules(startDate, endDate string, page, pageSize int) (entities.ScheduleResponse, error) {
 	offset := (page - 1) * pageSize
 	data, total, err := u.resRepo.GetSchedules(startDate, endDate, pageSize, offset)
+	if err != nil {
+		return entities.ScheduleResponse{}, err
+	}
+	closures, err := u.openingRepo.GetBlackoutDates(0, startDate, endDate)
 
 	return entities.ScheduleResponse{
 		Message:   "success",
 		Data:      data,
 		TotalData: total,
+		Closures:  closures,
 	}, err
 }
 
-func (u *reservationUsecase) GetRoomSchedule(roomID int, start, end time.Time) (map[string]interface{}, error) {
+func (u *reservationUsecase) GetRoomSchedule(roomID int, start, end time.Time, includeCancelled bool) (map[string]interface{}, error) {
 	room, err := u.roomRepo.GetByID(roomID)
 	if err != nil {
-		return nil, errors.New("room not found")
+		return nil, fmt.Errorf("room %w", entities.ErrNotFound)
 	}
-	schedules, err := u.resRepo.GetReservationsByRoomID(roomID, start, end)
+	schedules, err := u.resRepo.GetReservationsByRoomID(roomID, start, end, includeCancelled)
+	if err != nil {
+		return nil, err
+	}
+	opening, err := u.roomOpening(roomID, start)
 	if err != nil {
 		return nil, err
 	}
@@ -216,5 +592,195 @@ func (u *reservationUsecase) GetRoomSchedule(roomID int, start, end time.Time) (
 		"room":      room,
 		"schedules": schedules,
 		"date":      start.Format("2006-01-02"),
+		"opening":   opening,
 	}, nil
 }
+
+// SearchAvailableRooms mencari room yang kosong di rentang waktu tertentu, lengkap
+// dengan harga hasil kalkulasi yang sama dengan Calculate
+func (u *reservationUsecase) SearchAvailableRooms(name, roomType string, participant, snackID int, start, end time.Time) ([]entities.AvailableRoom, error) {
+	if start.IsZero() || end.IsZero() {
+		return nil, &entities.BadRequestError{Message: "startTime and endTime are required"}
+	}
+	if !end.After(start) {
+		return nil, &entities.BadRequestError{Message: "end time must be after start time"}
+	}
+	if roomType != "" && roomType != "small" && roomType != "medium" && roomType != "large" {
+		return nil, &entities.BadRequestError{Message: "room type is not valid"}
+	}
+	return u.availableRooms(name, roomType, participant, entities.RoomReservationRequest{
+		StartTime: start, EndTime: end, Participant: participant, SnackID: snackID, AddSnack: snackID > 0,
+	})
+}
+
+// availableRooms: room kosong di jadwal req dengan kapasitas minimal minCapacity.
+// Harga dihitung dari req (peserta & snack yang diminta), bukan dari minCapacity.
+func (u *reservationUsecase) availableRooms(name, roomType string, minCapacity int, req entities.RoomReservationRequest) ([]entities.AvailableRoom, error) {
+	capacity := ""
+	if minCapacity > 0 {
+		capacity = strconv.Itoa(minCapacity)
+	}
+
+	rooms, err := u.roomRepo.GetAvailable(name, roomType, capacity, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []entities.AvailableRoom{}
+	for _, room := range rooms {
+		r := req
+		r.ID = room.ID
+		line, _, err := u.buildRoomLine(r)
+		// Room yang tutup / aturan booking room type-nya tidak terpenuhi tidak ditampilkan
+		if _, ok := unbookableReason(err); ok {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entities.AvailableRoom{
+			Room:          room,
+			SubTotalRoom:  line.SubTotalRoom,
+			SubTotalSnack: line.SubTotalSnack,
+			Total:         line.SubTotalRoom + line.SubTotalSnack,
+			Duration:      line.Duration,
+		})
+	}
+
+	// Best fit: kapasitas terkecil yang cukup, lalu harga total termurah
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Capacity != result[j].Capacity {
+			return result[i].Capacity < result[j].Capacity
+		}
+		return result[i].Total < result[j].Total
+	})
+
+	return result, nil
+}
+
+// Kelipatan waktu mulai untuk saran slot alternatif
+const suggestionStep = 15 * time.Minute
+
+// Jumlah maksimal saran slot di room yang sama
+const maxSuggestedSlots = 3
+
+// conflictWithSuggestions membuat ConflictError beserta saran: slot kosong terdekat
+// dengan durasi yang sama di room yang sama (hari yang sama), dan room lain dengan
+// kapasitas sama/lebih besar yang kosong di jam yang diminta.
+func (u *reservationUsecase) conflictWithSuggestions(r entities.RoomReservationRequest) error {
+	conflictErr := &entities.ConflictError{RoomID: r.ID}
+
+	suggestions, err := u.suggestAlternatives(r)
+	if err == nil {
+		conflictErr.Suggestions = &suggestions
+	}
+	return conflictErr
+}
+
+func (u *reservationUsecase) suggestAlternatives(r entities.RoomReservationRequest) (entities.ConflictSuggestion, error) {
+	suggestions := entities.ConflictSuggestion{
+		SameRoomSlots: []entities.SuggestedSlot{},
+		OtherRooms:    []entities.AvailableRoom{},
+	}
+
+	room, err := u.roomRepo.GetByID(r.ID)
+	if err != nil {
+		return suggestions, err
+	}
+
+	// A. Slot lain di room yang sama pada hari yang sama, hanya di dalam jam buka room
+	duration := r.EndTime.Sub(r.StartTime)
+	opening, err := u.roomOpening(r.ID, r.StartTime)
+	if err != nil {
+		return suggestions, err
+	}
+	var dayStart, dayEnd time.Time
+	if !opening.Closed {
+		dayStart, dayEnd = *opening.OpenTime, *opening.CloseTime
+	}
+
+	occupied, err := u.resRepo.GetReservationsByRoomID(r.ID, dayStart, dayEnd, false)
+	if err != nil {
+		return suggestions, err
+	}
+
+	now := time.Now()
+	setup := time.Duration(room.SetupBufferMinutes) * time.Minute
+	teardown := time.Duration(room.TeardownBufferMinutes) * time.Minute
+	var candidates []entities.SuggestedSlot
+	for start := dayStart; !start.Add(duration).After(dayEnd); start = start.Add(suggestionStep) {
+		end := start.Add(duration)
+		if start.Before(now) || start.Equal(r.StartTime) {
+			continue
+		}
+		// Slot kandidat juga diperluas dengan buffer room, sama seperti CheckAvailability
+		blockedFrom := start.Add(-setup)
+		blockedUntil := end.Add(teardown)
+		free := true
+		for _, o := range occupied {
+			if blockedFrom.Before(o.BlockedUntil) && o.BlockedFrom.Before(blockedUntil) {
+				free = false
+				break
+			}
+		}
+		if free {
+			candidates = append(candidates, entities.SuggestedSlot{RoomID: r.ID, StartTime: start, EndTime: end})
+		}
+	}
+
+	// Urutkan berdasarkan jarak ke jam yang diminta
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return absDuration(candidates[i].StartTime.Sub(r.StartTime)) < absDuration(candidates[j].StartTime.Sub(r.StartTime))
+	})
+	if len(candidates) > maxSuggestedSlots {
+		candidates = candidates[:maxSuggestedSlots]
+	}
+	suggestions.SameRoomSlots = append(suggestions.SameRoomSlots, candidates...)
+
+	// B. Room lain dengan kapasitas >= room yang diminta di jam yang sama,
+	// harga tetap untuk jumlah peserta & snack yang diminta
+	capacity := room.Capacity
+	if r.Participant > capacity {
+		capacity = r.Participant
+	}
+	otherRooms, err := u.availableRooms("", "", capacity, r)
+	if err != nil {
+		return suggestions, err
+	}
+	for _, other := range otherRooms {
+		if other.ID != r.ID {
+			suggestions.OtherRooms = append(suggestions.OtherRooms, other)
+		}
+	}
+
+	return suggestions, nil
+}
+
+// HELPER
+
+// unbookableReason: pesan error jika jadwal ditolak karena jam buka / aturan booking
+// (bukan error sistem), dipakai untuk melewati room / occurrence tersebut
+func unbookableReason(err error) (string, bool) {
+	var closedErr *entities.ClosedError
+	var ruleErr *entities.ValidationError
+	if errors.As(err, &closedErr) || errors.As(err, &ruleErr) {
+		return err.Error(), true
+	}
+	return "", false
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package usecases
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/utils"
+)
+
+// Lama penawaran slot ke waitlist default jika WAITLIST_OFFER_HOURS tidak diisi
+const defaultWaitlistOfferDuration = 2 * time.Hour
+
+func waitlistOfferDuration() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("WAITLIST_OFFER_HOURS"))
+	if err != nil || hours <= 0 {
+		return defaultWaitlistOfferDuration
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// JoinWaitlist mendaftarkan user ke antrian room + rentang waktu yang sedang penuh
+func (u *reservationUsecase) JoinWaitlist(req entities.WaitlistRequest) (entities.WaitlistEntry, error) {
+	var entry entities.WaitlistEntry
+	if !req.EndTime.After(req.StartTime) {
+		return entry, &entities.BadRequestError{Message: "end time must be after start time"}
+	}
+	if !req.StartTime.After(time.Now()) {
+		return entry, &entities.BadRequestError{Message: "start time must be in the future"}
+	}
+	room, err := u.roomRepo.GetByID(req.RoomID)
+	if err != nil {
+		return entry, fmt.Errorf("room %w", entities.ErrNotFound)
+	}
+	if err := u.checkSchedule(room, req.StartTime, req.EndTime, req.Participant); err != nil {
+		return entry, err
+	}
+
+	available, err := u.resRepo.CheckAvailability(req.RoomID, req.StartTime, req.EndTime)
+	if err != nil {
+		return entry, err
+	}
+	if available {
+		return entry, &entities.BadRequestError{Message: "room is available, please create a reservation directly"}
+	}
+
+	entry = entities.WaitlistEntry{
+		UserID: req.UserID, RoomID: req.RoomID, StartTime: req.StartTime, EndTime: req.EndTime,
+		Participant: req.Participant, Name: req.Name, PhoneNumber: req.PhoneNumber,
+		Company: req.Company, Notes: req.Notes, AutoBook: req.AutoBook, Status: "waiting",
+	}
+	if req.AddSnack {
+		entry.SnackID = req.SnackID
+	}
+
+	entry.ID, err = u.waitlistRepo.Create(entry)
+	if err != nil {
+		return entry, err
+	}
+	return u.waitlistRepo.GetByID(entry.ID)
+}
+
+func (u *reservationUsecase) GetWaitlist(userID int) ([]entities.WaitlistEntry, error) {
+	return u.waitlistRepo.GetByUserID(userID)
+}
+
+func (u *reservationUsecase) LeaveWaitlist(id, userID int, userRole string) error {
+	entry, err := u.waitlistRepo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("waitlist entry %w", entities.ErrNotFound)
+	}
+	if userRole != "admin" && entry.UserID != userID {
+		return &entities.ForbiddenError{Message: "you can only leave your own waitlist entry"}
+	}
+	return u.waitlistRepo.Cancel(id)
+}
+
+// processWaitlist dipanggil setelah slot kosong (cancel / hold expired). Entry waitlist
+// yang bersinggungan diproses FIFO: jika seluruh rentang waktunya kosong, entry autoBook
+// langsung dibooking, selain itu slot ditawarkan sebagai hold sementara.
+// Error hanya dicatat karena perubahan yang memicu sudah tersimpan.
+func (u *reservationUsecase) processWaitlist(freed []entities.ReservationDetailData) {
+	for _, d := range freed {
+		entries, err := u.waitlistRepo.GetWaiting(d.RoomID, d.StartAt, d.EndAt)
+		if err != nil {
+			log.Printf("waitlist: failed to load entries for room %d: %v", d.RoomID, err)
+			continue
+		}
+
+		for _, entry := range entries {
+			available, err := u.resRepo.CheckAvailability(entry.RoomID, entry.StartTime, entry.EndTime)
+			if err != nil || !available {
+				continue
+			}
+			if err := u.fulfillWaitlist(entry); err != nil {
+				log.Printf("waitlist: failed to process entry %d: %v", entry.ID, err)
+			}
+		}
+	}
+}
+
+func (u *reservationUsecase) fulfillWaitlist(entry entities.WaitlistEntry) error {
+	req := entities.ReservationRequest{
+		UserID: entry.UserID, Name: entry.Name, PhoneNumber: entry.PhoneNumber,
+		Company: entry.Company, Notes: entry.Notes,
+		Rooms: []entities.RoomReservationRequest{{
+			ID: entry.RoomID, StartTime: entry.StartTime, EndTime: entry.EndTime,
+			Participant: entry.Participant, SnackID: entry.SnackID, AddSnack: entry.SnackID > 0,
+		}},
+	}
+
+	var subject, body string
+	if entry.AutoBook {
+		resData, detData, err := u.buildReservation(req, req.Rooms)
+		if err != nil {
+			return err
+		}
+		reservationID, err := u.resRepo.Create(resData, detData)
+		if err != nil {
+			return err
+		}
+		if err := u.waitlistRepo.SetOutcome(entry.ID, "booked", reservationID, time.Time{}); err != nil {
+			return err
+		}
+
+		subject = "Waitlist: Your Reservation Is Booked"
+		body = fmt.Sprintf(`
+    <h1>Your Reservation Is Booked</h1>
+    <p>Hi %s,</p>
+    <p>The room you were waiting for is now available and has been booked for you (reservation #%d),
+    %s - %s.</p>
+    `, html.EscapeString(entry.Name), reservationID, entry.StartTime.Format("02 Jan 2006 15:04"), entry.EndTime.Format("15:04"))
+		if resData.StatusReservation == "pending" {
+			body += `    <p>This booking needs admin approval, you will get another email once it is decided.</p>
+    `
+		}
+	} else {
+		reservationID, expiresAt, err := u.createHold(req, waitlistOfferDuration())
+		if err != nil {
+			return err
+		}
+		if err := u.waitlistRepo.SetOutcome(entry.ID, "offered", reservationID, expiresAt); err != nil {
+			return err
+		}
+
+		subject = "Waitlist: A Slot Is Available"
+		body = fmt.Sprintf(`
+    <h1>A Slot Is Available</h1>
+    <p>Hi %s,</p>
+    <p>The room you were waiting for is now available, %s - %s.</p>
+    <p>It is held for you as reservation #%d until %s. Confirm it by changing the status to booked,
+    otherwise the offer goes to the next person in the waitlist.</p>
+    `, html.EscapeString(entry.Name), entry.StartTime.Format("02 Jan 2006 15:04"), entry.EndTime.Format("15:04"),
+			reservationID, expiresAt.Format("02 Jan 2006 15:04"))
+	}
+
+	if entry.Email != "" {
+		go func() {
+			if err := utils.SendNotificationEmail(entry.Email, subject, body); err != nil {
+				log.Printf("waitlist: failed to send email for entry %d: %v", entry.ID, err)
+			}
+		}()
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ type RoomUsecase interface {
 	// Tambahkan parameter baseURL
 	Update(id int, room entities.RoomRequest, baseURL string) (entities.RoomRequest, error)
 	Delete(id int) error
+	GetCheckInToken(id int) (string, error)
 }
 
 type roomUsecase struct {
@@ -31,6 +32,9 @@ func (u *roomUsecase) Create(room entities.RoomRequest, baseURL string) (entitie
 	if room.Name == "" || room.Type == "" || room.Capacity <= 0 || room.PricePerHour <= 0 {
 		return room, errors.New("invalid room data")
 	}
+	if negativeBuffer(room) {
+		return room, errors.New("buffer minutes cannot be negative")
+	}
 
 	// 2. Logic Gambar
 	if room.ImageURL != "" {
@@ -92,6 +96,9 @@ func (u *roomUsecase) Update(id int, room entities.RoomRequest, baseURL string)
 	if room.PricePerHour <= 0 {
 		return room, errors.New("price per hour must be larger more than 0")
 	}
+	if negativeBuffer(room) {
+		return room, errors.New("buffer minutes cannot be negative")
+	}
 
 	oldRoom, err := u.roomRepo.GetByID(id)
 	if err != nil {
@@ -109,6 +116,14 @@ func (u *roomUsecase) Update(id int, room entities.RoomRequest, baseURL string)
 		room.ImageURL = oldRoom.PictureURL
 	}
 
+	// Buffer yang tidak dikirim tetap pakai nilai lama
+	if room.SetupBufferMinutes == nil {
+		room.SetupBufferMinutes = &oldRoom.SetupBufferMinutes
+	}
+	if room.TeardownBufferMinutes == nil {
+		room.TeardownBufferMinutes = &oldRoom.TeardownBufferMinutes
+	}
+
 	rowsAffected, err := u.roomRepo.Update(id, room)
 	if err != nil {
 		return room, err
@@ -138,3 +153,21 @@ func (u *roomUsecase) Delete(id int) error {
 
 	return nil
 }
+
+// GetCheckInToken: token QR untuk check-in di room, dibuat sekali lalu dipakai terus
+func (u *roomUsecase) GetCheckInToken(id int) (string, error) {
+	if _, err := u.roomRepo.GetByID(id); err != nil {
+		return "", errors.New("room not found")
+	}
+	token, err := generateRandomToken()
+	if err != nil {
+		return "", err
+	}
+	return u.roomRepo.GetOrCreateCheckInToken(id, token)
+}
+
+// negativeBuffer: true jika salah satu buffer yang dikirim bernilai negatif
+func negativeBuffer(room entities.RoomRequest) bool {
+	return (room.SetupBufferMinutes != nil && *room.SetupBufferMinutes < 0) ||
+		(room.TeardownBufferMinutes != nil && *room.TeardownBufferMinutes < 0)
+}
@@ -0,0 +1,276 @@
+package usecases
+
+import (
+	"fmt"
+	"math"
+
+	"BE-E-Meeting/app/entities"
+	"BE-E-Meeting/app/repositories"
+)
+
+type TaxUsecase interface {
+	GetTaxComponents() ([]entities.TaxComponent, error)
+	CreateTaxComponent(req entities.TaxComponentRequest) (entities.TaxComponent, error)
+	UpdateTaxComponent(id int, req entities.TaxComponentRequest) (entities.TaxComponent, error)
+	DeleteTaxComponent(id int) error
+}
+
+type taxUsecase struct {
+	taxRepo repositories.TaxRepository
+}
+
+func NewTaxUsecase(taxRepo repositories.TaxRepository) TaxUsecase {
+	return &taxUsecase{taxRepo: taxRepo}
+}
+
+func (u *taxUsecase) GetTaxComponents() ([]entities.TaxComponent, error) {
+	return u.taxRepo.GetTaxComponents()
+}
+
+// taxComponentFromRequest: default pembulatan nearest ke rupiah penuh (unit 1)
+func taxComponentFromRequest(req entities.TaxComponentRequest) entities.TaxComponent {
+	component := entities.TaxComponent{
+		Name: req.Name, Kind: req.Kind, Rate: req.Rate,
+		Rounding: req.Rounding, RoundingUnit: req.RoundingUnit, SortOrder: req.SortOrder,
+	}
+	if component.Rounding == "" {
+		component.Rounding = "nearest"
+	}
+	if component.RoundingUnit <= 0 {
+		component.RoundingUnit = 1
+	}
+	return component
+}
+
+func (u *taxUsecase) CreateTaxComponent(req entities.TaxComponentRequest) (entities.TaxComponent, error) {
+	return u.taxRepo.CreateTaxComponent(taxComponentFromRequest(req))
+}
+
+func (u *taxUsecase) UpdateTaxComponent(id int, req entities.TaxComponentRequest) (entities.TaxComponent, error) {
+	component := taxComponentFromRequest(req)
+	component.ID = id
+	return u.taxRepo.UpdateTaxComponent(component)
+}
+
+func (u *taxUsecase) DeleteTaxComponent(id int) error {
+	return u.taxRepo.DeleteTaxComponent(id)
+}
+
+// roundCharge membulatkan nominal service / pajak sesuai aturan komponen
+func roundCharge(amount float64, rounding string, unit float64) float64 {
+	if unit <= 0 {
+		unit = 0.01
+	}
+	// Toleransi kecil supaya 1000.0000001 tidak dibulatkan ke atas karena error float
+	q := amount / unit
+	switch rounding {
+	case "up":
+		q = math.Ceil(q - 1e-9)
+	case "down":
+		q = math.Floor(q + 1e-9)
+	default:
+		q = math.Round(q)
+	}
+	return roundPrice(q * unit)
+}
+
+// detailInvoiceLines: baris invoice satu room. Breakdown dari priceRoom dipakai untuk baris fee
+// per pricing rule, jika tidak ada (snapshot lama) selisih harga room jadi satu baris penyesuaian.
+func detailInvoiceLines(d entities.ReservationDetailData, breakdown []entities.PriceLine) []entities.InvoiceLine {
+	loc := businessLocation()
+	base := roundPrice(roomPrice(d.RoomPrice, d.DurationMinute))
+	lines := []entities.InvoiceLine{{
+		DetailID: d.ID, Type: "room",
+		Description: fmt.Sprintf("%s, %s - %s", d.RoomName, d.StartAt.In(loc).Format("02 Jan 2006 15:04"), d.EndAt.In(loc).Format("15:04")),
+		Quantity:    roundPrice(float64(d.DurationMinute) / 60), Unit: "hour", UnitPrice: d.RoomPrice, Amount: base,
+	}}
+
+	fees := 0.0
+	for _, p := range breakdown {
+		if p.Kind == "base" || p.Amount == 0 {
+			continue
+		}
+		lines = append(lines, entities.InvoiceLine{DetailID: d.ID, Type: "fee", Description: p.Name, Quantity: 1, UnitPrice: p.Amount, Amount: p.Amount})
+		fees += p.Amount
+	}
+	if diff := roundPrice(d.TotalRoom - base - fees); diff != 0 {
+		lines = append(lines, entities.InvoiceLine{DetailID: d.ID, Type: "fee", Description: "pricing adjustment", Quantity: 1, UnitPrice: diff, Amount: diff})
+	}
+
+	// Satu baris per item snack, unit person ditulis pax
+	for _, s := range detailSnackLines(d) {
+		unit := "pax"
+		if s.Unit == "box" {
+			unit = "box"
+		}
+		lines = append(lines, entities.InvoiceLine{
+			DetailID: d.ID, Type: "snack", Description: fmt.Sprintf("%s (%s)", s.Name, s.ServeAt.In(loc).Format("15:04")),
+			Quantity: float64(s.Quantity), Unit: unit, UnitPrice: s.Price, Amount: s.Subtotal,
+		})
+	}
+	return lines
+}
+
+// discountInvoiceLines: potongan promo / agreement sebagai baris negatif, jumlahnya = total diskon (sudah dibatasi subtotal)
+func discountInvoiceLines(discounts []entities.DiscountLine, total float64) []entities.InvoiceLine {
+	var lines []entities.InvoiceLine
+	remaining := total
+	for _, d := range discounts {
+		amount := minFloat(d.Amount, remaining)
+		if amount <= 0 {
+			continue
+		}
+		remaining = roundPrice(remaining - amount)
+
+		line := entities.InvoiceLine{Type: "discount", Quantity: 1, UnitPrice: -amount, Amount: -amount}
+		if d.Source == "agreement" {
+			line.Description = fmt.Sprintf("Company agreement %s (%s)", d.Code, d.Name)
+		} else {
+			line.Description = fmt.Sprintf("Promo %s (%s)", d.Code, d.Name)
+		}
+		if d.DiscountType == "percent" {
+			line.Rate = d.DiscountValue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// applyInvoice menghitung total header dari detail (subtotal harus sudah dihitung applyDetailTotals):
+// total - diskon, lalu service charge dari total setelah diskon, lalu pajak dari total setelah diskon + service charge.
+// Setiap baris service / pajak dibulatkan sesuai aturan komponennya.
+func applyInvoice(res *entities.ReservationData, details []entities.ReservationDetailData, discounts []entities.InvoiceLine, charges []entities.TaxComponent) {
+	for i := range details {
+		if details[i].InvoiceLines == nil {
+			details[i].InvoiceLines = detailInvoiceLines(details[i], nil)
+		}
+	}
+
+	res.InvoiceLines = nil
+	res.DiscountAmount = 0
+	for _, l := range discounts {
+		res.DiscountAmount -= l.Amount
+		res.InvoiceLines = append(res.InvoiceLines, l)
+	}
+	res.DiscountAmount = roundPrice(res.DiscountAmount)
+	net := math.Max(res.SubTotalRoom+res.SubTotalSnack-res.DiscountAmount, 0)
+
+	res.ServiceCharge, res.TaxAmount = 0, 0
+	for _, kind := range []string{"service", "tax"} {
+		base := net
+		if kind == "tax" {
+			base += res.ServiceCharge
+		}
+		for _, c := range charges {
+			if c.Kind != kind {
+				continue
+			}
+			amount := roundCharge(base*c.Rate/100, c.Rounding, c.RoundingUnit)
+			res.InvoiceLines = append(res.InvoiceLines, entities.InvoiceLine{
+				Type: kind, Description: c.Name, Quantity: 1, UnitPrice: roundPrice(base), Rate: c.Rate, Amount: amount,
+				TaxComponentID: c.ID, Rounding: c.Rounding, RoundingUnit: c.RoundingUnit,
+			})
+			if kind == "service" {
+				res.ServiceCharge += amount
+			} else {
+				res.TaxAmount += amount
+			}
+		}
+	}
+	res.ServiceCharge = roundPrice(res.ServiceCharge)
+	res.TaxAmount = roundPrice(res.TaxAmount)
+	res.Total = roundPrice(net + res.ServiceCharge + res.TaxAmount)
+}
+
+// allInvoiceLines: baris per detail lalu baris level reservasi, untuk response
+func allInvoiceLines(res entities.ReservationData, details []entities.ReservationDetailData) []entities.InvoiceLine {
+	var lines []entities.InvoiceLine
+	for _, d := range details {
+		lines = append(lines, d.InvoiceLines...)
+	}
+	return append(lines, res.InvoiceLines...)
+}
+
+// invoiceSnapshot: baris invoice yang tersimpan saat booking. Dipakai saat modify, reschedule dan
+// partial cancel supaya diskon & tarif pajak mengikuti saat booking, bukan tarif yang berlaku sekarang.
+type invoiceSnapshot struct {
+	details   map[int][]entities.InvoiceLine
+	discounts []entities.InvoiceLine
+	charges   []entities.TaxComponent
+	subtotal  float64
+}
+
+func (u *reservationUsecase) invoiceSnapshot(current entities.ReservationHistoryData) (invoiceSnapshot, error) {
+	snapshot := invoiceSnapshot{details: map[int][]entities.InvoiceLine{}, subtotal: current.SubTotalRoom + current.SubTotalSnack}
+
+	lines, err := u.resRepo.GetInvoiceLines(current.ID)
+	if err != nil {
+		return snapshot, err
+	}
+	for _, l := range lines {
+		switch {
+		case l.DetailID > 0:
+			snapshot.details[l.DetailID] = append(snapshot.details[l.DetailID], l)
+		case l.Type == "discount":
+			snapshot.discounts = append(snapshot.discounts, l)
+		case l.Type == "service" || l.Type == "tax":
+			snapshot.charges = append(snapshot.charges, entities.TaxComponent{
+				ID: l.TaxComponentID, Name: l.Description, Kind: l.Type, Rate: l.Rate, Rounding: l.Rounding, RoundingUnit: l.RoundingUnit,
+			})
+		}
+	}
+	// Reservasi yang dibuat sebelum ada baris invoice: diskon jadi satu baris
+	if len(snapshot.discounts) == 0 && current.Discount > 0 {
+		snapshot.discounts = []entities.InvoiceLine{{Type: "discount", Description: "discount", Quantity: 1, UnitPrice: -current.Discount, Amount: -current.Discount}}
+	}
+	return snapshot, nil
+}
+
+// apply menghitung ulang header dari detail baru. Detail yang tidak berubah (InvoiceLines kosong)
+// memakai baris tersimpan, diskon disesuaikan proporsional dengan subtotal baru.
+func (s invoiceSnapshot) apply(res *entities.ReservationData, details []entities.ReservationDetailData) {
+	applyDetailTotals(res, details)
+	for i, d := range details {
+		if d.InvoiceLines == nil && d.ID > 0 {
+			details[i].InvoiceLines = s.details[d.ID]
+		}
+	}
+
+	before := 0.0
+	for _, l := range s.discounts {
+		before -= l.Amount
+	}
+	target := scaleDiscount(before, s.subtotal, res.SubTotalRoom+res.SubTotalSnack)
+	var discounts []entities.InvoiceLine
+	remaining := target
+	for i, l := range s.discounts {
+		if before <= 0 {
+			break
+		}
+		amount := roundPrice(-l.Amount * target / before)
+		if i == len(s.discounts)-1 {
+			amount = remaining
+		}
+		remaining = roundPrice(remaining - amount)
+		l.ID, l.UnitPrice, l.Amount = 0, -amount, -amount
+		discounts = append(discounts, l)
+	}
+	applyInvoice(res, details, discounts, s.charges)
+}
+
+// applyCurrentCharges: invoice reservasi baru dengan tax component yang berlaku sekarang
+func (u *reservationUsecase) applyCurrentCharges(res *entities.ReservationData, details []entities.ReservationDetailData, discounts []entities.InvoiceLine) error {
+	charges, err := u.taxRepo.GetTaxComponents()
+	if err != nil {
+		return err
+	}
+	applyInvoice(res, details, discounts, charges)
+	return nil
+}
+
+func historySummary(h entities.ReservationHistoryData) entities.PriceSummary {
+	return entities.PriceSummary{
+		SubTotalRoom: h.SubTotalRoom, SubTotalSnack: h.SubTotalSnack, Discount: h.Discount,
+		ServiceCharge: h.ServiceCharge, Tax: h.Tax, Total: h.Total,
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 
@@ -53,3 +54,36 @@ func SendResetEmail(toEmail, resetToken string) error {
 
 	return d.DialAndSend(m)
 }
+
+// SendNotificationEmail mengirim email notifikasi (HTML) memakai konfigurasi SMTP yang sama
+func SendNotificationEmail(toEmail, subject, htmlBody string) error {
+	return SendEmailWithAttachment(toEmail, subject, htmlBody, "", "", nil)
+}
+
+// SendEmailWithAttachment sama dengan SendNotificationEmail plus satu lampiran (mis. invoice PDF).
+// fileName kosong = tanpa lampiran.
+func SendEmailWithAttachment(toEmail, subject, htmlBody, fileName, contentType string, content []byte) error {
+	smtpPort, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil {
+		return err
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", os.Getenv("SMTP_FROM"))
+	m.SetHeader("To", toEmail)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/html", htmlBody)
+	if fileName != "" {
+		m.Attach(fileName,
+			gomail.SetHeader(map[string][]string{"Content-Type": {contentType}}),
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(content)
+				return err
+			}))
+	}
+
+	d := gomail.NewDialer(os.Getenv("SMTP_HOST"), smtpPort, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASS"))
+	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+
+	return d.DialAndSend(m)
+}
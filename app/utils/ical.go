@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ICSEvent adalah data minimal untuk satu VEVENT (RFC 5545)
+type ICSEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Status      string // CONFIRMED, TENTATIVE, CANCELLED
+	Sequence    int
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time
+}
+
+const icsTimeFormat = "20060102T150405Z"
+
+// BuildICS menyusun file iCalendar (VCALENDAR) dari daftar event
+func BuildICS(calendarName string, events []ICSEvent) string {
+	var b strings.Builder
+
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//E-Meeting//Reservation//EN")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:"+escapeICSText(calendarName))
+
+	for _, e := range events {
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:"+e.UID)
+		writeICSLine(&b, "DTSTAMP:"+e.Stamp.UTC().Format(icsTimeFormat))
+		writeICSLine(&b, "DTSTART:"+e.Start.UTC().Format(icsTimeFormat))
+		writeICSLine(&b, "DTEND:"+e.End.UTC().Format(icsTimeFormat))
+		writeICSLine(&b, fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		writeICSLine(&b, "SUMMARY:"+escapeICSText(e.Summary))
+		if e.Description != "" {
+			writeICSLine(&b, "DESCRIPTION:"+escapeICSText(e.Description))
+		}
+		if e.Location != "" {
+			writeICSLine(&b, "LOCATION:"+escapeICSText(e.Location))
+		}
+		if e.Status != "" {
+			writeICSLine(&b, "STATUS:"+e.Status)
+		}
+		writeICSLine(&b, "END:VEVENT")
+	}
+
+	writeICSLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// writeICSLine menulis satu content line dengan CRLF, dilipat per 75 octet
+func writeICSLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Jangan memotong di tengah karakter UTF-8
+		for cut > 0 && !isUTF8Start(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Baris lanjutan diawali spasi, jadi sisa ruangnya 74 octet
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isUTF8Start(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+func escapeICSText(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`;`, `\;`,
+		`,`, `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(s)
+}
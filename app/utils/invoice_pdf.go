@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// InvoiceDocument: isi invoice / receipt yang sudah diformat (tanggal dalam zona waktu bisnis)
+type InvoiceDocument struct {
+	Title  string // INVOICE / RECEIPT
+	Issuer []string
+	BillTo []string
+	// Info di kanan atas, mis. {"Invoice No", "INV/2026/000001"}
+	Meta   [][2]string
+	Lines  []InvoiceDocumentLine
+	Totals []InvoiceDocumentTotal
+	// Teks cap di bawah total, mis. "PAID - 16 Oct 2026 10:00"
+	Stamp string
+	Notes []string
+}
+
+type InvoiceDocumentLine struct {
+	Description string
+	Quantity    string
+	UnitPrice   float64
+	Amount      float64
+}
+
+type InvoiceDocumentTotal struct {
+	Label  string
+	Amount float64
+	Bold   bool
+}
+
+const (
+	invoiceMargin     = 48.0
+	invoiceLineHeight = 16.0
+)
+
+// BuildInvoicePDF menyusun invoice satu kolom: penerbit & info invoice, penerima tagihan,
+// tabel baris (pindah halaman jika penuh), total, cap (receipt) dan catatan
+func BuildInvoicePDF(doc InvoiceDocument) []byte {
+	pdf := NewPDF()
+	right := PDFPageWidth - invoiceMargin
+
+	y := invoiceMargin + 10
+	pdf.Text(invoiceMargin, y, 20, PDFFontBold, doc.Title)
+	metaY := y
+	for _, m := range doc.Meta {
+		pdf.TextRight(right-130, metaY, 9, PDFFontRegular, m[0])
+		pdf.TextRight(right, metaY, 9, PDFFontBold, m[1])
+		metaY += 13
+	}
+
+	y += 24
+	for i, line := range doc.Issuer {
+		font := PDFFontRegular
+		if i == 0 {
+			font = PDFFontBold
+		}
+		pdf.Text(invoiceMargin, y, 9, font, line)
+		y += 12
+	}
+
+	y = math.Max(y, metaY) + 16
+	pdf.Text(invoiceMargin, y, 9, PDFFontBold, "Bill To")
+	y += 13
+	for _, line := range doc.BillTo {
+		pdf.Text(invoiceMargin, y, 9, PDFFontRegular, line)
+		y += 12
+	}
+
+	// Kolom tabel: deskripsi | qty | harga satuan | jumlah
+	colQty, colUnit := right-210, right-95
+	tableHeader := func() {
+		y += 10
+		pdf.FillRect(invoiceMargin, y-11, right-invoiceMargin, 16, 0.9)
+		pdf.Text(invoiceMargin+4, y, 9, PDFFontBold, "Description")
+		pdf.TextRight(colQty, y, 9, PDFFontBold, "Qty")
+		pdf.TextRight(colUnit, y, 9, PDFFontBold, "Unit Price")
+		pdf.TextRight(right-4, y, 9, PDFFontBold, "Amount")
+		y += invoiceLineHeight + 2
+	}
+	tableHeader()
+
+	for _, line := range doc.Lines {
+		if y > PDFPageHeight-invoiceMargin-40 {
+			pdf.AddPage()
+			y = invoiceMargin
+			tableHeader()
+		}
+		pdf.Text(invoiceMargin+4, y, 9, PDFFontRegular, PDFFitText(line.Description, 9, colQty-invoiceMargin-60))
+		pdf.TextRight(colQty, y, 9, PDFFontRegular, line.Quantity)
+		pdf.TextRight(colUnit, y, 9, PDFFontRegular, FormatRupiah(line.UnitPrice))
+		pdf.TextRight(right-4, y, 9, PDFFontRegular, FormatRupiah(line.Amount))
+		y += invoiceLineHeight
+	}
+
+	if y > PDFPageHeight-invoiceMargin-float64(len(doc.Totals)+len(doc.Notes)+3)*invoiceLineHeight {
+		pdf.AddPage()
+		y = invoiceMargin
+	}
+	pdf.Line(invoiceMargin, y-10, right, y-10, 0.5)
+	y += 4
+	for _, t := range doc.Totals {
+		font := PDFFontRegular
+		if t.Bold {
+			font = PDFFontBold
+		}
+		pdf.TextRight(colUnit, y, 9, font, t.Label)
+		pdf.TextRight(right-4, y, 9, font, FormatRupiah(t.Amount))
+		y += invoiceLineHeight
+	}
+
+	if doc.Stamp != "" {
+		y += 10
+		pdf.Text(invoiceMargin, y, 14, PDFFontBold, doc.Stamp)
+		y += 10
+	}
+	y += 14
+	for _, note := range doc.Notes {
+		pdf.Text(invoiceMargin, y, 8, PDFFontRegular, note)
+		y += 11
+	}
+	return pdf.Bytes()
+}
+
+// FormatRupiah: 1234567.5 -> "Rp 1.234.567,50", sen hanya ditampilkan jika ada
+func FormatRupiah(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	cents := int64(math.Round(amount * 100))
+	whole := fmt.Sprintf("%d", cents/100)
+
+	var b strings.Builder
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+	if cents%100 != 0 {
+		fmt.Fprintf(&b, ",%02d", cents%100)
+	}
+	return sign + "Rp " + b.String()
+}
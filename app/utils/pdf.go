@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PDF adalah penulis PDF 1.4 minimal (teks, garis, kotak) dengan font standar Helvetica,
+// cukup untuk dokumen sederhana seperti invoice tanpa library tambahan.
+// Koordinat dalam point (1/72 inch) dan Y dihitung dari atas halaman.
+type PDF struct {
+	pages []*bytes.Buffer
+}
+
+// Ukuran kertas A4 dalam point
+const (
+	PDFPageWidth  = 595.28
+	PDFPageHeight = 841.89
+)
+
+// Nama resource font di setiap halaman
+const (
+	PDFFontRegular = "F1"
+	PDFFontBold    = "F2"
+)
+
+func NewPDF() *PDF {
+	p := &PDF{}
+	p.AddPage()
+	return p
+}
+
+func (p *PDF) AddPage() {
+	p.pages = append(p.pages, &bytes.Buffer{})
+}
+
+func (p *PDF) current() *bytes.Buffer {
+	return p.pages[len(p.pages)-1]
+}
+
+// Text menulis teks dengan baseline di (x, y)
+func (p *PDF) Text(x, y, size float64, font, text string) {
+	fmt.Fprintf(p.current(), "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PDFPageHeight-y, pdfEscape(text))
+}
+
+// TextRight menulis teks rata kanan, berakhir di x
+func (p *PDF) TextRight(x, y, size float64, font, text string) {
+	p.Text(x-PDFTextWidth(text, size), y, size, font, text)
+}
+
+func (p *PDF) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(p.current(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, PDFPageHeight-y1, x2, PDFPageHeight-y2)
+}
+
+// FillRect mengisi kotak dengan warna abu-abu (0 = hitam, 1 = putih), (x, y) = pojok kiri atas
+func (p *PDF) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(p.current(), "%.2f g %.2f %.2f %.2f %.2f re f 0 g\n", gray, x, PDFPageHeight-y-h, w, h)
+}
+
+// helveticaWidths: lebar karakter ASCII 32-126 font Helvetica (per 1000 unit), dari AFM standar.
+// Dipakai juga untuk Helvetica-Bold sebagai perkiraan (angka & tanda baca sama lebarnya).
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// PDFTextWidth: lebar teks dalam point untuk ukuran font tertentu
+func PDFTextWidth(text string, size float64) float64 {
+	total := 0
+	for _, r := range text {
+		if r >= 32 && r <= 126 {
+			total += helveticaWidths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// PDFFitText memotong teks (dengan "...") supaya tidak lebih lebar dari maxWidth
+func PDFFitText(text string, size, maxWidth float64) string {
+	if PDFTextWidth(text, size) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && PDFTextWidth(string(runes)+"...", size) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "..."
+}
+
+// pdfEscape: string PDF memakai WinAnsiEncoding, karakter di luar Latin-1 diganti "?"
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 32 || r > 255:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
+
+// Bytes menyusun file PDF: catalog, pages, 2 font, lalu page + content stream per halaman
+func (p *PDF) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objek 1-4 tetap, halaman mulai dari objek 5 (page, content, page, content, ...)
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			PDFPageWidth, PDFPageHeight, PDFFontRegular, PDFFontBold, 6+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
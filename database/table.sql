@@ -3,7 +3,7 @@
 -- ==============================
 
 CREATE TYPE user_status AS ENUM ('active', 'inactive', 'suspended');
-CREATE TYPE status_reservation AS ENUM ('booked', 'paid', 'cancel');
+CREATE TYPE status_reservation AS ENUM ('booked', 'paid', 'cancel', 'refunded', 'hold', 'pending');
 CREATE TYPE user_role AS ENUM ('admin', 'user');
 CREATE TYPE snack_unit AS ENUM ('person', 'box');
 CREATE TYPE room_type AS ENUM ('small', 'medium', 'large');
@@ -117,3 +117,454 @@ CREATE TABLE reservation_details (
     updated_at TIMESTAMPTZ
 );
 
+-- ==============================
+-- TABLE: reservation_status_histories
+-- ==============================
+
+CREATE TABLE reservation_status_histories (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT REFERENCES reservations(id) ON DELETE CASCADE,
+    changed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    from_status status_reservation NOT NULL,
+    to_status status_reservation NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- ==============================
+-- TABLE: reservation_series
+-- ==============================
+
+CREATE TYPE recurrence_frequency AS ENUM ('daily', 'weekly', 'monthly');
+
+CREATE TABLE reservation_series (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    frequency recurrence_frequency NOT NULL,
+    interval_value INT NOT NULL DEFAULT 1,
+    by_weekday VARCHAR(50),
+    until_at TIMESTAMPTZ,
+    occurrence_count INT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+ALTER TABLE reservations ADD COLUMN series_id INT REFERENCES reservation_series(id) ON DELETE SET NULL;
+ALTER TABLE reservations ADD COLUMN occurrence_start TIMESTAMPTZ;
+
+-- ==============================
+-- TABLE: calendar_feed_tokens
+-- ==============================
+
+ALTER TABLE reservation_details ADD COLUMN sequence INT NOT NULL DEFAULT 0;
+
+CREATE TYPE calendar_feed_type AS ENUM ('user', 'room');
+
+CREATE TABLE calendar_feed_tokens (
+    id SERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    feed_type calendar_feed_type NOT NULL,
+    owner_id INT NOT NULL,
+    created_by INT REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- ==============================
+-- TABLE: reservation_change_histories
+-- ==============================
+
+CREATE TABLE reservation_change_histories (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT REFERENCES reservations(id) ON DELETE CASCADE,
+    changed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    before_total DECIMAL(14,2),
+    after_total DECIMAL(14,2),
+    before_details JSONB,
+    after_details JSONB,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+
+-- ==============================
+-- Partial cancellation per reservation_details
+-- ==============================
+
+ALTER TABLE reservation_details ADD COLUMN cancelled_at TIMESTAMPTZ;
+ALTER TABLE reservation_details ADD COLUMN cancel_reason TEXT;
+
+-- ==============================
+-- TABLE: cancellation_policies
+-- ==============================
+
+CREATE TABLE cancellation_policies (
+    id SERIAL PRIMARY KEY,
+    room_type room_type,
+    free_cancel_hours INT NOT NULL DEFAULT 24,
+    late_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 50,
+    no_show_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 100,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_cancellation_policies_room_type ON cancellation_policies(room_type);
+CREATE UNIQUE INDEX idx_cancellation_policies_global ON cancellation_policies((room_type IS NULL)) WHERE room_type IS NULL;
+
+INSERT INTO cancellation_policies (room_type, free_cancel_hours, late_fee_percent, no_show_fee_percent) VALUES (NULL, 24, 50, 100);
+
+ALTER TABLE reservations ADD COLUMN cancellation_fee DECIMAL(14,2) NOT NULL DEFAULT 0;
+ALTER TABLE reservations ADD COLUMN refund_amount DECIMAL(14,2) NOT NULL DEFAULT 0;
+
+-- ==============================
+-- Tentative hold (status 'hold')
+-- ==============================
+
+ALTER TABLE reservations ADD COLUMN hold_expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_reservations_hold_expires_at ON reservations(hold_expires_at) WHERE hold_expires_at IS NOT NULL;
+
+-- ==============================
+-- TABLE: reservation_waitlists
+-- ==============================
+
+CREATE TYPE waitlist_status AS ENUM ('waiting', 'offered', 'booked', 'expired', 'cancelled');
+
+CREATE TABLE reservation_waitlists (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    room_id INT REFERENCES rooms(id) ON DELETE CASCADE,
+    start_at TIMESTAMPTZ NOT NULL,
+    end_at TIMESTAMPTZ NOT NULL,
+    participant INT NOT NULL,
+    snack_id INT REFERENCES snacks(id) ON DELETE SET NULL,
+    contact_name VARCHAR(100),
+    contact_phone VARCHAR(50),
+    contact_company VARCHAR(255),
+    note TEXT,
+    auto_book BOOLEAN NOT NULL DEFAULT false,
+    status waitlist_status NOT NULL DEFAULT 'waiting',
+    -- reservasi yang dibuat saat slot ditawarkan (hold) atau langsung dibooking
+    reservation_id INT REFERENCES reservations(id) ON DELETE SET NULL,
+    offer_expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_reservation_waitlists_room ON reservation_waitlists(room_id, status, start_at);
+CREATE INDEX idx_reservation_waitlists_reservation ON reservation_waitlists(reservation_id);
+
+-- ==============================
+-- Check-in & no-show
+-- ==============================
+
+ALTER TABLE reservation_details ADD COLUMN checked_in_at TIMESTAMPTZ;
+ALTER TABLE reservation_details ADD COLUMN no_show_at TIMESTAMPTZ;
+
+CREATE INDEX idx_reservation_details_no_show ON reservation_details(start_at) WHERE checked_in_at IS NULL AND no_show_at IS NULL;
+
+ALTER TABLE rooms ADD COLUMN checkin_token VARCHAR(64) UNIQUE;
+
+-- ==============================
+-- Buffer setup / teardown per room
+-- ==============================
+
+ALTER TABLE rooms ADD COLUMN setup_buffer_minutes INT NOT NULL DEFAULT 0 CHECK (setup_buffer_minutes >= 0);
+ALTER TABLE rooms ADD COLUMN teardown_buffer_minutes INT NOT NULL DEFAULT 0 CHECK (teardown_buffer_minutes >= 0);
+
+-- ==============================
+-- TABLE: room_operating_hours (room_id NULL = default global)
+-- ==============================
+
+CREATE TABLE room_operating_hours (
+    id SERIAL PRIMARY KEY,
+    room_id INT REFERENCES rooms(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6), -- 0 = Minggu
+    open_time TIME NOT NULL DEFAULT '00:00',
+    close_time TIME NOT NULL DEFAULT '24:00',
+    is_closed BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (is_closed OR close_time > open_time)
+);
+
+CREATE UNIQUE INDEX idx_room_operating_hours_room ON room_operating_hours(room_id, weekday) WHERE room_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_room_operating_hours_global ON room_operating_hours(weekday) WHERE room_id IS NULL;
+
+INSERT INTO room_operating_hours (room_id, weekday, open_time, close_time)
+SELECT NULL, d, '07:00', '22:00' FROM generate_series(0, 6) AS d;
+
+-- ==============================
+-- TABLE: blackout_dates (room_id NULL = semua room)
+-- ==============================
+
+CREATE TABLE blackout_dates (
+    id SERIAL PRIMARY KEY,
+    room_id INT REFERENCES rooms(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_blackout_dates_range ON blackout_dates(start_date, end_date);
+
+-- ==============================
+-- TABLE: booking_rules (room_type NULL = aturan global, 0 = tidak dibatasi)
+-- ==============================
+
+CREATE TABLE booking_rules (
+    id SERIAL PRIMARY KEY,
+    room_type room_type,
+    min_lead_minutes INT NOT NULL DEFAULT 0 CHECK (min_lead_minutes >= 0),
+    max_horizon_days INT NOT NULL DEFAULT 90 CHECK (max_horizon_days >= 0),
+    min_duration_minutes INT NOT NULL DEFAULT 0 CHECK (min_duration_minutes >= 0),
+    max_duration_minutes INT NOT NULL DEFAULT 0 CHECK (max_duration_minutes >= 0),
+    slot_minutes INT NOT NULL DEFAULT 15 CHECK (slot_minutes IN (0, 5, 10, 15, 30, 60)),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_booking_rules_room_type ON booking_rules(room_type);
+CREATE UNIQUE INDEX idx_booking_rules_global ON booking_rules((room_type IS NULL)) WHERE room_type IS NULL;
+
+INSERT INTO booking_rules (room_type, min_lead_minutes, max_horizon_days, min_duration_minutes, max_duration_minutes, slot_minutes)
+VALUES (NULL, 0, 90, 15, 0, 15);
+
+-- Toleransi peserta melebihi kapasitas room (persen)
+ALTER TABLE booking_rules ADD COLUMN capacity_overflow_percent INT NOT NULL DEFAULT 0 CHECK (capacity_overflow_percent BETWEEN 0 AND 100);
+
+-- ==============================
+-- Approval workflow (status 'pending')
+-- ==============================
+
+ALTER TABLE reservations ADD COLUMN approval_reason TEXT;
+
+ALTER TABLE booking_rules ADD COLUMN requires_approval BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE booking_rules ADD COLUMN approval_price_threshold DECIMAL(14,2) NOT NULL DEFAULT 0 CHECK (approval_price_threshold >= 0);
+
+INSERT INTO booking_rules (room_type, min_lead_minutes, max_horizon_days, min_duration_minutes, max_duration_minutes,
+    slot_minutes, capacity_overflow_percent, requires_approval, approval_price_threshold)
+SELECT 'large', min_lead_minutes, max_horizon_days, min_duration_minutes, max_duration_minutes,
+    slot_minutes, capacity_overflow_percent, true, approval_price_threshold
+FROM booking_rules WHERE room_type IS NULL
+ON CONFLICT (room_type) DO UPDATE SET requires_approval = true;
+
+-- ==============================
+-- TABLE: pricing_rules (room_type NULL = semua room)
+-- ==============================
+
+CREATE TABLE pricing_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('time_band', 'weekend', 'holiday', 'package', 'long_booking')),
+    room_type room_type,
+    weekdays INT[] NOT NULL DEFAULT '{}',
+    start_time TIME,
+    end_time TIME,
+    start_date DATE,
+    end_date DATE,
+    multiplier DECIMAL(6,3) NOT NULL DEFAULT 1 CHECK (multiplier >= 0),
+    duration_minutes INT NOT NULL DEFAULT 0 CHECK (duration_minutes >= 0),
+    package_price DECIMAL(14,2) NOT NULL DEFAULT 0 CHECK (package_price >= 0),
+    discount_percent DECIMAL(5,2) NOT NULL DEFAULT 0 CHECK (discount_percent BETWEEN 0 AND 100),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_pricing_rules_room_type ON pricing_rules(room_type);
+
+-- ==============================
+-- TABLE: promo_codes & company_agreements
+-- ==============================
+
+CREATE TABLE promo_codes (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    discount_value DECIMAL(14,2) NOT NULL CHECK (discount_value > 0),
+    valid_from TIMESTAMPTZ,
+    valid_until TIMESTAMPTZ,
+    usage_limit INT NOT NULL DEFAULT 0 CHECK (usage_limit >= 0),
+    per_user_limit INT NOT NULL DEFAULT 0 CHECK (per_user_limit >= 0),
+    room_types room_type[] NOT NULL DEFAULT '{}',
+    snack_ids INT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_promo_codes_code ON promo_codes(UPPER(code));
+
+CREATE TABLE company_agreements (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    company_name VARCHAR(100) NOT NULL,
+    discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    discount_value DECIMAL(14,2) NOT NULL CHECK (discount_value > 0),
+    valid_from TIMESTAMPTZ,
+    valid_until TIMESTAMPTZ,
+    usage_limit INT NOT NULL DEFAULT 0 CHECK (usage_limit >= 0),
+    room_types room_type[] NOT NULL DEFAULT '{}',
+    snack_ids INT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_company_agreements_code ON company_agreements(UPPER(code));
+
+ALTER TABLE reservations ADD COLUMN promo_code_id INT REFERENCES promo_codes(id) ON DELETE SET NULL;
+ALTER TABLE reservations ADD COLUMN company_agreement_id INT REFERENCES company_agreements(id) ON DELETE SET NULL;
+ALTER TABLE reservations ADD COLUMN discount_amount DECIMAL(14,2) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_reservations_promo_code ON reservations(promo_code_id) WHERE promo_code_id IS NOT NULL;
+CREATE INDEX idx_reservations_company_agreement ON reservations(company_agreement_id) WHERE company_agreement_id IS NOT NULL;
+
+-- ==============================
+-- TABLE: tax_components & reservation_invoice_lines
+-- ==============================
+
+CREATE TABLE tax_components (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('service', 'tax')),
+    rate DECIMAL(5,2) NOT NULL CHECK (rate > 0 AND rate <= 100),
+    rounding VARCHAR(10) NOT NULL DEFAULT 'nearest' CHECK (rounding IN ('nearest', 'up', 'down')),
+    rounding_unit DECIMAL(14,2) NOT NULL DEFAULT 1 CHECK (rounding_unit > 0),
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+ALTER TABLE reservations ADD COLUMN service_charge DECIMAL(14,2) NOT NULL DEFAULT 0;
+ALTER TABLE reservations ADD COLUMN tax_amount DECIMAL(14,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE reservation_invoice_lines (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    reservation_detail_id INT REFERENCES reservation_details(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    line_type VARCHAR(10) NOT NULL CHECK (line_type IN ('room', 'fee', 'snack', 'discount', 'service', 'tax')),
+    description VARCHAR(255) NOT NULL,
+    quantity DECIMAL(10,2) NOT NULL DEFAULT 1,
+    unit VARCHAR(10),
+    unit_price DECIMAL(14,2) NOT NULL DEFAULT 0,
+    rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    amount DECIMAL(14,2) NOT NULL,
+    tax_component_id INT REFERENCES tax_components(id) ON DELETE SET NULL,
+    rounding VARCHAR(10),
+    rounding_unit DECIMAL(14,2),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_reservation_invoice_lines_reservation ON reservation_invoice_lines(reservation_id, position);
+
+-- ==============================
+-- TABLE: invoice_sequences & reservation_invoices
+-- ==============================
+
+CREATE TABLE invoice_sequences (
+    kind VARCHAR(10) NOT NULL,
+    year INT NOT NULL,
+    last_number INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (kind, year)
+);
+
+CREATE TABLE reservation_invoices (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('invoice', 'receipt')),
+    invoice_number VARCHAR(30) NOT NULL UNIQUE,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (reservation_id, kind)
+);
+
+-- ==============================
+-- TABLE: reservation_payments
+-- Payment intent ke payment provider untuk total reservasi. Satu intent pending per reservasi,
+-- status berubah lewat webhook (paid / failed / expired)
+-- ==============================
+
+CREATE TABLE reservation_payments (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    provider VARCHAR(30) NOT NULL,
+    reference VARCHAR(50) NOT NULL UNIQUE,
+    provider_reference VARCHAR(100),
+    amount DECIMAL(14,2) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'failed', 'expired')),
+    payment_url TEXT,
+    expires_at TIMESTAMPTZ,
+    paid_amount DECIMAL(14,2),
+    paid_at TIMESTAMPTZ,
+    failure_reason TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_reservation_payments_pending ON reservation_payments(reservation_id) WHERE status = 'pending';
+
+-- ==============================
+-- TABLE: payment_webhook_events
+-- Event webhook yang sudah diproses, event yang dikirim ulang provider diabaikan
+-- ==============================
+
+CREATE TABLE payment_webhook_events (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(30) NOT NULL,
+    event_id VARCHAR(100) NOT NULL,
+    reference VARCHAR(50) NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    amount DECIMAL(14,2) NOT NULL DEFAULT 0,
+    payload TEXT,
+    received_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (provider, event_id)
+);
+
+-- Referensi & jumlah pembayaran yang membuat reservasi menjadi paid
+ALTER TABLE reservations ADD COLUMN payment_reference VARCHAR(50);
+ALTER TABLE reservations ADD COLUMN paid_amount DECIMAL(14,2);
+ALTER TABLE reservations ADD COLUMN paid_at TIMESTAMPTZ;
+
+-- ==============================
+-- Snapshot room di reservation_details
+-- History & detail reservasi dibaca dari snapshot (nama, tipe, harga), bukan dari rooms,
+-- jadi edit / hapus room tidak mengubah atau menghilangkan booking lama
+-- ==============================
+
+ALTER TABLE reservation_details ADD COLUMN room_type VARCHAR(20);
+
+UPDATE reservation_details rd SET room_type = rm.room_type::text
+FROM rooms rm WHERE rm.id = rd.room_id;
+
+-- Hapus room tidak lagi menghapus detail reservasi, room_id menjadi NULL
+ALTER TABLE reservation_details DROP CONSTRAINT IF EXISTS reservation_details_room_id_fkey;
+ALTER TABLE reservation_details ADD CONSTRAINT reservation_details_room_id_fkey
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE SET NULL;
+
+-- ==============================
+-- TABLE: reservation_snack_lines
+-- Beberapa item snack per room yang dibooking (snapshot nama, unit & harga saat booking),
+-- harga = snack_price x quantity. Kolom snack_* di reservation_details = item pertama.
+-- ==============================
+
+CREATE TABLE reservation_snack_lines (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    reservation_detail_id INT NOT NULL REFERENCES reservation_details(id) ON DELETE CASCADE,
+    snack_id INT REFERENCES snacks(id) ON DELETE SET NULL,
+    snack_name VARCHAR(100) NOT NULL,
+    snack_unit VARCHAR(10) NOT NULL DEFAULT 'person',
+    snack_price DECIMAL(12,2) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    serve_at TIMESTAMPTZ NOT NULL,
+    subtotal DECIMAL(14,2) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_reservation_snack_lines_detail ON reservation_snack_lines(reservation_detail_id, serve_at);
+
+-- Data lama: satu snack x jumlah peserta, disajikan di jam mulai
+INSERT INTO reservation_snack_lines (reservation_id, reservation_detail_id, snack_id, snack_name, snack_unit, snack_price, quantity, serve_at, subtotal, created_at)
+SELECT rd.reservation_id, rd.id, rd.snack_id, rd.snack_name, 'person', rd.snack_price, rd.total_participants, rd.start_at, rd.total_snack, NOW()
+FROM reservation_details rd
+WHERE rd.snack_name <> '' AND COALESCE(rd.total_snack, 0) > 0 AND COALESCE(rd.total_participants, 0) > 0;
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"BE-E-Meeting/app/config"
 	"BE-E-Meeting/app/handler"
@@ -82,29 +83,59 @@ func main() {
 	// Google Oauth
 	googleConfig := config.LoadGoogleConfig()
 
+	// Payment provider (fake / gateway)
+	paymentProvider := config.LoadPaymentProvider()
+
 	// Repositories
 	userRepo := repositories.NewUserRepository(db)
 	roomRepo := repositories.NewRoomRepository(db)
 	snackRepo := repositories.NewSnackRepository(db)
 	resRepo := repositories.NewReservationRepository(db)
 	dashboardRepo := repositories.NewDashboardRepository(db)
+	calendarRepo := repositories.NewCalendarRepository(db)
+	policyRepo := repositories.NewPolicyRepository(db)
+	waitlistRepo := repositories.NewWaitlistRepository(db)
+	openingRepo := repositories.NewOpeningHoursRepository(db)
+	pricingRepo := repositories.NewPricingRepository(db)
+	discountRepo := repositories.NewDiscountRepository(db)
+	taxRepo := repositories.NewTaxRepository(db)
+	invoiceRepo := repositories.NewInvoiceRepository(db)
+	paymentRepo := repositories.NewPaymentRepository(db)
 
 	// Usecases
 	userUsecase := usecases.NewUserUsecase(userRepo)
 	roomUsecase := usecases.NewRoomUsecase(roomRepo)
 	snackUsecase := usecases.NewSnackUsecase(snackRepo)
-	resUsecase := usecases.NewReservationUsecase(resRepo, roomRepo, snackRepo)
+	resUsecase := usecases.NewReservationUsecase(resRepo, roomRepo, snackRepo, policyRepo, waitlistRepo, openingRepo, pricingRepo, discountRepo, taxRepo, invoiceRepo, paymentRepo, paymentProvider)
 	dashboardUsecase := usecases.NewDashboardUsecase(dashboardRepo)
 	authUsecase := usecases.NewAuthUsecase(userRepo, googleConfig)
+	calendarUsecase := usecases.NewCalendarUsecase(calendarRepo, roomRepo)
+	policyUsecase := usecases.NewPolicyUsecase(policyRepo)
+	openingUsecase := usecases.NewOpeningHoursUsecase(openingRepo, roomRepo)
+	pricingUsecase := usecases.NewPricingUsecase(pricingRepo)
+	discountUsecase := usecases.NewDiscountUsecase(discountRepo)
+	taxUsecase := usecases.NewTaxUsecase(taxRepo)
 
 	// Handlers
 	userHandler := handler.NewUserHandler(userUsecase)
 	roomHandler := handler.NewRoomHandler(roomUsecase)
 	snackHandler := handler.NewSnackHandler(snackUsecase)
-	resHandler := handler.NewReservationHandler(resUsecase)
+	resHandler := handler.NewReservationHandler(resUsecase, calendarUsecase)
 	dashboardHandler := handler.NewDashboardHandler(dashboardUsecase)
 	fileHandler := handler.NewFileHandler()
 	authHandler := handler.NewAuthHandler(authUsecase)
+	calendarHandler := handler.NewCalendarHandler(calendarUsecase, resUsecase)
+	waitlistHandler := handler.NewWaitlistHandler(resUsecase)
+	policyHandler := handler.NewPolicyHandler(policyUsecase)
+	openingHandler := handler.NewOpeningHoursHandler(openingUsecase)
+	pricingHandler := handler.NewPricingHandler(pricingUsecase)
+	discountHandler := handler.NewDiscountHandler(discountUsecase)
+	taxHandler := handler.NewTaxHandler(taxUsecase)
+	paymentHandler := handler.NewPaymentHandler(resUsecase)
+
+	// Background worker: lepas hold yang sudah expired & tandai no-show setiap menit
+	go usecases.RunHoldExpiryWorker(resUsecase, time.Minute)
+	go usecases.RunNoShowWorker(resUsecase, time.Minute)
 
 	// ==========================================
 	// ROUTES
@@ -129,7 +160,10 @@ func main() {
 	// --- ROOM ---
 	e.POST("/rooms", roomHandler.CreateRoom, middleware.RoleAuthMiddleware("admin"))
 	e.GET("/rooms", roomHandler.GetRooms, middleware.RoleAuthMiddleware("admin", "user"))
+	e.GET("/rooms/available", resHandler.SearchAvailableRooms, middleware.RoleAuthMiddleware("admin", "user"))
 	e.GET("/rooms/:id", roomHandler.GetRoomByID, middleware.RoleAuthMiddleware("admin", "user"))
+	e.GET("/rooms/:id/check-in-token", roomHandler.GetRoomCheckInToken, middleware.RoleAuthMiddleware("admin"))
+	e.POST("/rooms/check-in/:token", resHandler.CheckInByRoomToken, middleware.RoleAuthMiddleware("admin", "user"))
 	e.PUT("/rooms/:id", roomHandler.UpdateRoom, middleware.RoleAuthMiddleware("admin"))
 	e.DELETE("/rooms/:id", roomHandler.DeleteRoom, middleware.RoleAuthMiddleware("admin"))
 	// Endpoint Legacy yang sudah dipindah ke Reservation Handler:
@@ -144,8 +178,71 @@ func main() {
 	e.GET("/reservation/history", resHandler.GetHistory, middleware.RoleAuthMiddleware("user"))
 	e.PUT("/reservation/status", resHandler.UpdateReservationStatus, middleware.RoleAuthMiddleware("admin", "user"))
 	e.GET("/reservation/:id", resHandler.GetReservationByID, middleware.RoleAuthMiddleware("admin", "user"))
+	e.PUT("/reservation/:id", resHandler.ModifyReservation, middleware.RoleAuthMiddleware("admin", "user"))
+	e.GET("/reservation/:id/status-history", resHandler.GetReservationStatusHistories, middleware.RoleAuthMiddleware("admin", "user"))
+	e.GET("/reservation/:id/changes", resHandler.GetReservationChangeHistories, middleware.RoleAuthMiddleware("admin", "user"))
+	e.PUT("/reservation/:id/details/cancel", resHandler.CancelReservationDetails, middleware.RoleAuthMiddleware("admin", "user"))
+	e.POST("/reservation/:id/check-in", resHandler.CheckInReservation, middleware.RoleAuthMiddleware("admin", "user"))
+	e.GET("/reservation/:id/invoice", resHandler.GetReservationInvoice, middleware.RoleAuthMiddleware("admin", "user"))
+	e.POST("/reservation/:id/invoice/email", resHandler.EmailReservationInvoice, middleware.RoleAuthMiddleware("admin", "user"))
+	e.PUT("/reservation/:id/series", resHandler.UpdateReservationSeries, middleware.RoleAuthMiddleware("admin", "user"))
+	e.PUT("/reservation/:id/series/cancel", resHandler.CancelReservationSeries, middleware.RoleAuthMiddleware("admin", "user"))
+	e.PUT("/reservation/:id/approve", resHandler.ApproveReservation, middleware.RoleAuthMiddleware("admin"))
+	e.PUT("/reservation/:id/reject", resHandler.RejectReservation, middleware.RoleAuthMiddleware("admin"))
+	e.GET("/reservations/approvals", resHandler.GetPendingApprovals, middleware.RoleAuthMiddleware("admin"))
 	e.GET("/reservations/schedules", resHandler.GetReservationSchedules, middleware.RoleAuthMiddleware("admin"))
 
+	// --- WAITLIST ---
+	e.POST("/waitlist", waitlistHandler.JoinWaitlist, middleware.RoleAuthMiddleware("admin", "user"))
+	e.GET("/waitlist", waitlistHandler.GetWaitlist, middleware.RoleAuthMiddleware("admin", "user"))
+	e.DELETE("/waitlist/:id", waitlistHandler.LeaveWaitlist, middleware.RoleAuthMiddleware("admin", "user"))
+
+	// --- CALENDAR (iCalendar) ---
+	e.POST("/calendar/feeds", calendarHandler.CreateCalendarFeed, middleware.RoleAuthMiddleware("admin", "user"))
+	// Feed subscription tanpa header Authorization, token di URL sebagai kredensial
+	e.GET("/calendar/:token", calendarHandler.GetCalendarFeed)
+
+	// --- POLICY ---
+	e.GET("/cancellation-policies", policyHandler.GetCancellationPolicies, middleware.RoleAuthMiddleware("admin", "user"))
+	e.PUT("/cancellation-policies", policyHandler.SaveCancellationPolicy, middleware.RoleAuthMiddleware("admin"))
+	e.GET("/booking-rules", policyHandler.GetBookingRules, middleware.RoleAuthMiddleware("admin", "user"))
+	e.PUT("/booking-rules", policyHandler.SaveBookingRule, middleware.RoleAuthMiddleware("admin"))
+
+	// --- OPENING HOURS & BLACKOUT DATES ---
+	e.GET("/operating-hours", openingHandler.GetOperatingHours, middleware.RoleAuthMiddleware("admin", "user"))
+	e.PUT("/operating-hours", openingHandler.SaveOperatingHours, middleware.RoleAuthMiddleware("admin"))
+	e.GET("/blackout-dates", openingHandler.GetBlackoutDates, middleware.RoleAuthMiddleware("admin", "user"))
+	e.POST("/blackout-dates", openingHandler.CreateBlackoutDate, middleware.RoleAuthMiddleware("admin"))
+	e.DELETE("/blackout-dates/:id", openingHandler.DeleteBlackoutDate, middleware.RoleAuthMiddleware("admin"))
+
+	// --- PRICING RULES ---
+	e.GET("/pricing-rules", pricingHandler.GetPricingRules, middleware.RoleAuthMiddleware("admin", "user"))
+	e.POST("/pricing-rules", pricingHandler.CreatePricingRule, middleware.RoleAuthMiddleware("admin"))
+	e.PUT("/pricing-rules/:id", pricingHandler.UpdatePricingRule, middleware.RoleAuthMiddleware("admin"))
+	e.DELETE("/pricing-rules/:id", pricingHandler.DeletePricingRule, middleware.RoleAuthMiddleware("admin"))
+
+	// --- PROMO CODES & COMPANY AGREEMENTS ---
+	e.GET("/promo-codes", discountHandler.GetPromoCodes, middleware.RoleAuthMiddleware("admin"))
+	e.POST("/promo-codes", discountHandler.CreatePromoCode, middleware.RoleAuthMiddleware("admin"))
+	e.PUT("/promo-codes/:id", discountHandler.UpdatePromoCode, middleware.RoleAuthMiddleware("admin"))
+	e.DELETE("/promo-codes/:id", discountHandler.DeletePromoCode, middleware.RoleAuthMiddleware("admin"))
+	e.GET("/company-agreements", discountHandler.GetCompanyAgreements, middleware.RoleAuthMiddleware("admin"))
+	e.POST("/company-agreements", discountHandler.CreateCompanyAgreement, middleware.RoleAuthMiddleware("admin"))
+	e.PUT("/company-agreements/:id", discountHandler.UpdateCompanyAgreement, middleware.RoleAuthMiddleware("admin"))
+	e.DELETE("/company-agreements/:id", discountHandler.DeleteCompanyAgreement, middleware.RoleAuthMiddleware("admin"))
+
+	// --- TAX & SERVICE CHARGE ---
+	e.GET("/tax-components", taxHandler.GetTaxComponents, middleware.RoleAuthMiddleware("admin", "user"))
+	e.POST("/tax-components", taxHandler.CreateTaxComponent, middleware.RoleAuthMiddleware("admin"))
+	e.PUT("/tax-components/:id", taxHandler.UpdateTaxComponent, middleware.RoleAuthMiddleware("admin"))
+	e.DELETE("/tax-components/:id", taxHandler.DeleteTaxComponent, middleware.RoleAuthMiddleware("admin"))
+
+	// --- PAYMENT ---
+	e.POST("/reservation/:id/payment", paymentHandler.CreatePayment, middleware.RoleAuthMiddleware("admin", "user"))
+	e.GET("/reservation/:id/payments", paymentHandler.GetPayments, middleware.RoleAuthMiddleware("admin", "user"))
+	// Dipanggil payment provider, diverifikasi dengan signature (tanpa token login)
+	e.POST("/payments/webhook", paymentHandler.PaymentWebhook)
+
 	// --- DASHBOARD ---
 	e.GET("/dashboard", dashboardHandler.GetDashboard, middleware.RoleAuthMiddleware("admin"))
 
@@ -0,0 +1,4 @@
+DROP TABLE if exists reservation_status_histories;
+
+-- Postgres tidak bisa menghapus value enum, jadi 'refunded' dikembalikan ke 'cancel'
+UPDATE reservations SET status_reservation = 'cancel' WHERE status_reservation = 'refunded';
@@ -0,0 +1,21 @@
+-- ==============================
+-- STATUS: refunded
+-- ==============================
+
+ALTER TYPE status_reservation ADD VALUE IF NOT EXISTS 'refunded';
+
+-- ==============================
+-- TABLE: reservation_status_histories
+-- ==============================
+
+CREATE TABLE reservation_status_histories (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT REFERENCES reservations(id) ON DELETE CASCADE,
+    changed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    from_status status_reservation NOT NULL,
+    to_status status_reservation NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_reservation_status_histories_reservation ON reservation_status_histories(reservation_id);
@@ -0,0 +1,6 @@
+DROP INDEX if exists idx_reservations_series;
+ALTER TABLE reservations DROP COLUMN if exists occurrence_start;
+ALTER TABLE reservations DROP COLUMN if exists series_id;
+
+DROP TABLE if exists reservation_series;
+DROP TYPE if exists recurrence_frequency;
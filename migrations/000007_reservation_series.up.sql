@@ -0,0 +1,22 @@
+-- ==============================
+-- TABLE: reservation_series
+-- ==============================
+
+CREATE TYPE recurrence_frequency AS ENUM ('daily', 'weekly', 'monthly');
+
+CREATE TABLE reservation_series (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    frequency recurrence_frequency NOT NULL,
+    interval_value INT NOT NULL DEFAULT 1,
+    by_weekday VARCHAR(50),
+    until_at TIMESTAMPTZ,
+    occurrence_count INT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+ALTER TABLE reservations ADD COLUMN series_id INT REFERENCES reservation_series(id) ON DELETE SET NULL;
+ALTER TABLE reservations ADD COLUMN occurrence_start TIMESTAMPTZ;
+
+CREATE INDEX idx_reservations_series ON reservations(series_id, occurrence_start);
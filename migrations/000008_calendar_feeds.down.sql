@@ -0,0 +1,4 @@
+DROP TABLE if exists calendar_feed_tokens;
+DROP TYPE if exists calendar_feed_type;
+
+ALTER TABLE reservation_details DROP COLUMN if exists sequence;
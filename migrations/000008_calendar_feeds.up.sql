@@ -0,0 +1,22 @@
+-- ==============================
+-- SEQUENCE untuk VEVENT (naik setiap reschedule / perubahan status)
+-- ==============================
+
+ALTER TABLE reservation_details ADD COLUMN sequence INT NOT NULL DEFAULT 0;
+
+-- ==============================
+-- TABLE: calendar_feed_tokens
+-- ==============================
+
+CREATE TYPE calendar_feed_type AS ENUM ('user', 'room');
+
+CREATE TABLE calendar_feed_tokens (
+    id SERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    feed_type calendar_feed_type NOT NULL,
+    owner_id INT NOT NULL,
+    created_by INT REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_calendar_feed_tokens_owner ON calendar_feed_tokens(feed_type, owner_id, created_by);
@@ -0,0 +1 @@
+DROP TABLE if exists reservation_change_histories;
@@ -0,0 +1,17 @@
+-- ==============================
+-- TABLE: reservation_change_histories
+-- ==============================
+
+CREATE TABLE reservation_change_histories (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT REFERENCES reservations(id) ON DELETE CASCADE,
+    changed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    before_total DECIMAL(14,2),
+    after_total DECIMAL(14,2),
+    before_details JSONB,
+    after_details JSONB,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_reservation_change_histories_reservation ON reservation_change_histories(reservation_id);
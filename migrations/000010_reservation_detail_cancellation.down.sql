@@ -0,0 +1,2 @@
+ALTER TABLE reservation_details DROP COLUMN IF EXISTS cancel_reason;
+ALTER TABLE reservation_details DROP COLUMN IF EXISTS cancelled_at;
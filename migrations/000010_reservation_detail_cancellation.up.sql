@@ -0,0 +1,7 @@
+-- ==============================
+-- Partial cancellation per reservation_details
+-- (detail dengan cancelled_at terisi tidak lagi memblokir room)
+-- ==============================
+
+ALTER TABLE reservation_details ADD COLUMN cancelled_at TIMESTAMPTZ;
+ALTER TABLE reservation_details ADD COLUMN cancel_reason TEXT;
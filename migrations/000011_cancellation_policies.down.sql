@@ -0,0 +1,3 @@
+ALTER TABLE reservations DROP COLUMN IF EXISTS refund_amount;
+ALTER TABLE reservations DROP COLUMN IF EXISTS cancellation_fee;
+DROP TABLE if exists cancellation_policies;
@@ -0,0 +1,23 @@
+-- ==============================
+-- TABLE: cancellation_policies
+-- room_type NULL = policy global (dipakai jika room type tidak punya policy sendiri)
+-- ==============================
+
+CREATE TABLE cancellation_policies (
+    id SERIAL PRIMARY KEY,
+    room_type room_type,
+    free_cancel_hours INT NOT NULL DEFAULT 24,
+    late_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 50,
+    no_show_fee_percent DECIMAL(5,2) NOT NULL DEFAULT 100,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_cancellation_policies_room_type ON cancellation_policies(room_type);
+CREATE UNIQUE INDEX idx_cancellation_policies_global ON cancellation_policies((room_type IS NULL)) WHERE room_type IS NULL;
+
+INSERT INTO cancellation_policies (room_type, free_cancel_hours, late_fee_percent, no_show_fee_percent) VALUES (NULL, 24, 50, 100);
+
+-- Biaya cancel & nominal refund yang dihitung saat reservasi dicancel
+ALTER TABLE reservations ADD COLUMN cancellation_fee DECIMAL(14,2) NOT NULL DEFAULT 0;
+ALTER TABLE reservations ADD COLUMN refund_amount DECIMAL(14,2) NOT NULL DEFAULT 0;
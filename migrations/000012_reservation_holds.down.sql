@@ -0,0 +1,5 @@
+-- Postgres tidak bisa menghapus value enum, hold yang tersisa dicancel
+UPDATE reservations SET status_reservation = 'cancel' WHERE status_reservation = 'hold';
+
+DROP INDEX IF EXISTS idx_reservations_hold_expires_at;
+ALTER TABLE reservations DROP COLUMN IF EXISTS hold_expires_at;
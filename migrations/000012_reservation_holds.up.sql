@@ -0,0 +1,9 @@
+-- ==============================
+-- Tentative hold: status 'hold' memblokir slot sampai hold_expires_at
+-- ==============================
+
+ALTER TYPE status_reservation ADD VALUE IF NOT EXISTS 'hold';
+
+ALTER TABLE reservations ADD COLUMN hold_expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_reservations_hold_expires_at ON reservations(hold_expires_at) WHERE hold_expires_at IS NOT NULL;
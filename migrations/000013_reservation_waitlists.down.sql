@@ -0,0 +1,2 @@
+DROP TABLE if exists reservation_waitlists;
+DROP TYPE if exists waitlist_status;
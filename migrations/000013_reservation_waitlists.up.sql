@@ -0,0 +1,30 @@
+-- ==============================
+-- TABLE: reservation_waitlists
+-- Antrian untuk room + rentang waktu yang sudah penuh
+-- ==============================
+
+CREATE TYPE waitlist_status AS ENUM ('waiting', 'offered', 'booked', 'expired', 'cancelled');
+
+CREATE TABLE reservation_waitlists (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    room_id INT REFERENCES rooms(id) ON DELETE CASCADE,
+    start_at TIMESTAMPTZ NOT NULL,
+    end_at TIMESTAMPTZ NOT NULL,
+    participant INT NOT NULL,
+    snack_id INT REFERENCES snacks(id) ON DELETE SET NULL,
+    contact_name VARCHAR(100),
+    contact_phone VARCHAR(50),
+    contact_company VARCHAR(255),
+    note TEXT,
+    auto_book BOOLEAN NOT NULL DEFAULT false,
+    status waitlist_status NOT NULL DEFAULT 'waiting',
+    -- reservasi yang dibuat saat slot ditawarkan (hold) atau langsung dibooking
+    reservation_id INT REFERENCES reservations(id) ON DELETE SET NULL,
+    offer_expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_reservation_waitlists_room ON reservation_waitlists(room_id, status, start_at);
+CREATE INDEX idx_reservation_waitlists_reservation ON reservation_waitlists(reservation_id);
@@ -0,0 +1,5 @@
+ALTER TABLE rooms DROP COLUMN IF EXISTS checkin_token;
+
+DROP INDEX IF EXISTS idx_reservation_details_no_show;
+ALTER TABLE reservation_details DROP COLUMN IF EXISTS no_show_at;
+ALTER TABLE reservation_details DROP COLUMN IF EXISTS checked_in_at;
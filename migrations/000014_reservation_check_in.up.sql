@@ -0,0 +1,11 @@
+-- ==============================
+-- Check-in & no-show per reservation_details
+-- ==============================
+
+ALTER TABLE reservation_details ADD COLUMN checked_in_at TIMESTAMPTZ;
+ALTER TABLE reservation_details ADD COLUMN no_show_at TIMESTAMPTZ;
+
+CREATE INDEX idx_reservation_details_no_show ON reservation_details(start_at) WHERE checked_in_at IS NULL AND no_show_at IS NULL;
+
+-- Token QR per room untuk check-in di lokasi
+ALTER TABLE rooms ADD COLUMN checkin_token VARCHAR(64) UNIQUE;
@@ -0,0 +1,2 @@
+ALTER TABLE rooms DROP COLUMN IF EXISTS teardown_buffer_minutes;
+ALTER TABLE rooms DROP COLUMN IF EXISTS setup_buffer_minutes;
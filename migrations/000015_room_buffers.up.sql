@@ -0,0 +1,7 @@
+-- ==============================
+-- Buffer setup / teardown per room (menit)
+-- ==============================
+
+-- Dipakai saat cek bentrok, jam meeting yang tersimpan tidak berubah
+ALTER TABLE rooms ADD COLUMN setup_buffer_minutes INT NOT NULL DEFAULT 0 CHECK (setup_buffer_minutes >= 0);
+ALTER TABLE rooms ADD COLUMN teardown_buffer_minutes INT NOT NULL DEFAULT 0 CHECK (teardown_buffer_minutes >= 0);
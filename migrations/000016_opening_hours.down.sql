@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS blackout_dates;
+DROP TABLE IF EXISTS room_operating_hours;
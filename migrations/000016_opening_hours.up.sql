@@ -0,0 +1,41 @@
+-- ==============================
+-- TABLE: room_operating_hours
+-- Jam buka mingguan per room. room_id NULL = default global,
+-- dipakai untuk hari yang tidak diatur di room tersebut.
+-- Hari tanpa jam buka sama sekali = buka 24 jam.
+-- ==============================
+
+CREATE TABLE room_operating_hours (
+    id SERIAL PRIMARY KEY,
+    room_id INT REFERENCES rooms(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6), -- 0 = Minggu
+    open_time TIME NOT NULL DEFAULT '00:00',
+    close_time TIME NOT NULL DEFAULT '24:00',
+    is_closed BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (is_closed OR close_time > open_time)
+);
+
+CREATE UNIQUE INDEX idx_room_operating_hours_room ON room_operating_hours(room_id, weekday) WHERE room_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_room_operating_hours_global ON room_operating_hours(weekday) WHERE room_id IS NULL;
+
+-- Default global: setiap hari 07:00 - 22:00
+INSERT INTO room_operating_hours (room_id, weekday, open_time, close_time)
+SELECT NULL, d, '07:00', '22:00' FROM generate_series(0, 6) AS d;
+
+-- ==============================
+-- TABLE: blackout_dates
+-- Tanggal tutup (inklusif). room_id NULL = semua room (hari libur nasional)
+-- ==============================
+
+CREATE TABLE blackout_dates (
+    id SERIAL PRIMARY KEY,
+    room_id INT REFERENCES rooms(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_blackout_dates_range ON blackout_dates(start_date, end_date);
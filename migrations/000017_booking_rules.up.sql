@@ -0,0 +1,23 @@
+-- ==============================
+-- TABLE: booking_rules
+-- room_type NULL = aturan global (dipakai jika room type tidak punya aturan sendiri)
+-- Nilai 0 = tidak dibatasi
+-- ==============================
+
+CREATE TABLE booking_rules (
+    id SERIAL PRIMARY KEY,
+    room_type room_type,
+    min_lead_minutes INT NOT NULL DEFAULT 0 CHECK (min_lead_minutes >= 0),
+    max_horizon_days INT NOT NULL DEFAULT 90 CHECK (max_horizon_days >= 0),
+    min_duration_minutes INT NOT NULL DEFAULT 0 CHECK (min_duration_minutes >= 0),
+    max_duration_minutes INT NOT NULL DEFAULT 0 CHECK (max_duration_minutes >= 0),
+    slot_minutes INT NOT NULL DEFAULT 15 CHECK (slot_minutes IN (0, 5, 10, 15, 30, 60)),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_booking_rules_room_type ON booking_rules(room_type);
+CREATE UNIQUE INDEX idx_booking_rules_global ON booking_rules((room_type IS NULL)) WHERE room_type IS NULL;
+
+INSERT INTO booking_rules (room_type, min_lead_minutes, max_horizon_days, min_duration_minutes, max_duration_minutes, slot_minutes)
+VALUES (NULL, 0, 90, 15, 0, 15);
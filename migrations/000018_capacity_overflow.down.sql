@@ -0,0 +1 @@
+ALTER TABLE booking_rules DROP COLUMN IF EXISTS capacity_overflow_percent;
@@ -0,0 +1,2 @@
+-- Toleransi peserta melebihi kapasitas room (persen), bagian dari booking_rules
+ALTER TABLE booking_rules ADD COLUMN capacity_overflow_percent INT NOT NULL DEFAULT 0 CHECK (capacity_overflow_percent BETWEEN 0 AND 100);
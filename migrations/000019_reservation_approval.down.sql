@@ -0,0 +1,7 @@
+-- Postgres tidak bisa menghapus value enum, pending yang tersisa dicancel
+UPDATE reservations SET status_reservation = 'cancel' WHERE status_reservation = 'pending';
+
+ALTER TABLE booking_rules DROP COLUMN IF EXISTS approval_price_threshold;
+ALTER TABLE booking_rules DROP COLUMN IF EXISTS requires_approval;
+
+ALTER TABLE reservations DROP COLUMN IF EXISTS approval_reason;
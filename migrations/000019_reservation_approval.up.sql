@@ -0,0 +1,20 @@
+-- ==============================
+-- Approval workflow: status 'pending' memblokir slot sampai disetujui / ditolak admin
+-- ==============================
+
+ALTER TYPE status_reservation ADD VALUE IF NOT EXISTS 'pending';
+
+-- Alasan reservasi butuh approval (room type / batas harga)
+ALTER TABLE reservations ADD COLUMN approval_reason TEXT;
+
+-- Aturan approval per room type, approval_price_threshold 0 = tidak dibatasi
+ALTER TABLE booking_rules ADD COLUMN requires_approval BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE booking_rules ADD COLUMN approval_price_threshold DECIMAL(14,2) NOT NULL DEFAULT 0 CHECK (approval_price_threshold >= 0);
+
+-- Room large butuh approval, aturan lain mengikuti aturan global
+INSERT INTO booking_rules (room_type, min_lead_minutes, max_horizon_days, min_duration_minutes, max_duration_minutes,
+    slot_minutes, capacity_overflow_percent, requires_approval, approval_price_threshold)
+SELECT 'large', min_lead_minutes, max_horizon_days, min_duration_minutes, max_duration_minutes,
+    slot_minutes, capacity_overflow_percent, true, approval_price_threshold
+FROM booking_rules WHERE room_type IS NULL
+ON CONFLICT (room_type) DO UPDATE SET requires_approval = true;
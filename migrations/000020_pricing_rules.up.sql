@@ -0,0 +1,25 @@
+-- ==============================
+-- TABLE: pricing_rules
+-- room_type NULL = berlaku untuk semua room
+-- kind: time_band (jam peak / off-peak), weekend, holiday, package (half-day / full-day), long_booking
+-- ==============================
+
+CREATE TABLE pricing_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('time_band', 'weekend', 'holiday', 'package', 'long_booking')),
+    room_type room_type,
+    weekdays INT[] NOT NULL DEFAULT '{}',
+    start_time TIME,
+    end_time TIME,
+    start_date DATE,
+    end_date DATE,
+    multiplier DECIMAL(6,3) NOT NULL DEFAULT 1 CHECK (multiplier >= 0),
+    duration_minutes INT NOT NULL DEFAULT 0 CHECK (duration_minutes >= 0),
+    package_price DECIMAL(14,2) NOT NULL DEFAULT 0 CHECK (package_price >= 0),
+    discount_percent DECIMAL(5,2) NOT NULL DEFAULT 0 CHECK (discount_percent BETWEEN 0 AND 100),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_pricing_rules_room_type ON pricing_rules(room_type);
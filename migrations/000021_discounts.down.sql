@@ -0,0 +1,6 @@
+ALTER TABLE reservations DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE reservations DROP COLUMN IF EXISTS company_agreement_id;
+ALTER TABLE reservations DROP COLUMN IF EXISTS promo_code_id;
+
+DROP TABLE IF EXISTS company_agreements;
+DROP TABLE IF EXISTS promo_codes;
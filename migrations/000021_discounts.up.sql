@@ -0,0 +1,48 @@
+-- ==============================
+-- TABLE: promo_codes & company_agreements
+-- discount_type percent = persen dari harga eligible, fixed = potongan nominal
+-- room_types / snack_ids kosong = semua room / snack eligible, usage_limit 0 = tidak dibatasi
+-- ==============================
+
+CREATE TABLE promo_codes (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    discount_value DECIMAL(14,2) NOT NULL CHECK (discount_value > 0),
+    valid_from TIMESTAMPTZ,
+    valid_until TIMESTAMPTZ,
+    usage_limit INT NOT NULL DEFAULT 0 CHECK (usage_limit >= 0),
+    per_user_limit INT NOT NULL DEFAULT 0 CHECK (per_user_limit >= 0),
+    room_types room_type[] NOT NULL DEFAULT '{}',
+    snack_ids INT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_promo_codes_code ON promo_codes(UPPER(code));
+
+CREATE TABLE company_agreements (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    company_name VARCHAR(100) NOT NULL,
+    discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    discount_value DECIMAL(14,2) NOT NULL CHECK (discount_value > 0),
+    valid_from TIMESTAMPTZ,
+    valid_until TIMESTAMPTZ,
+    usage_limit INT NOT NULL DEFAULT 0 CHECK (usage_limit >= 0),
+    room_types room_type[] NOT NULL DEFAULT '{}',
+    snack_ids INT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_company_agreements_code ON company_agreements(UPPER(code));
+
+-- Diskon yang dipakai reservasi, total = subtotal_room + subtotal_snack - discount_amount
+ALTER TABLE reservations ADD COLUMN promo_code_id INT REFERENCES promo_codes(id) ON DELETE SET NULL;
+ALTER TABLE reservations ADD COLUMN company_agreement_id INT REFERENCES company_agreements(id) ON DELETE SET NULL;
+ALTER TABLE reservations ADD COLUMN discount_amount DECIMAL(14,2) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_reservations_promo_code ON reservations(promo_code_id) WHERE promo_code_id IS NOT NULL;
+CREATE INDEX idx_reservations_company_agreement ON reservations(company_agreement_id) WHERE company_agreement_id IS NOT NULL;
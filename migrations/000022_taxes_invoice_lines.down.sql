@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS reservation_invoice_lines;
+
+ALTER TABLE reservations DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE reservations DROP COLUMN IF EXISTS service_charge;
+
+DROP TABLE IF EXISTS tax_components;
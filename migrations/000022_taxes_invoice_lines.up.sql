@@ -0,0 +1,47 @@
+-- ==============================
+-- TABLE: tax_components
+-- kind service = service charge (dari total setelah diskon), tax = pajak / PPN (setelah diskon + service charge)
+-- rate dalam persen, pembulatan per baris: nearest / up / down ke kelipatan rounding_unit
+-- ==============================
+
+CREATE TABLE tax_components (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('service', 'tax')),
+    rate DECIMAL(5,2) NOT NULL CHECK (rate > 0 AND rate <= 100),
+    rounding VARCHAR(10) NOT NULL DEFAULT 'nearest' CHECK (rounding IN ('nearest', 'up', 'down')),
+    rounding_unit DECIMAL(14,2) NOT NULL DEFAULT 1 CHECK (rounding_unit > 0),
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+-- total = subtotal_room + subtotal_snack - discount_amount + service_charge + tax_amount
+ALTER TABLE reservations ADD COLUMN service_charge DECIMAL(14,2) NOT NULL DEFAULT 0;
+ALTER TABLE reservations ADD COLUMN tax_amount DECIMAL(14,2) NOT NULL DEFAULT 0;
+
+-- ==============================
+-- TABLE: reservation_invoice_lines
+-- Baris invoice yang disimpan saat booking (room, fee, snack, discount, service, tax),
+-- baris per room terhubung ke reservation_details. Rate / pembulatan pajak disalin dari tax_components.
+-- ==============================
+
+CREATE TABLE reservation_invoice_lines (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    reservation_detail_id INT REFERENCES reservation_details(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    line_type VARCHAR(10) NOT NULL CHECK (line_type IN ('room', 'fee', 'snack', 'discount', 'service', 'tax')),
+    description VARCHAR(255) NOT NULL,
+    quantity DECIMAL(10,2) NOT NULL DEFAULT 1,
+    unit VARCHAR(10),
+    unit_price DECIMAL(14,2) NOT NULL DEFAULT 0,
+    rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    amount DECIMAL(14,2) NOT NULL,
+    tax_component_id INT REFERENCES tax_components(id) ON DELETE SET NULL,
+    rounding VARCHAR(10),
+    rounding_unit DECIMAL(14,2),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_reservation_invoice_lines_reservation ON reservation_invoice_lines(reservation_id, position);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reservation_invoices;
+DROP TABLE IF EXISTS invoice_sequences;
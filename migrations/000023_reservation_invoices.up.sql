@@ -0,0 +1,21 @@
+-- ==============================
+-- TABLE: invoice_sequences & reservation_invoices
+-- Nomor invoice / receipt berurutan per tahun tanpa loncat (counter dikunci di transaksi),
+-- satu nomor per reservasi per jenis dan tidak berubah saat PDF dibuat ulang
+-- ==============================
+
+CREATE TABLE invoice_sequences (
+    kind VARCHAR(10) NOT NULL,
+    year INT NOT NULL,
+    last_number INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (kind, year)
+);
+
+CREATE TABLE reservation_invoices (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('invoice', 'receipt')),
+    invoice_number VARCHAR(30) NOT NULL UNIQUE,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (reservation_id, kind)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE reservations DROP COLUMN IF EXISTS paid_at;
+ALTER TABLE reservations DROP COLUMN IF EXISTS paid_amount;
+ALTER TABLE reservations DROP COLUMN IF EXISTS payment_reference;
+
+DROP TABLE IF EXISTS payment_webhook_events;
+DROP TABLE IF EXISTS reservation_payments;
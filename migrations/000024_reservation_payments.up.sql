@@ -0,0 +1,46 @@
+-- ==============================
+-- TABLE: reservation_payments
+-- Payment intent ke payment provider untuk total reservasi. Satu intent pending per reservasi,
+-- status berubah lewat webhook (paid / failed / expired)
+-- ==============================
+
+CREATE TABLE reservation_payments (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    provider VARCHAR(30) NOT NULL,
+    reference VARCHAR(50) NOT NULL UNIQUE,
+    provider_reference VARCHAR(100),
+    amount DECIMAL(14,2) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'failed', 'expired')),
+    payment_url TEXT,
+    expires_at TIMESTAMPTZ,
+    paid_amount DECIMAL(14,2),
+    paid_at TIMESTAMPTZ,
+    failure_reason TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_reservation_payments_pending ON reservation_payments(reservation_id) WHERE status = 'pending';
+
+-- ==============================
+-- TABLE: payment_webhook_events
+-- Event webhook yang sudah diproses, event yang dikirim ulang provider diabaikan
+-- ==============================
+
+CREATE TABLE payment_webhook_events (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(30) NOT NULL,
+    event_id VARCHAR(100) NOT NULL,
+    reference VARCHAR(50) NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    amount DECIMAL(14,2) NOT NULL DEFAULT 0,
+    payload TEXT,
+    received_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (provider, event_id)
+);
+
+-- Referensi & jumlah pembayaran yang membuat reservasi menjadi paid
+ALTER TABLE reservations ADD COLUMN payment_reference VARCHAR(50);
+ALTER TABLE reservations ADD COLUMN paid_amount DECIMAL(14,2);
+ALTER TABLE reservations ADD COLUMN paid_at TIMESTAMPTZ;
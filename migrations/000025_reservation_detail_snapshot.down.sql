@@ -0,0 +1,7 @@
+DELETE FROM reservation_details WHERE room_id IS NULL;
+
+ALTER TABLE reservation_details DROP CONSTRAINT IF EXISTS reservation_details_room_id_fkey;
+ALTER TABLE reservation_details ADD CONSTRAINT reservation_details_room_id_fkey
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE;
+
+ALTER TABLE reservation_details DROP COLUMN IF EXISTS room_type;
@@ -0,0 +1,15 @@
+-- ==============================
+-- Snapshot room di reservation_details
+-- History & detail reservasi dibaca dari snapshot (nama, tipe, harga), bukan dari rooms,
+-- jadi edit / hapus room tidak mengubah atau menghilangkan booking lama
+-- ==============================
+
+ALTER TABLE reservation_details ADD COLUMN room_type VARCHAR(20);
+
+UPDATE reservation_details rd SET room_type = rm.room_type::text
+FROM rooms rm WHERE rm.id = rd.room_id;
+
+-- Hapus room tidak lagi menghapus detail reservasi, room_id menjadi NULL
+ALTER TABLE reservation_details DROP CONSTRAINT IF EXISTS reservation_details_room_id_fkey;
+ALTER TABLE reservation_details ADD CONSTRAINT reservation_details_room_id_fkey
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE SET NULL;
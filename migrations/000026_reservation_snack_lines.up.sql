@@ -0,0 +1,27 @@
+-- ==============================
+-- TABLE: reservation_snack_lines
+-- Beberapa item snack per room yang dibooking (snapshot nama, unit & harga saat booking),
+-- harga = snack_price x quantity. Kolom snack_* di reservation_details = item pertama.
+-- ==============================
+
+CREATE TABLE reservation_snack_lines (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    reservation_detail_id INT NOT NULL REFERENCES reservation_details(id) ON DELETE CASCADE,
+    snack_id INT REFERENCES snacks(id) ON DELETE SET NULL,
+    snack_name VARCHAR(100) NOT NULL,
+    snack_unit VARCHAR(10) NOT NULL DEFAULT 'person',
+    snack_price DECIMAL(12,2) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    serve_at TIMESTAMPTZ NOT NULL,
+    subtotal DECIMAL(14,2) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_reservation_snack_lines_detail ON reservation_snack_lines(reservation_detail_id, serve_at);
+
+-- Data lama: satu snack x jumlah peserta, disajikan di jam mulai
+INSERT INTO reservation_snack_lines (reservation_id, reservation_detail_id, snack_id, snack_name, snack_unit, snack_price, quantity, serve_at, subtotal, created_at)
+SELECT rd.reservation_id, rd.id, rd.snack_id, rd.snack_name, 'person', rd.snack_price, rd.total_participants, rd.start_at, rd.total_snack, NOW()
+FROM reservation_details rd
+WHERE rd.snack_name <> '' AND COALESCE(rd.total_snack, 0) > 0 AND COALESCE(rd.total_participants, 0) > 0;